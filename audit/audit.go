@@ -0,0 +1,265 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package audit writes the audit mode trail: append-only, rotated files of
+// records chained by a running SHA-256, so any edit, removal or reordering
+// of a record breaks the chain.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+)
+
+const (
+	filePrefix     = "audit-"
+	fileSuffix     = ".log"
+	fileTimeLayout = "20060102T150405.000000000"
+	defaultMaxSize = 100
+)
+
+// genesisHash is the previous hash of the first record of a chain.
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+// Record is one audit entry, Hash covers PrevHash and every other field.
+type Record struct {
+	Seq        uint64 `json:"seq"`
+	Timestamp  int64  `json:"timestamp"` // unix milliseconds
+	Route      string `json:"route"`
+	Addr       string `json:"addr"`
+	OrgId      string `json:"org_id"`
+	TraceId    string `json:"trace_id"`
+	BodyLength int    `json:"body_length"`
+	BodyHash   string `json:"body_hash"`
+	Body       []byte `json:"body,omitempty"`
+	PrevHash   string `json:"prev_hash"`
+	Hash       string `json:"hash"`
+}
+
+// computeHash returns the chain hash of the record, ignoring its current Hash.
+func (r Record) computeHash() (string, error) {
+	r.Hash = ""
+	data, err := jsoniter.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(r.PrevHash+"\n"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sink appends chained records to rotated files in a directory.
+type Sink struct {
+	dir      string
+	maxSize  int64
+	withBody bool
+
+	lock     sync.Mutex
+	file     *os.File
+	size     int64
+	day      string
+	seq      uint64
+	prevHash string
+}
+
+var sink *Sink
+
+// Init opens the audit sink of the audit running mode.
+func Init(conf *config.Config) {
+	dir := conf.Audit.Dir
+	if dir == "" {
+		dir = filepath.Join(conf.LogDir, "audit")
+	}
+	s, err := NewSink(dir, conf.Audit.MaxSize, conf.Audit.WithBody)
+	if err != nil {
+		log.Fatalf("failed to open audit sink dir:%s err:%+v", dir, err)
+	}
+	sink = s
+}
+
+// GetSink returns the audit sink, nil when it is not enabled.
+func GetSink() *Sink {
+	return sink
+}
+
+// NewSink opens the sink and resumes the chain from the last record on disk.
+func NewSink(dir string, maxSizeMB int, withBody bool) (*Sink, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSize
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &Sink{
+		dir:      dir,
+		maxSize:  int64(maxSizeMB) * 1024 * 1024,
+		withBody: withBody,
+		prevHash: genesisHash,
+	}
+	files, err := listFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		last, err := lastRecord(files)
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			s.seq = last.Seq
+			s.prevHash = last.Hash
+		}
+	}
+	return s, nil
+}
+
+// Write appends a record for one request.
+func (s *Sink) Write(route, addr, orgId, traceId string, body []byte) error {
+	sum := sha256.Sum256(body)
+	record := Record{
+		Timestamp:  time.Now().UnixMilli(),
+		Route:      route,
+		Addr:       addr,
+		OrgId:      orgId,
+		TraceId:    traceId,
+		BodyLength: len(body),
+		BodyHash:   hex.EncodeToString(sum[:]),
+	}
+	if s.withBody {
+		record.Body = body
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record.Seq = s.seq + 1
+	record.PrevHash = s.prevHash
+	hash, err := record.computeHash()
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+	line, err := jsoniter.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if err := s.rotate(int64(len(line))); err != nil {
+		return err
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+	s.seq = record.Seq
+	s.prevHash = record.Hash
+	return nil
+}
+
+// rotate opens a new file on the first write, on a new day, or when the next line would exceed maxSize.
+func (s *Sink) rotate(next int64) error {
+	now := time.Now()
+	day := now.Format("20060102")
+	if s.file != nil && s.day == day && (s.size == 0 || s.size+next <= s.maxSize) {
+		return nil
+	}
+	if s.file != nil {
+		_ = s.file.Sync()
+		_ = s.file.Close()
+	}
+	name := filepath.Join(s.dir, filePrefix+now.Format(fileTimeLayout)+fileSuffix)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		s.file = nil
+		return err
+	}
+	s.file = f
+	s.size = 0
+	s.day = day
+	return nil
+}
+
+// Close flushes and closes the current file.
+func (s *Sink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	_ = s.file.Sync()
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// listFiles returns the audit files of dir in chain order.
+func listFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// lastRecord returns the last record of the newest non-empty file.
+func lastRecord(files []string) (*Record, error) {
+	for i := len(files) - 1; i >= 0; i-- {
+		f, err := os.Open(files[i])
+		if err != nil {
+			return nil, err
+		}
+		var last *Record
+		scanner := newScanner(f)
+		for scanner.Scan() {
+			record := &Record{}
+			if err := jsoniter.Unmarshal(scanner.Bytes(), record); err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("%s: %w", files[i], err)
+			}
+			last = record
+		}
+		err = scanner.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			return last, nil
+		}
+	}
+	return nil, nil
+}
+
+func newScanner(f *os.File) *bufio.Scanner {
+	scanner := bufio.NewScanner(f)
+	// records may carry request bodies up to msg.file
+	scanner.Buffer(make([]byte, 64*1024), 256*1024*1024)
+	return scanner
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// VerifyResult summarizes a verified chain.
+type VerifyResult struct {
+	Files    int
+	Records  uint64
+	FirstSeq uint64
+	LastSeq  uint64
+	LastHash string
+}
+
+// ChainError locates the first record that breaks the chain.
+type ChainError struct {
+	File   string
+	Line   int
+	Seq    uint64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("%s:%d seq %d: %s", e.File, e.Line, e.Seq, e.Reason)
+}
+
+// Verify validates the chain of an audit directory, or of a single audit file.
+// A directory chain must start at the genesis hash, a single file is checked
+// from its first record on.
+func Verify(path string) (*VerifyResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	fromGenesis := false
+	if info.IsDir() {
+		if files, err = listFiles(path); err != nil {
+			return nil, err
+		}
+		fromGenesis = true
+	}
+
+	result := &VerifyResult{}
+	prevHash := ""
+	if fromGenesis {
+		prevHash = genesisHash
+	}
+	for _, file := range files {
+		if err := verifyFile(file, &prevHash, result); err != nil {
+			return result, err
+		}
+		result.Files++
+	}
+	result.LastHash = prevHash
+	return result, nil
+}
+
+func verifyFile(file string, prevHash *string, result *VerifyResult) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	line := 0
+	scanner := newScanner(f)
+	for scanner.Scan() {
+		line++
+		record := Record{}
+		if err := jsoniter.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return &ChainError{File: file, Line: line, Reason: "malformed record: " + err.Error()}
+		}
+		if *prevHash != "" && record.PrevHash != *prevHash {
+			return &ChainError{File: file, Line: line, Seq: record.Seq, Reason: "prev_hash does not match the previous record"}
+		}
+		if result.Records > 0 && record.Seq != result.LastSeq+1 {
+			return &ChainError{File: file, Line: line, Seq: record.Seq, Reason: fmt.Sprintf("seq gap after %d", result.LastSeq)}
+		}
+		hash, err := record.computeHash()
+		if err != nil {
+			return &ChainError{File: file, Line: line, Seq: record.Seq, Reason: err.Error()}
+		}
+		if hash != record.Hash {
+			return &ChainError{File: file, Line: line, Seq: record.Seq, Reason: "hash mismatch, record was modified"}
+		}
+		if record.Body != nil {
+			sum := sha256.Sum256(record.Body)
+			if hex.EncodeToString(sum[:]) != record.BodyHash {
+				return &ChainError{File: file, Line: line, Seq: record.Seq, Reason: "body does not match body_hash"}
+			}
+		}
+		if result.Records == 0 {
+			result.FirstSeq = record.Seq
+		}
+		result.Records++
+		result.LastSeq = record.Seq
+		*prevHash = record.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return &ChainError{File: file, Line: line + 1, Reason: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package certs serves TLS certificates that are reloaded when their files
+// change, so renewed or short-lived certificates apply without a restart.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/web3password/satis/log"
+)
+
+// reloadDebounce lets a renewal finish writing both the certificate and the key.
+const reloadDebounce = 500 * time.Millisecond
+
+// Reloader holds a key pair and an optional CA pool, reloading them when the
+// files change. A failed reload keeps the previous certificate.
+type Reloader struct {
+	ca  string
+	crt string
+	key string
+
+	lock sync.RWMutex
+	cert *tls.Certificate
+	pool *x509.CertPool
+}
+
+// NewReloader loads the files and starts watching them. ca may be empty.
+func NewReloader(ca, crt, key string) (*Reloader, error) {
+	r := &Reloader{ca: ca, crt: crt, key: key}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+}
+
+func (r *Reloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.crt, r.key)
+	if err != nil {
+		return err
+	}
+	var pool *x509.CertPool
+	if r.ca != "" {
+		caCert, err := os.ReadFile(r.ca)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM(caCert); !ok {
+			return fmt.Errorf("no certificate found in %s", r.ca)
+		}
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return err
+	}
+	cert.Leaf = leaf
+
+	r.lock.Lock()
+	r.cert = &cert
+	r.pool = pool
+	r.lock.Unlock()
+	log.Logger.Info("tls certificate loaded", log.String("crt", r.crt), log.String("subject", leaf.Subject.String()),
+		log.String("not_after", leaf.NotAfter.Format(time.RFC3339)))
+	return nil
+}
+
+// watch follows the directories of the files, which also catches renames and
+// the symlink swaps of mounted secrets, and reloads after the writes settle.
+func (r *Reloader) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Logger.Error("tls certificate watch error", log.String("crt", r.crt), log.Error(err))
+		return
+	}
+	defer watcher.Close()
+	for _, file := range []string{r.ca, r.crt, r.key} {
+		if file == "" {
+			continue
+		}
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			log.Logger.Error("tls certificate watch error", log.String("file", file), log.Error(err))
+			return
+		}
+	}
+
+	timer := time.NewTimer(reloadDebounce)
+	timer.Stop()
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			timer.Reset(reloadDebounce)
+		case <-timer.C:
+			if err := r.load(); err != nil {
+				log.Logger.Error("tls certificate reload error, keep the current certificate", log.String("crt", r.crt), log.Error(err))
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Logger.Error("tls certificate watch error", log.String("crt", r.crt), log.Error(err))
+		}
+	}
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.cert, r.pool
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate.
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+// ServerConfig returns a server config that picks up reloaded certificates on
+// every handshake. With a CA, client certificates signed by it are required.
+// Each handshake uses a clone of the returned config, so the fields callers set
+// on it survive. It offers h2 and http/1.1 itself, grpc and endless add their
+// protocols to copies a handshake never sees.
+func (r *Reloader) ServerConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: r.GetCertificate,
+	}
+	r.setClientAuth(base)
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		conf := base.Clone()
+		conf.GetConfigForClient = nil
+		r.setClientAuth(conf)
+		return conf, nil
+	}
+	return base
+}
+
+// setClientAuth requires client certificates signed by the current CA pool, if any.
+func (r *Reloader) setClientAuth(conf *tls.Config) {
+	if _, pool := r.current(); pool != nil {
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+		conf.ClientCAs = pool
+	}
+}
+
+// ClientConfig returns a client config that presents the reloaded certificate
+// and verifies the server against the current CA pool.
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		ServerName:           serverName,
+		GetClientCertificate: r.GetClientCertificate,
+		// the chain is verified in VerifyConnection, so a rotated CA applies too
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return r.verifyServer(cs, serverName)
+		},
+	}
+}
+
+func (r *Reloader) verifyServer(cs tls.ConnectionState, serverName string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	_, pool := r.current()
+	opts := x509.VerifyOptions{
+		Roots:         pool,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,218 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// testCA issues the certificates of a test.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a localhost leaf named cn.
+func (ca *testCA) issue(t *testing.T, cn string, serial int64) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func writeFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerConfigKeepsALPNAfterRotation(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	caPath, crt, key := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	writeFile(t, caPath, ca.pem)
+	crtPEM, keyPEM := ca.issue(t, "server 1", 2)
+	writeFile(t, crt, crtPEM)
+	writeFile(t, key, keyPEM)
+	r, err := NewReloader(caPath, crt, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCrt, clientKey := ca.issue(t, "client", 3)
+	clientCert, err := tls.X509KeyPair(clientCrt, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientConfig := func() *tls.Config {
+		return &tls.Config{
+			ServerName:   "localhost",
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{clientCert},
+			NextProtos:   []string{"h2"},
+		}
+	}
+
+	// grpc serves a copy of the config with h2 added
+	creds := credentials.NewTLS(r.ServerConfig())
+	grpcLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = grpcLn.Close() })
+	grpcHandshake := func() (string, string, error) {
+		done := make(chan error, 1)
+		go func() {
+			server, err := grpcLn.Accept()
+			if err != nil {
+				done <- err
+				return
+			}
+			defer server.Close()
+			_, _, err = creds.ServerHandshake(server)
+			done <- err
+		}()
+		conn, err := tls.Dial("tcp", grpcLn.Addr().String(), clientConfig())
+		if err != nil {
+			return "", "", err
+		}
+		defer conn.Close()
+		if err := <-done; err != nil {
+			return "", "", err
+		}
+		cs := conn.ConnectionState()
+		return cs.NegotiatedProtocol, cs.PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	// http serves the config itself
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		TLSConfig: r.ServerConfig(),
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { _ = srv.Close() })
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig(), ForceAttemptHTTP2: true}}
+	httpGet := func() (int, string, error) {
+		rsp, err := httpClient.Get(fmt.Sprintf("https://localhost:%d", ln.Addr().(*net.TCPAddr).Port))
+		if err != nil {
+			return 0, "", err
+		}
+		defer rsp.Body.Close()
+		httpClient.CloseIdleConnections()
+		return rsp.ProtoMajor, rsp.TLS.PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	check := func(cn string) {
+		t.Helper()
+		proto, got, err := grpcHandshake()
+		if err != nil || proto != "h2" || got != cn {
+			t.Fatalf("grpc handshake: protocol %q certificate %q, want h2 %q: %v", proto, got, cn, err)
+		}
+		major, got, err := httpGet()
+		if err != nil || major != 2 || got != cn {
+			t.Fatalf("https: HTTP/%d certificate %q, want HTTP/2 %q: %v", major, got, cn, err)
+		}
+	}
+	check("server 1")
+
+	crtPEM, keyPEM = ca.issue(t, "server 2", 4)
+	writeFile(t, crt, crtPEM)
+	writeFile(t, key, keyPEM)
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(100 * time.Millisecond) {
+		if cert, _ := r.current(); cert.Leaf.Subject.CommonName == "server 2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the rotated certificate was not loaded")
+		}
+	}
+	check("server 2")
+
+	// a client without a certificate is refused
+	conf := clientConfig()
+	conf.Certificates = nil
+	conn, err := tls.Dial("tcp", ln.Addr().String(), conf)
+	if err == nil {
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	if err == nil {
+		t.Fatal("handshake without a client certificate succeeded")
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package client builds signed BSON requests for the satis http api and
+// decodes its responses, for tools and tests talking to a running instance.
+//
+//	signer, err := client.LoadKey("user.key")
+//	c := client.New("http://127.0.0.1:8080", signer)
+//	rsp, err := c.Call(ctx, "/web3password/userInfo", nil, nil)
+//	fmt.Println(rsp.Code, rsp.Msg, string(rsp.JSON()))
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/model"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Client calls one satis instance with the requests signed by Signer.
+type Client struct {
+	BaseURL string
+	Signer  Signer
+	HTTP    *http.Client
+}
+
+// Response is a decoded api response.
+type Response struct {
+	Code    int
+	Msg     string
+	Data    []byte
+	TraceID string
+}
+
+// New returns a client of the instance at baseURL, e.g. http://127.0.0.1:8080.
+func New(baseURL string, signer Signer) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Signer:  signer,
+		HTTP:    &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Params fills in the fields every request carries, addr, timestamp, nonce,
+// the route token and the hash of data, keeping the ones already set.
+func (c *Client) Params(route Route, params map[string]any, data []byte) map[string]any {
+	filled := make(map[string]any, len(params)+5)
+	for k, v := range params {
+		filled[k] = v
+	}
+	setDefault(filled, "addr", c.Signer.Address())
+	setDefault(filled, "timestamp", time.Now().Unix())
+	setDefault(filled, "nonce", uuid.NewString())
+	if typed, ok := c.Signer.(interface{ SigType() string }); ok {
+		setDefault(filled, "sig_type", typed.SigType())
+	}
+	if route.Token != "" {
+		setDefault(filled, "token", route.Token)
+	}
+	if len(data) > 0 && route.HashField != "" {
+		sum := sha256.Sum256(data)
+		setDefault(filled, route.HashField, hex.EncodeToString(sum[:]))
+	}
+	return filled
+}
+
+func setDefault(params map[string]any, key string, value any) {
+	if _, ok := params[key]; !ok {
+		params[key] = value
+	}
+}
+
+// Call signs and posts a request to path. Routes outside Routes are posted
+// with the params as given apart from addr, timestamp and nonce.
+func (c *Client) Call(ctx context.Context, path string, params map[string]any, data []byte) (*Response, error) {
+	route, ok := LookupRoute(path)
+	if !ok {
+		route = Route{Path: path}
+	}
+	b, err := json.Marshal(c.Params(route, params, data))
+	if err != nil {
+		return nil, err
+	}
+	return c.Post(ctx, route.Path, string(b), data)
+}
+
+// Op signs a request to path as an op of a transaction, the params are filled
+// in as Call does.
+func (c *Client) Op(path string, params map[string]any, data []byte) (*model.TxOp, error) {
+	route, ok := LookupRoute(path)
+	if !ok {
+		route = Route{Path: path}
+	}
+	b, err := json.Marshal(c.Params(route, params, data))
+	if err != nil {
+		return nil, err
+	}
+	sign, err := c.Signer.Sign(b)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TxOp{Route: route.Path, Signature: sign, Params: string(b), Data: data}, nil
+}
+
+// Transaction posts ops to /transaction, to be applied all or none.
+func (c *Client) Transaction(ctx context.Context, ops ...*model.TxOp) (*Response, error) {
+	data, err := bson.Marshal(model.TransactionData{Ops: ops})
+	if err != nil {
+		return nil, err
+	}
+	return c.Call(ctx, "/web3password/transaction", nil, data)
+}
+
+// Post signs params as they are and posts them to path.
+func (c *Client) Post(ctx context.Context, path, params string, data []byte) (*Response, error) {
+	rsp, err := c.send(ctx, path, params, data)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	return decodeResponse(path, rsp)
+}
+
+// Export writes the vault archive of the signer to w. The response is returned
+// instead when the export is refused, it is nil once the archive is written.
+func (c *Client) Export(ctx context.Context, params map[string]any, w io.Writer) (*Response, error) {
+	route, _ := LookupRoute("/web3password/vault/export")
+	b, err := json.Marshal(c.Params(route, params, nil))
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := c.send(ctx, route.Path, string(b), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.Header.Get("Content-Type") != "application/x-tar" {
+		return decodeResponse(route.Path, rsp)
+	}
+	_, err = io.Copy(w, rsp.Body)
+	return nil, err
+}
+
+// Event is an event of the /events stream, Data is json.
+type Event struct {
+	ID   string
+	Name string
+	Data []byte
+}
+
+// Events subscribes to the notifications of the signer and calls f for every
+// event until ctx is done, the stream ends or f returns an error, which is
+// returned. The response is returned instead when the subscription is refused.
+func (c *Client) Events(ctx context.Context, params map[string]any, f func(*Event) error) (*Response, error) {
+	route, _ := LookupRoute("/web3password/events")
+	b, err := json.Marshal(c.Params(route, params, nil))
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.request(ctx, route.Path, string(b), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the stream outlives the timeout of the other calls
+	hc := *c.HTTP
+	hc.Timeout = 0
+	rsp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if !strings.HasPrefix(rsp.Header.Get("Content-Type"), "text/event-stream") {
+		return decodeResponse(route.Path, rsp)
+	}
+	scanner := bufio.NewScanner(rsp.Body)
+	event := &Event{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event.Name != "" {
+				if err := f(event); err != nil {
+					return nil, err
+				}
+			}
+			event = &Event{}
+		case strings.HasPrefix(line, ":"):
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				event.ID = value
+			case "event":
+				event.Name = value
+			case "data":
+				event.Data = append(event.Data, value...)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *Client) send(ctx context.Context, path, params string, data []byte) (*http.Response, error) {
+	req, err := c.request(ctx, path, params, data)
+	if err != nil {
+		return nil, err
+	}
+	return c.HTTP.Do(req)
+}
+
+func (c *Client) request(ctx context.Context, path, params string, data []byte) (*http.Request, error) {
+	sign, err := c.Signer.Sign([]byte(params))
+	if err != nil {
+		return nil, err
+	}
+	body, err := encode.Web3PasswordRequestBsonEncode(sign, params, data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return req, nil
+}
+
+func decodeResponse(path string, rsp *http.Response) (*Response, error) {
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var decoded *encode.Web3PasswordResponseBsonStruct
+	if len(b) >= 6 {
+		decoded, err = encode.Web3PasswordResponseBsonDecode(b)
+	}
+	if decoded == nil || err != nil {
+		return nil, fmt.Errorf("%s returned %s, not a BSON response: %.200q", path, rsp.Status, b)
+	}
+	return &Response{Code: decoded.Code, Msg: decoded.Msg, Data: decoded.Data, TraceID: rsp.Header.Get("X-Trace-id")}, nil
+}
+
+// DecodeData decodes Data for display: a BSON document, JSON, text, or the
+// raw bytes, base64 encoded in JSON.
+func (r *Response) DecodeData() any {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(r.Data, &doc); err == nil {
+		return doc
+	}
+	if json.Valid(r.Data) {
+		return json.RawMessage(r.Data)
+	}
+	if utf8.Valid(r.Data) {
+		return string(r.Data)
+	}
+	return r.Data
+}
+
+// JSON is the response as indented JSON.
+func (r *Response) JSON() []byte {
+	b, err := json.MarshalIndent(map[string]any{
+		"code":     r.Code,
+		"msg":      r.Msg,
+		"data":     r.DecodeData(),
+		"trace_id": r.TraceID,
+	}, "", "  ")
+	if err != nil {
+		b, _ = json.MarshalIndent(map[string]any{"code": r.Code, "msg": r.Msg, "data": r.Data, "trace_id": r.TraceID}, "", "  ")
+	}
+	return b
+}
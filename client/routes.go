@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package client
+
+import (
+	"github.com/web3password/satis/model"
+)
+
+// Route is a POST route of service.Routers and the token its params carry.
+type Route struct {
+	Path  string
+	Token string // empty when the route does not check it
+	// HashField is the params field holding the sha256 of the data.
+	HashField string
+}
+
+// Routes lists the BSON routes in the order of service.Routers.
+var Routes = []Route{
+	{"/web3password/userRegister", model.RegisterToken, "hash"},
+	{"/web3password/getPersonalSignAddress", model.GetPersonalSignAddressToken, "hash"},
+	{"/web3password/getVipInfo", model.GetVIPInfoToken, "hash"},
+	{"/web3password/userInfo", model.GetUserInfoToken, "hash"},
+	{"/web3password/getLatestBlockTimestamp", "", "hash"},
+	{"/web3password/checkTx", model.CheckTxToken, "hash"},
+	{"/web3password/batchCheckTx", model.IndexBatchCheckTxToken, "hash"},
+	{"/web3password/addCredential", model.AddCredentialToken, "hash"},
+	{"/web3password/batchAddCredential", model.IndexBatchAddCredentialToken, "hash"},
+	{"/web3password/getCredential", model.GetCredentialToken, "hash"},
+	{"/web3password/deleteCredential", model.DelCredentialToken, "hash"},
+	{"/web3password/batchDeleteCredential", model.IndexBatchDeleteCredentialToken, "hash"},
+	{"/web3password/deleteAllCredential", model.DeleteAllCredentialToken, "hash"},
+	{"/web3password/getAllCredentialTimestamp", model.GetAllCredentialTimestampToken, "hash"},
+	{"/web3password/getCredentialList", model.GetCredentialListToken, "hash"},
+	{"/web3password/syncCredentials", model.SyncCredentialsToken, "hash"},
+	{"/web3password/storageStat", model.StorageStatToken, "hash"},
+	{"/web3password/getVersionConfig", model.GetVersionConfigToken, "hash"},
+	{"/web3password/session", model.SessionToken, "hash"},
+	{"/web3password/transaction", model.TransactionToken, "hash"},
+	{"/web3password/events", model.EventsToken, "hash"},
+
+	{"/web3password/vault/export", model.VaultExportToken, "hash"},
+	{"/web3password/vault/import", model.VaultImportToken, "hash"},
+	{"/web3password/vault/importStatus", model.VaultImportStatusToken, "hash"},
+
+	{"/web3password/admin/authorization", model.AadminAuthorizationToken, "hash"},
+	{"/web3password/admin/addMember", model.AdminAddMemberToken, "hash"},
+	{"/web3password/admin/batchImportMember", model.AdminBatchImportMemberToken, "hash"},
+	{"/web3password/admin/updateMember", model.AdminUpdateMemberToken, "hash"},
+	{"/web3password/admin/removeMember", model.AdminRemoveMemberToken, "hash"},
+	{"/web3password/admin/transferSuperAdmin", model.AdminTransferSuperAdminToken, "hash"},
+	{"/web3password/admin/getMemberList", model.AdminGetMemberListToken, "hash"},
+	{"/web3password/admin/getOrgInfo", model.AdminGetOrgInfoToken, "hash"},
+	{"/web3password/admin/updateOrgInfo", model.AdminUpdateOrgInfoToken, "hash"},
+	{"/web3password/admin/operationHistory", model.AdminOperationHistoryToken, "hash"},
+	{"/web3password/admin/getAdminShareMnemonic", model.AdminGetAdminMnemonicToken, "hash"},
+
+	{"/web3password/file/upload", model.FileUploadToken, "sha256"},
+	{"/web3password/file/uploadIocopy", model.FileUploadToken, "sha256"},
+	{"/web3password/file/uploadBufio", model.FileUploadToken, "sha256"},
+	{"/web3password/file/download", model.FileDownloadToken, "hash"},
+	{"/web3password/file/attachment", model.FileAttachmentToken, "hash"},
+	{"/web3password/file/report", model.FileReportToken, "hash"},
+
+	{"/web3password/sharefolder/create", model.ShareFolderCreateToken, "hash"},
+	{"/web3password/sharefolder/update", model.ShareFolderUpdateToken, "hash"},
+	{"/web3password/sharefolder/destroy", model.ShareFolderDestroyToken, "hash"},
+	{"/web3password/sharefolder/addrecord", model.ShareFolderAddRecordToken, "hash"},
+	{"/web3password/sharefolder/deleterecord", model.ShareFolderDeleteRecordToken, "hash"},
+	{"/web3password/sharefolder/addmember", model.ShareFolderAddMemberToken, "hash"},
+	{"/web3password/sharefolder/updatemember", model.ShareFolderUpdateMemberToken, "hash"},
+	{"/web3password/sharefolder/memberlist", model.ShareFolderMemberListToken, "hash"},
+	{"/web3password/sharefolder/memberexit", model.ShareFolderMemberExitToken, "hash"},
+	{"/web3password/sharefolder/deletemember", model.ShareFolderDeleteMemberToken, "hash"},
+	{"/web3password/sharefolder/batchUpdate", model.ShareFolderBatchUpdateToken, "hash"},
+	{"/web3password/sharefolder/folderlist", model.ShareFolderFolderListToken, "hash"},
+	{"/web3password/sharefolder/recordlist", model.ShareFolderRecordListToken, "hash"},
+	{"/web3password/sharefolder/recordlistbyrid", model.ShareFolderRecordListTokenByRid, "hash"},
+
+	{"/web3password/vip/getConfig", model.VipGetConfigToken, "hash"},
+	{"/web3password/vip/subscriptionList", model.VipSubscriptionListToken, "hash"},
+	{"/web3password/vip/createOrder", model.VipCreateOrderToken, "hash"},
+	{"/web3password/vip/checkOrder", model.VipCheckOrderToken, "hash"},
+	{"/web3password/vip/apple/in-app-purchase/verifyReceipt", model.VipAppleVerifyReceiptToken, "hash"},
+	{"/web3password/vip/register", model.AdminRegisterToken, "hash"},
+	{"/web3password/vip/paymentList", model.VipPaymentListToken, "hash"},
+	{"/web3password/vip/discount", model.VipDiscountToken, "hash"},
+	{"/web3password/vip/getOrderList", model.VipGetOrderList, "hash"},
+	{"/web3password/vip/getVipIOSPromotionSign", model.VipIOSPromotionSign, "hash"},
+	{"/web3password/vip/price", model.VipPrice, "hash"},
+}
+
+// LookupRoute finds a route by path, the leading /web3password may be left out.
+func LookupRoute(path string) (Route, bool) {
+	for _, r := range Routes {
+		if r.Path == path || r.Path == "/web3password"+path {
+			return r, true
+		}
+	}
+	return Route{}, false
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package client_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/satis/client"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/service"
+)
+
+// TestRoutesMatchRouters keeps Routes in step with the BSON routes of service.Routers.
+func TestRoutesMatchRouters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(`running_mode: official
+node:
+  token: test
+log_dir: %s
+http_server:
+  port: "8080"
+server:
+  port: "8081"
+`, t.TempDir())
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.ParseConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	gin.SetMode(gin.ReleaseMode)
+
+	registered := make(map[string]bool)
+	for _, r := range service.Routers().Routes() {
+		if r.Method == "POST" && strings.HasPrefix(r.Path, "/web3password/") {
+			registered[r.Path] = true
+			if _, ok := client.LookupRoute(r.Path); !ok {
+				t.Errorf("route %s is missing from client.Routes", r.Path)
+			}
+		}
+	}
+	listed := make(map[string]bool)
+	for _, r := range client.Routes {
+		if listed[r.Path] {
+			t.Errorf("route %s is listed twice", r.Path)
+		}
+		listed[r.Path] = true
+		if !registered[r.Path] {
+			t.Errorf("client route %s is not registered", r.Path)
+		}
+	}
+	for _, r := range client.Routes {
+		if r.HashField == "" {
+			t.Errorf("route %s has no hash field", r.Path)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package client
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs the params of a request for the address it holds.
+type Signer interface {
+	Address() string
+	Sign(params []byte) (string, error)
+}
+
+// SessionSigner signs with a session token, only the read-only routes accept it.
+type SessionSigner struct {
+	Addr  string
+	Token string
+}
+
+func (s SessionSigner) Address() string {
+	return s.Addr
+}
+
+func (s SessionSigner) Sign([]byte) (string, error) {
+	return s.Token, nil
+}
+
+// SigType is the sig_type the params are sent with.
+func (s SessionSigner) SigType() string {
+	return "session"
+}
+
+// KeySigner is the personal sign of a secp256k1 key, the default sig_type.
+type KeySigner struct {
+	key  *ecdsa.PrivateKey
+	addr string
+}
+
+// NewKeySigner parses a hex private key, with or without the 0x prefix.
+func NewKeySigner(hexKey string) (*KeySigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %s", err.Error())
+	}
+	return &KeySigner{key: key, addr: crypto.PubkeyToAddress(key.PublicKey).Hex()}, nil
+}
+
+// LoadKey reads a file holding a hex private key.
+func LoadKey(path string) (*KeySigner, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(string(b))
+}
+
+// GenerateKey creates a random key, for test accounts.
+func GenerateKey() (*KeySigner, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &KeySigner{key: key, addr: crypto.PubkeyToAddress(key.PublicKey).Hex()}, nil
+}
+
+// Hex is the private key, without the 0x prefix.
+func (s *KeySigner) Hex() string {
+	return hex.EncodeToString(crypto.FromECDSA(s.key))
+}
+
+func (s *KeySigner) Address() string {
+	return s.addr
+}
+
+// Sign signs the keccak256 of params, with the 27/28 recovery id the wallets use.
+func (s *KeySigner) Sign(params []byte) (string, error) {
+	sig, err := crypto.Sign(crypto.Keccak256(params), s.key)
+	if err != nil {
+		return "", err
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig), nil
+}
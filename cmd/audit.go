@@ -0,0 +1,41 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/web3password/satis/audit"
+)
+
+// runAudit implements `satis audit verify <dir|file>`.
+func runAudit(args []string) int {
+	if len(args) != 2 || args[0] != "verify" {
+		fmt.Fprintln(os.Stderr, "usage: satis audit verify <audit dir|audit file>")
+		return 2
+	}
+	result, err := audit.Verify(args[1])
+	if err != nil {
+		var chainErr *audit.ChainError
+		if errors.As(err, &chainErr) {
+			fmt.Fprintf(os.Stderr, "audit chain broken: %s\n", chainErr.Error())
+			if result != nil {
+				fmt.Fprintf(os.Stderr, "verified before break: files=%d records=%d last_seq=%d\n", result.Files, result.Records, result.LastSeq)
+			}
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "audit verify error: %s\n", err.Error())
+		return 1
+	}
+	fmt.Printf("audit chain ok: files=%d records=%d seq=%d..%d last_hash=%s\n", result.Files, result.Records, result.FirstSeq, result.LastSeq, result.LastHash)
+	return 0
+}
@@ -0,0 +1,57 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/web3password/satis/config"
+)
+
+// runConfig implements `satis config check [-conf config.yaml]`, it validates
+// the file and prints the effective config with secrets redacted.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: satis config check [-conf config.yaml]")
+		return 2
+	}
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	path := fs.String("conf", "config.yaml", "config file to check")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		*path = fs.Arg(0)
+	}
+
+	conf, err := config.LoadConfig(*path)
+	if err != nil {
+		var verr config.ValidationError
+		if errors.As(err, &verr) {
+			fmt.Fprintf(os.Stderr, "%s is invalid:\n", *path)
+			for _, fe := range verr {
+				fmt.Fprintf(os.Stderr, "  %s\n", fe.Error())
+			}
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "%s is invalid: %s\n", *path, err.Error())
+		return 1
+	}
+	dump, err := conf.Dump()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dump config error: %s\n", err.Error())
+		return 1
+	}
+	fmt.Printf("%s is valid, effective config:\n%s", *path, dump)
+	return 0
+}
@@ -11,15 +11,22 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net"
+	"os"
 	"time"
 
 	"google.golang.org/grpc/credentials"
 
 	"github.com/fvbock/endless"
-	"github.com/web3password/jewel/tools"
+	"github.com/web3password/satis/audit"
+	"github.com/web3password/satis/certs"
 	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/idempotency"
 	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/middleware"
+	"github.com/web3password/satis/model"
 	"github.com/web3password/satis/service"
 	"github.com/web3password/satis/service/handlers"
 	pb "github.com/web3password/w3p-protobuf/user"
@@ -44,15 +51,30 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "audit":
+			os.Exit(runAudit(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "errors":
+			fmt.Print(model.CodesMarkdown())
+			os.Exit(0)
+		}
+	}
 	flag.Parse()
+	if err := config.ParseConfig(*confPath); err != nil {
+		log.Fatalf("failed to load config err:%+v", err)
+	}
+	// the logger is set before the watcher starts, its reloads log through it
+	log.SetLogger()
+	config.Subscribe("restart", warnRestartRequired)
 	go config.WatchConfig(*confPath)
-	config.ParseConfig(*confPath)
 	conf := config.GetConfig()
 	listen, err := net.Listen(conf.GetServerProto(), conf.GetGRPCServerAddress())
 	if err != nil {
 		log.Fatalf("failed to listen err:%+v", err)
 	}
-	log.SetLogger()
 	options := []grpc.ServerOption{
 		grpc.KeepaliveEnforcementPolicy(kaep),
 		grpc.KeepaliveParams(kasp),
@@ -60,14 +82,15 @@ func main() {
 		grpc.MaxSendMsgSize(conf.MsgSize.File),
 	}
 	if conf.Server.EnableTLS {
-		tlsConfig, err := tools.TLSServerConfig(conf.Tls.Ca, conf.Tls.ServerTls.Crt, conf.Tls.ServerTls.Key)
+		reloader, err := certs.NewReloader(conf.Tls.Ca, conf.Tls.ServerTls.Crt, conf.Tls.ServerTls.Key)
 		if err != nil {
 			panic(err)
 		}
-		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		options = append(options, grpc.Creds(credentials.NewTLS(reloader.ServerConfig())))
 	}
 	s := grpc.NewServer(options...)
-	pb.RegisterUserServer(s, service.NewService(conf))
+	svc := service.NewService(conf)
+	pb.RegisterUserServer(s, svc)
 	log.Logger.Info("grpc server listening at", log.Any("port", listen.Addr()))
 	go func() {
 		if err = s.Serve(listen); err != nil {
@@ -75,8 +98,45 @@ func main() {
 		}
 	}()
 	handlers.Init(conf)
-	err = endless.ListenAndServe(conf.GetHttpServerAddress(), service.Routers())
+	handlers.SetService(svc)
+	idempotency.Init(conf)
+	// local_report rules may be set in any mode running the agent
+	if conf.RunningMode != consts.RunningModeOfficial {
+		middleware.InitOutbox(conf)
+	}
+	if conf.RunningMode == consts.RunningModeAudit {
+		audit.Init(conf)
+	}
+	err = listenAndServeHttp(conf)
 	if err != nil {
 		log.Logger.Error("server run error", log.Error(err))
 	}
 }
+
+// listenAndServeHttp serves the http routes, over TLS with reloaded
+// certificates when http_server.enable_tls is set.
+func listenAndServeHttp(conf *config.Config) error {
+	if !conf.HttpServer.EnableTLS {
+		return endless.ListenAndServe(conf.GetHttpServerAddress(), service.Routers())
+	}
+	reloader, err := certs.NewReloader(conf.HttpServer.ClientCa, conf.HttpServer.Crt, conf.HttpServer.Key)
+	if err != nil {
+		return err
+	}
+	srv := endless.NewServer(conf.GetHttpServerAddress(), service.Routers())
+	srv.TLSConfig = reloader.ServerConfig()
+	// endless loads the pair once, GetConfigForClient serves the reloaded one
+	return srv.ListenAndServeTLS(conf.HttpServer.Crt, conf.HttpServer.Key)
+}
+
+// restartSections are read once at startup, a reload cannot apply them.
+// Certificate files are reloaded on change, only new paths need a restart.
+var restartSections = []string{"running_mode", "server", "http_server", "tls", "msg"}
+
+func warnRestartRequired(_, _ *config.Config, changed []string) {
+	for _, section := range restartSections {
+		if config.SectionChanged(changed, section) {
+			log.Logger.Warn("config section changed, restart satis to apply it", log.String("section", section))
+		}
+	}
+}
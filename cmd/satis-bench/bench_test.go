@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/web3password/satis/client"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/satistest"
+)
+
+// The benchmarks share one in process satis, started by the first of them,
+// with the fake nodes of -nodes and -node-delay:
+//
+//	go test ./cmd/satis-bench -run '^$' -bench . -benchmem
+//	go test ./cmd/satis-bench -run '^$' -bench Upload -args -node-delay 5ms
+var (
+	benchOnce    sync.Once
+	benchHarness *satistest.Harness
+	benchClient  *client.Client
+	benchErr     error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchHarness != nil {
+		_ = benchHarness.Close()
+	}
+	os.Exit(code)
+}
+
+func inProcessClient(b *testing.B) *client.Client {
+	b.Helper()
+	benchOnce.Do(func() {
+		signer, err := client.GenerateKey()
+		if err != nil {
+			benchErr = err
+			return
+		}
+		if benchHarness, benchErr = startInProcess(); benchErr != nil {
+			return
+		}
+		benchClient = client.New(benchHarness.URL(), signer)
+		benchClient.HTTP.Transport = newTransport(64)
+	})
+	if benchErr != nil {
+		b.Fatal(benchErr)
+	}
+	return benchClient
+}
+
+// benchRoute calls route with data from parallel workers, -cpu sets how many.
+func benchRoute(b *testing.B, route string, params map[string]any, data []byte) {
+	c := inProcessClient(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			rsp, err := c.Call(context.Background(), route, params, data)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			if rsp.Code != model.StatusOK {
+				b.Errorf("%s answered %d %s", route, rsp.Code, rsp.Msg)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkUserRegister(b *testing.B) {
+	benchRoute(b, "/web3password/userRegister", nil, nil)
+}
+
+func BenchmarkAddCredential(b *testing.B) {
+	benchRoute(b, "/web3password/addCredential", map[string]any{"id": "bench", "op_timestamp": 1}, make([]byte, 1<<10))
+}
+
+func BenchmarkFileUpload(b *testing.B) {
+	for _, size := range []string{"1k", "64k", "1m"} {
+		b.Run(size, func(b *testing.B) {
+			sizes, err := parseSizes(size)
+			if err != nil {
+				b.Fatal(err)
+			}
+			benchRoute(b, "/web3password/file/upload", map[string]any{"org_id": "1"}, make([]byte, sizes[0]))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// satis-bench drives signed requests through the dispatch path of satis,
+// http, grpc, the node queues and the response wait, and reports throughput,
+// latency percentiles and memory. Without -url it starts satis in process
+// with fake ares, index and storage nodes answering success. The in process
+// runs are also go test benchmarks, see bench_test.go.
+//
+//	satis-bench -c 64 -n 20000
+//	satis-bench -c 64 -d 30s -node-delay 5ms
+//	satis-bench -route /web3password/file/upload -params '{"org_id":"1"}' -sizes 1k,1m,16m -c 8 -n 200
+//	satis-bench -url http://127.0.0.1:8080 -key user.key -route /web3password/userInfo
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/web3password/satis/client"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/satistest"
+	pb "github.com/web3password/w3p-protobuf/user"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	url         = flag.String("url", "", "base url of a running satis, satis runs in process without one")
+	keyFile     = flag.String("key", "", "file holding the hex private key, a new key is used without one")
+	route       = flag.String("route", "/web3password/userRegister", "route to call")
+	params      = flag.String("params", "{}", "params json, addr, timestamp, nonce, token and hash are filled in when missing")
+	sizes       = flag.String("sizes", "0", "comma separated data sizes, one run each, e.g. 0,1k,1m,16m")
+	concurrency = flag.Int("c", 16, "concurrent requests")
+	requests    = flag.Int("n", 10000, "requests per run")
+	duration    = flag.Duration("d", 0, "run for this long instead of -n requests")
+	nodes       = flag.Int("nodes", 1, "fake nodes per group, in process only")
+	nodeDelay   = flag.Duration("node-delay", 0, "time the fake nodes take to answer, in process only")
+)
+
+func main() {
+	flag.Parse()
+	if err := bench(); err != nil {
+		fmt.Fprintf(os.Stderr, "satis-bench: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func bench() error {
+	var m map[string]any
+	decoder := json.NewDecoder(strings.NewReader(*params))
+	decoder.UseNumber()
+	if err := decoder.Decode(&m); err != nil {
+		return fmt.Errorf("invalid params: %s", err.Error())
+	}
+	dataSizes, err := parseSizes(*sizes)
+	if err != nil {
+		return err
+	}
+	var signer *client.KeySigner
+	if *keyFile != "" {
+		signer, err = client.LoadKey(*keyFile)
+	} else {
+		signer, err = client.GenerateKey()
+	}
+	if err != nil {
+		return err
+	}
+
+	base := *url
+	var queueDepth func() int
+	if base == "" {
+		h, err := startInProcess()
+		if err != nil {
+			return err
+		}
+		defer h.Close()
+		base = h.URL()
+		queueDepth = func() int {
+			depth := 0
+			for _, group := range []string{model.ARES_PROXY, model.INDEX_PROXY, model.STORAGE_PROXY} {
+				for i := 0; i < *nodes; i++ {
+					depth += h.QueueDepth(nodeID(group, i))
+				}
+			}
+			return depth
+		}
+	}
+
+	c := client.New(base, signer)
+	c.HTTP.Transport = newTransport(*concurrency)
+	for _, size := range dataSizes {
+		r := run{
+			Route:       *route,
+			Params:      m,
+			Data:        make([]byte, size),
+			Concurrency: *concurrency,
+			Requests:    *requests,
+			Duration:    *duration,
+			QueueDepth:  queueDepth,
+		}
+		fmt.Println(r.exec(c))
+	}
+	return nil
+}
+
+// startInProcess starts satis with *nodes fake nodes in every group.
+func startInProcess() (*satistest.Harness, error) {
+	h, err := satistest.Start(satistest.Options{LogLevel: "error"})
+	if err != nil {
+		return nil, err
+	}
+	// answers both node formats, bson in data and json in params
+	reply := satistest.Reply(model.StatusOK, model.MsgOK, bson.M{})
+	reply.Params = fmt.Sprintf(`{"code":%d,"msg":%q}`, model.StatusOK, model.MsgOK)
+	delay := *nodeDelay
+	handler := func(*pb.StreamRsp) *pb.StreamReq {
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		return &pb.StreamReq{Params: reply.Params, Data: reply.Data}
+	}
+	for _, group := range []string{model.ARES_PROXY, model.INDEX_PROXY, model.STORAGE_PROXY} {
+		for i := 0; i < *nodes; i++ {
+			n, err := h.Node(group, nodeID(group, i))
+			if err != nil {
+				_ = h.Close()
+				return nil, err
+			}
+			n.HandleAll(handler)
+		}
+	}
+	return h, nil
+}
+
+func nodeID(group string, i int) string {
+	return fmt.Sprintf("bench-%s-%d", group, i)
+}
+
+// parseSizes parses sizes like 0,512,1k,16m.
+func parseSizes(s string) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(s, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		unit := 1
+		switch {
+		case strings.HasSuffix(item, "k"):
+			unit, item = 1<<10, strings.TrimSuffix(item, "k")
+		case strings.HasSuffix(item, "m"):
+			unit, item = 1<<20, strings.TrimSuffix(item, "m")
+		}
+		n, err := strconv.Atoi(item)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid size %q", item)
+		}
+		out = append(out, n*unit)
+	}
+	return out, nil
+}
+
+// newTransport keeps a connection per worker, the default two idle
+// connections per host would measure connection setup instead.
+func newTransport(concurrency int) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = concurrency
+	t.MaxIdleConnsPerHost = concurrency
+	return t
+}
@@ -0,0 +1,175 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/web3password/satis/client"
+)
+
+// run is one load run of a route with a data size.
+type run struct {
+	Route       string
+	Params      map[string]any
+	Data        []byte
+	Concurrency int
+	Requests    int           // total requests, unused when Duration is set
+	Duration    time.Duration // run for this long instead of Requests
+	// QueueDepth samples the depth of the node queues, nil against a remote instance.
+	QueueDepth func() int
+}
+
+type result struct {
+	Route     string
+	Size      int
+	Elapsed   time.Duration
+	Latencies []time.Duration
+	Codes     map[int]int
+	Errors    int // transport errors, no response
+	MaxQueue  int
+	// allocations of the process, satis and the load generator together
+	// when satis runs in process
+	AllocPerReq uint64
+	PeakHeap    uint64
+}
+
+func (r run) exec(c *client.Client) *result {
+	res := &result{Route: r.Route, Size: len(r.Data), Codes: make(map[int]int)}
+	var lock sync.Mutex
+	var issued int64
+	ctx := context.Background()
+	if r.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Duration)
+		defer cancel()
+	}
+	next := func() bool {
+		if r.Duration > 0 {
+			return ctx.Err() == nil
+		}
+		return atomic.AddInt64(&issued, 1) <= int64(r.Requests)
+	}
+
+	stop := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		r.sample(res, stop)
+	}()
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < r.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next() {
+				t := time.Now()
+				// a fresh context, the run deadline must not cut requests in flight
+				rsp, err := c.Call(context.Background(), r.Route, r.Params, r.Data)
+				latency := time.Since(t)
+				lock.Lock()
+				if err != nil {
+					res.Errors++
+				} else {
+					res.Codes[rsp.Code]++
+					res.Latencies = append(res.Latencies, latency)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	res.Elapsed = time.Since(start)
+	close(stop)
+	<-sampled
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	if n := len(res.Latencies) + res.Errors; n > 0 {
+		res.AllocPerReq = (after.TotalAlloc - before.TotalAlloc) / uint64(n)
+	}
+	sort.Slice(res.Latencies, func(i, j int) bool { return res.Latencies[i] < res.Latencies[j] })
+	return res
+}
+
+// sample records the peak heap and queue depth until stop is closed.
+func (r run) sample(res *result, stop chan struct{}) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	var m runtime.MemStats
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			runtime.ReadMemStats(&m)
+			if m.HeapInuse > res.PeakHeap {
+				res.PeakHeap = m.HeapInuse
+			}
+			if r.QueueDepth != nil {
+				if depth := r.QueueDepth(); depth > res.MaxQueue {
+					res.MaxQueue = depth
+				}
+			}
+		}
+	}
+}
+
+// percentile of the sorted latencies, p in [0, 100].
+func (r *result) percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(r.Latencies)-1) * p / 100)
+	return r.Latencies[i]
+}
+
+func (r *result) String() string {
+	n := len(r.Latencies) + r.Errors
+	codes := make([]int, 0, len(r.Codes))
+	for code := range r.Codes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	s := fmt.Sprintf("%s size=%s\n", r.Route, formatSize(r.Size))
+	s += fmt.Sprintf("  requests=%d errors=%d elapsed=%s rps=%.1f\n", n, r.Errors, r.Elapsed.Round(time.Millisecond), float64(n)/r.Elapsed.Seconds())
+	s += fmt.Sprintf("  latency p50=%s p90=%s p99=%s max=%s\n", r.percentile(50), r.percentile(90), r.percentile(99), r.percentile(100))
+	for _, code := range codes {
+		s += fmt.Sprintf("  code %d: %d\n", code, r.Codes[code])
+	}
+	s += fmt.Sprintf("  alloc/req=%s peak heap=%s", formatSize(int(r.AllocPerReq)), formatSize(int(r.PeakHeap)))
+	if r.MaxQueue > 0 {
+		s += fmt.Sprintf(" max queue=%d", r.MaxQueue)
+	}
+	return s
+}
+
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dm", n>>20)
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fm", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%dk", n>>10)
+	}
+	return fmt.Sprintf("%d", n)
+}
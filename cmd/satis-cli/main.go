@@ -0,0 +1,182 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// satis-cli sends signed requests to a satis instance and prints the
+// responses as JSON.
+//
+//	satis-cli keygen > user.key
+//	satis-cli call -key user.key -params '{"id":"1"}' /getCredential
+//	satis-cli call -key user.key -data record.bin /addCredential
+//	satis-cli call -key user.key -out vault.tar /vault/export
+//	satis-cli routes
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/web3password/satis/client"
+	"github.com/web3password/satis/vault"
+)
+
+const usage = `usage:
+  satis-cli call [flags] <route>   sign and send a request
+  satis-cli routes                 list the routes and their tokens
+  satis-cli keygen                 print a new private key and its address`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "call":
+		os.Exit(runCall(os.Args[2:]))
+	case "routes":
+		for _, r := range client.Routes {
+			fmt.Printf("%-60s %-35s %s\n", r.Path, r.Token, r.HashField)
+		}
+	case "keygen":
+		signer, err := client.GenerateKey()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "keygen error: %s\n", err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(signer.Hex())
+		fmt.Fprintf(os.Stderr, "address: %s\n", signer.Address())
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runCall(args []string) int {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	url := fs.String("url", "http://127.0.0.1:8080", "base url of the satis http api")
+	keyFile := fs.String("key", "", "file holding the hex private key, SATIS_KEY is used without one")
+	params := fs.String("params", "{}", "params json, addr, timestamp, nonce, token and hash are filled in when missing")
+	raw := fs.Bool("raw", false, "sign and send -params as they are")
+	dataFile := fs.String("data", "", "file appended as the request data")
+	outFile := fs.String("out", "", "file the vault archive of /vault/export is written to")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: satis-cli call [-url url] [-key file] [-params json] [-raw] [-data file] [-out file] <route>")
+		return 2
+	}
+
+	var signer *client.KeySigner
+	var err error
+	if *keyFile != "" {
+		signer, err = client.LoadKey(*keyFile)
+	} else if key := os.Getenv("SATIS_KEY"); key != "" {
+		signer, err = client.NewKeySigner(key)
+	} else {
+		err = fmt.Errorf("no key, set -key or SATIS_KEY")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "key error: %s\n", err.Error())
+		return 1
+	}
+	var data []byte
+	if *dataFile != "" {
+		if data, err = os.ReadFile(*dataFile); err != nil {
+			fmt.Fprintf(os.Stderr, "data error: %s\n", err.Error())
+			return 1
+		}
+	}
+
+	c := client.New(*url, signer)
+	path := fs.Arg(0)
+	var rsp *client.Response
+	if *outFile != "" {
+		var parts []string
+		if parts, rsp, err = export(c, *params, *outFile); err == nil && rsp == nil {
+			fmt.Fprintf(os.Stderr, "vault archive written to %s\n", strings.Join(parts, " "))
+			return 0
+		}
+	} else if *raw {
+		if route, ok := client.LookupRoute(path); ok {
+			path = route.Path
+		}
+		rsp, err = c.Post(context.Background(), path, *params, data)
+	} else {
+		var m map[string]any
+		decoder := json.NewDecoder(bytes.NewReader([]byte(*params)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&m); err != nil {
+			fmt.Fprintf(os.Stderr, "params error: %s\n", err.Error())
+			return 1
+		}
+		rsp, err = c.Call(context.Background(), path, m, data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "request error: %s\n", err.Error())
+		return 1
+	}
+	fmt.Println(string(rsp.JSON()))
+	return 0
+}
+
+// export writes the vault archive to out, removing it when the export is
+// refused. An export stopped before its signature expired goes on in out.2,
+// out.3..., each part signed anew with the resume of the previous one. The
+// parts written are returned.
+func export(c *client.Client, params, out string) ([]string, *client.Response, error) {
+	var m map[string]any
+	if err := json.Unmarshal([]byte(params), &m); err != nil {
+		return nil, nil, err
+	}
+	var parts []string
+	for name := out; ; name = fmt.Sprintf("%s.%d", out, len(parts)+1) {
+		resume, rsp, err := exportPart(c, m, name)
+		if err != nil || rsp != nil {
+			return parts, rsp, err
+		}
+		parts = append(parts, name)
+		if resume == nil {
+			return parts, nil, nil
+		}
+		// the next part is signed with a fresh timestamp and nonce
+		delete(m, "timestamp")
+		delete(m, "nonce")
+		m["resume_kind"], m["resume_cursor"] = resume.Kind, resume.Cursor
+	}
+}
+
+// exportPart writes a part of the vault archive to name and returns its resume.
+func exportPart(c *client.Client, params map[string]any, name string) (*vault.Resume, *client.Response, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	rsp, err := c.Export(context.Background(), params, f)
+	var manifest *vault.Manifest
+	if err == nil && rsp == nil {
+		if _, err = f.Seek(0, io.SeekStart); err == nil {
+			manifest, err = vault.ReadManifest(f)
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || rsp != nil {
+		os.Remove(name)
+		return nil, rsp, err
+	}
+	return manifest.Resume, nil, nil
+}
@@ -10,33 +10,166 @@ For more information, please refer to https://www.web3password.com/web3password_
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"sync"
 
-	"github.com/fsnotify/fsnotify"
 	"gopkg.in/yaml.v3"
 )
 
 var (
-	config *Config
-	lock   = sync.RWMutex{}
+	config    *Config
+	configSum [sha256.Size]byte
+	lock      = sync.RWMutex{}
 )
 
 // Config .
 type Config struct {
-	RunningMode       string     `yaml:"running_mode"`
-	Server            Server     `yaml:"server"`      // server start config
-	HttpServer        HttpServer `yaml:"http_server"` // http server start config
-	Node              Node       `yaml:"node"`        // node config
-	MsgSize           Msg        `yaml:"msg"`         // msg size
-	LogDir            string     `yaml:"log_dir"`
-	Tls               Tls        `yaml:"tls"`
-	OfficialDomain    string     `yaml:"official_domain"`  // official_domain
-	OfficialDomains   []string   `yaml:"official_domains"` // official_domains
-	PersonalWhiteList string     `yaml:"personal_white_list"`
-	OrgWhiteList      string     `yaml:"org_white_list"`
+	RunningMode       string        `yaml:"running_mode"`
+	Server            Server        `yaml:"server"`      // server start config
+	HttpServer        HttpServer    `yaml:"http_server"` // http server start config
+	Node              Node          `yaml:"node"`        // node config
+	MsgSize           Msg           `yaml:"msg"`         // msg size
+	LogDir            string        `yaml:"log_dir"`
+	Log               Log           `yaml:"log"`
+	Tls               Tls           `yaml:"tls"`
+	OfficialDomain    string        `yaml:"official_domain"`     // official_domain
+	OfficialDomains   []string      `yaml:"official_domains"`    // official_domains
+	PersonalWhiteList string        `yaml:"personal_white_list"` // legacy allow list of addresses for local routes, comma separated
+	OrgWhiteList      string        `yaml:"org_white_list"`      // legacy allow list of org ids for local routes, comma separated
+	AccessControl     AccessControl `yaml:"access_control"`
+	RoutePolicy       RoutePolicy   `yaml:"route_policy"`  // agent route policy
+	ReportOutbox      ReportOutbox  `yaml:"report_outbox"` // outbox of the local_report routes
+	Upstream          Upstream      `yaml:"upstream"`      // official domain upstream pool
+	Audit             Audit         `yaml:"audit"`         // audit mode sink
+	Session           Session       `yaml:"session"`       // session tokens for read-only routes
+	Idempotency       Idempotency   `yaml:"idempotency"`   // replay of retried mutating requests
+	Events            Events        `yaml:"events"`        // change notifications pushed to clients
+	Ops               Ops           `yaml:"ops"`           // access to the /satis endpoints
+	WebAuthn          WebAuthn      `yaml:"webauthn"`      // relying party of the passkey signatures
+
+	sources map[string]string // yaml path -> source of the values not read from the file
+}
+
+// Log configures the logger, everything but the format is applied on reload.
+type Log struct {
+	Level   string    `yaml:"level"`    // debug, info, warn, error, default info
+	Format  string    `yaml:"format"`   // json or console, default json
+	Stdout  bool      `yaml:"stdout"`   // also write to stdout, for containers
+	MaxSize int       `yaml:"max_size"` // rotate after this many MB as well as daily, 0 disables
+	MaxAge  int       `yaml:"max_age"`  // days to keep rotated files, default 7
+	Redact  LogRedact `yaml:"redact"`
+}
+
+// LogRedact configures what reaches the logs. Signatures, mnemonics, tokens,
+// data, content and body fields are always masked unless disabled, which
+// official mode ignores.
+type LogRedact struct {
+	Disable bool     `yaml:"disable"` // log sensitive fields in clear, for debugging a local node
+	Fields  []string `yaml:"fields"`  // more field names to mask
+	Headers []string `yaml:"headers"` // request headers to log, default a safe list
+	Address string   `yaml:"address"` // plain or hash addresses and org ids, default hash in official mode
+}
+
+// Audit configures the audit mode sink.
+type Audit struct {
+	Dir      string `yaml:"dir"`       // default log_dir/audit
+	MaxSize  int    `yaml:"max_size"`  // rotate after this many MB, default 100
+	WithBody bool   `yaml:"with_body"` // keep the request body, not only its hash
+}
+
+// Session configures the short-lived tokens exchanged for a personal_auth at
+// /web3password/session. Everything is read per request, a reload applies it.
+type Session struct {
+	Enable       bool     `yaml:"enable"`
+	Secret       string   `yaml:"secret" secret:"true"` // hmac key, random per process when empty
+	SecretFile   string   `yaml:"secret_file"`          // read the secret from this file instead
+	TTL          int      `yaml:"ttl"`                  // seconds, default 900, never past the personal_auth expiry
+	RevokeBefore int64    `yaml:"revoke_before"`        // unix time, tokens issued earlier are rejected
+	RevokedAddrs []string `yaml:"revoked_addrs"`        // addresses whose tokens are rejected
+}
+
+// Idempotency configures the Idempotency-Key of mutating routes. Store and
+// dir are read at startup, the rest per request.
+type Idempotency struct {
+	Enable bool     `yaml:"enable"`
+	TTL    int      `yaml:"ttl"`    // seconds a response is kept for retries, default 86400
+	Store  string   `yaml:"store"`  // memory or dir, default memory
+	Dir    string   `yaml:"dir"`    // dir store, default log_dir/idempotency, may be shared by the instances
+	Routes []string `yaml:"routes"` // routes in addition to the built-in ones
+}
+
+// Events configures the /web3password/events streams, read per subscription.
+type Events struct {
+	Heartbeat   int `yaml:"heartbeat"`    // seconds between keep-alive comments, default 25
+	MaxLifetime int `yaml:"max_lifetime"` // seconds before a stream is closed and the client reconnects, default 3600
+	MaxPerAddr  int `yaml:"max_per_addr"` // streams open at once per address, default 8
+}
+
+// Upstream configures the pool of official domains, durations are in seconds.
+type Upstream struct {
+	DialTimeout    int    `yaml:"dial_timeout"`    // default 5
+	RequestTimeout int    `yaml:"request_timeout"` // default 60
+	MaxRetries     int    `yaml:"max_retries"`     // retries on another domain for safe requests, default 2
+	FailThreshold  int    `yaml:"fail_threshold"`  // consecutive failures before a domain is marked down, default 3
+	Cooldown       int    `yaml:"cooldown"`        // how long a down domain is skipped, default 30
+	HealthInterval int    `yaml:"health_interval"` // active health check interval, default 10, negative disables
+	HealthPath     string `yaml:"health_path"`     // default /favicon.ico
+}
+
+// AccessControl configures the allow and deny lists of the node.
+type AccessControl struct {
+	File  string       `yaml:"file"` // optional yaml file with more lists, reloaded on change
+	Lists []AccessList `yaml:"lists"`
+}
+
+// AccessList is evaluated for the routes in scope: "*", "local" (routes served
+// by this node), a route group (user, admin, file, sharefolder, vip) or an exact path.
+// Deny entries always win. When a list has allow entries, the address or the
+// org_id of the request must match one of them.
+type AccessList struct {
+	Name       string   `yaml:"name"`
+	Scope      []string `yaml:"scope"`       // default "local"
+	AllowAddrs []string `yaml:"allow_addrs"` // exact addresses
+	DenyAddrs  []string `yaml:"deny_addrs"`
+	AllowOrgs  []string `yaml:"allow_orgs"` // exact org ids or patterns such as "org-*"
+	DenyOrgs   []string `yaml:"deny_orgs"`
+}
+
+// ReportOutbox configures the on-disk outbox of events reported to the official domain.
+type ReportOutbox struct {
+	Dir         string `yaml:"dir"`          // default log_dir/report_outbox
+	MaxAttempts int    `yaml:"max_attempts"` // attempts before an event is parked as failed, default 20
+}
+
+// Ops guards the /satis endpoints with a bearer token, read per request.
+type Ops struct {
+	Token     string `yaml:"token" secret:"true"` // the endpoints answer 404 when empty
+	TokenFile string `yaml:"token_file"`          // read the token from this file instead
+}
+
+// WebAuthn binds the passkey signatures to the relying party, read per
+// request. The webauthn sig_type is refused without rp_id.
+type WebAuthn struct {
+	RPID    string   `yaml:"rp_id"`   // domain the passkeys are registered for
+	Origins []string `yaml:"origins"` // allowed client data origins, default https://<rp_id>
+}
+
+// RoutePolicy declares how the agent middleware treats each route.
+// Routes override the built-in table of the current running mode.
+type RoutePolicy struct {
+	Default string      `yaml:"default"` // action for routes not listed, default proxy
+	Routes  []RouteRule `yaml:"routes"`
+}
+
+// RouteRule binds an exact request path to an action: local, proxy, local_report or deny.
+type RouteRule struct {
+	Path    string `yaml:"path"`
+	Action  string `yaml:"action"`
+	Timeout int    `yaml:"timeout"` // proxy timeout in seconds, default upstream.request_timeout
 }
 
 type Tls struct {
@@ -64,16 +197,23 @@ type HttpServer struct {
 	IP          string `yaml:"ip"`
 	Port        string `yaml:"port"`
 	WithTraceID bool   `yaml:"with_trace_id"`
+	ErrorStatus bool   `yaml:"error_status"` // send catalog errors with the HTTP status of their category, not 200
+	EnableTLS   bool   `yaml:"enable_tls"`
+	Crt         string `yaml:"crt"`
+	Key         string `yaml:"key"`
+	ClientCa    string `yaml:"client_ca"` // optional, requires client certificates signed by it
 }
 
 // Node .
 type Node struct {
-	Token string `yaml:"token"`
+	Token     string `yaml:"token" secret:"true"`
+	TokenFile string `yaml:"token_file"` // read the token from this file instead
 }
 
+// Msg bounds the message sizes in bytes, 0 is the default.
 type Msg struct {
-	Api  int `yaml:"api"`
-	File int `yaml:"file"`
+	Api  int `yaml:"api"`  // default 1048576
+	File int `yaml:"file"` // default 62914560
 }
 
 // GetServerProto .
@@ -91,57 +231,60 @@ func (c *Config) GetHttpServerAddress() string {
 	return fmt.Sprintf("%s:%s", c.HttpServer.IP, c.HttpServer.Port)
 }
 
-func WatchConfig(path string) {
-	watcher, err := fsnotify.NewWatcher()
+// LoadConfig reads a config file, applies the SATIS_* environment and secret
+// file overrides, then defaults and validates it. Unknown keys are rejected.
+func LoadConfig(path string) (*Config, error) {
+	c, _, err := loadConfigFile(path)
+	return c, err
+}
+
+// loadConfigFile is LoadConfig that also returns the sha256 of the file content
+// and of the overridden values.
+func loadConfigFile(path string) (*Config, [sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return nil, [sha256.Size]byte{}, fmt.Errorf("open config file: %w", err)
 	}
-	defer watcher.Close()
-
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Has(fsnotify.Write) {
-					ParseConfig(path)
-					fmt.Println("modified file:", event.Name, config.MsgSize.File)
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				fmt.Println("error:", err)
-			}
-		}
-	}()
-	err = watcher.Add(path)
+	c, err := decodeConfig(data)
 	if err != nil {
-		panic(err)
+		return nil, sha256.Sum256(data), fmt.Errorf("parse config file %s: %w", path, err)
 	}
-	select {}
-}
-func ParseConfig(path string) {
-	fmt.Println("load config", path)
-	file, err := os.Open(path)
-	if err != nil {
-		panic("failed open config file err")
+	if err = c.applyOverrides(os.LookupEnv); err != nil {
+		return nil, sha256.Sum256(data), err
 	}
-	fileByte, err := io.ReadAll(file)
-	if err != nil {
-		panic("failed read config file err")
+	sum := sha256.Sum256(append(data, c.overrideDigest()...))
+	c.SetDefaults()
+	if err = c.Validate(); err != nil {
+		return nil, sum, err
 	}
+	return c, sum, nil
+}
+
+func decodeConfig(data []byte) (*Config, error) {
 	c := &Config{}
-	if err = yaml.Unmarshal(fileByte, c); err != nil {
-		panic("parse config file err")
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ParseConfig loads the config file and makes it the current config.
+func ParseConfig(path string) error {
+	fmt.Println("load config", path)
+	c, sum, err := loadConfigFile(path)
+	if err != nil {
+		return err
 	}
 
 	lock.Lock()
 	defer lock.Unlock()
 	config = c
+	configSum = sum
+	return nil
 }
+
 func GetConfig() *Config {
 	lock.RLock()
 	defer lock.RUnlock()
@@ -0,0 +1,171 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix     = "SATIS_"
+	fileKeySuffix = "_file"
+
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// EnvName returns the environment variable that overrides a yaml field path,
+// e.g. node.token is SATIS_NODE_TOKEN.
+func EnvName(field string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(field, ".", "_"))
+}
+
+// applyOverrides applies the SATIS_* environment variables, then loads every
+// secret field from its `<key>_file` sibling unless the secret itself came
+// from the environment. Scalars take the raw value, string lists a comma
+// separated value, anything else a yaml or json document.
+func (c *Config) applyOverrides(lookup func(string) (string, bool)) error {
+	var errs ValidationError
+	walkFields(reflect.ValueOf(c).Elem(), "", func(field string, v reflect.Value, _ reflect.StructField) {
+		name := EnvName(field)
+		value, ok := lookup(name)
+		if !ok {
+			return
+		}
+		if err := setFieldValue(v, value); err != nil {
+			errs = append(errs, FieldError{Field: field, Msg: fmt.Sprintf("invalid %s: %s", name, err.Error())})
+			return
+		}
+		c.setSource(field, "env "+name)
+	})
+
+	walkFields(reflect.ValueOf(c).Elem(), "", func(field string, v reflect.Value, f reflect.StructField) {
+		if f.Tag.Get(secretTagName) != secretTagEnable || v.Kind() != reflect.String {
+			return
+		}
+		if strings.HasPrefix(c.Source(field), "env ") {
+			return
+		}
+		path := c.lookupString(field + fileKeySuffix)
+		if path == "" {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, FieldError{Field: field + fileKeySuffix, Msg: err.Error()})
+			return
+		}
+		v.SetString(strings.TrimRight(string(data), "\r\n"))
+		c.setSource(field, "file "+path)
+	})
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// walkFields calls fn for every leaf field with its yaml path, descending into nested structs.
+func walkFields(v reflect.Value, prefix string, fn func(field string, v reflect.Value, f reflect.StructField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := yamlKey(f)
+		if !f.IsExported() || key == "-" {
+			continue
+		}
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		if v.Field(i).Kind() == reflect.Struct {
+			walkFields(v.Field(i), field, fn)
+			continue
+		}
+		fn(field, v.Field(i), f)
+	}
+}
+
+func setFieldValue(v reflect.Value, value string) error {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "["):
+		list := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = reflect.Append(list, reflect.ValueOf(item))
+			}
+		}
+		v.Set(list)
+		return nil
+	}
+	ptr := reflect.New(v.Type())
+	if err := yaml.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+// lookupString returns the string field at a yaml path, empty when there is none.
+func (c *Config) lookupString(field string) string {
+	value := ""
+	walkFields(reflect.ValueOf(c).Elem(), "", func(name string, v reflect.Value, _ reflect.StructField) {
+		if name == field && v.Kind() == reflect.String {
+			value = v.String()
+		}
+	})
+	return value
+}
+
+func (c *Config) setSource(field, source string) {
+	if c.sources == nil {
+		c.sources = make(map[string]string)
+	}
+	c.sources[field] = source
+}
+
+// Source tells where the effective value of a yaml field path came from:
+// "file", "default", "env SATIS_..." or "file <path>" for a secret file.
+func (c *Config) Source(field string) string {
+	if source, ok := c.sources[field]; ok {
+		return source
+	}
+	return SourceFile
+}
+
+// overrideDigest renders the overridden values, so a reload notices a changed
+// environment or secret file even when the config file itself did not change.
+func (c *Config) overrideDigest() []byte {
+	fields := make([]string, 0, len(c.sources))
+	for field, source := range c.sources {
+		if source != SourceDefault {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	var b strings.Builder
+	v := reflect.ValueOf(c).Elem()
+	walkFields(v, "", func(field string, fv reflect.Value, _ reflect.StructField) {
+		i := sort.SearchStrings(fields, field)
+		if i < len(fields) && fields[i] == field {
+			fmt.Fprintf(&b, "%s=%v\n", field, fv.Interface())
+		}
+	})
+	return []byte(b.String())
+}
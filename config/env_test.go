@@ -0,0 +1,185 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for field, want := range map[string]string{
+		"node.token":            "SATIS_NODE_TOKEN",
+		"official_domains":      "SATIS_OFFICIAL_DOMAINS",
+		"events.max_per_addr":   "SATIS_EVENTS_MAX_PER_ADDR",
+		"upstream.dial_timeout": "SATIS_UPSTREAM_DIAL_TIMEOUT",
+	} {
+		if got := EnvName(field); got != want {
+			t.Errorf("EnvName(%s) = %s, want %s", field, got, want)
+		}
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		check  func(c *Config) bool
+		source map[string]string
+	}{
+		{"string", map[string]string{"SATIS_NODE_TOKEN": "from-env"},
+			func(c *Config) bool { return c.Node.Token == "from-env" },
+			map[string]string{"node.token": "env SATIS_NODE_TOKEN"}},
+		{"int", map[string]string{"SATIS_EVENTS_MAX_PER_ADDR": "3"},
+			func(c *Config) bool { return c.Events.MaxPerAddr == 3 },
+			map[string]string{"events.max_per_addr": "env SATIS_EVENTS_MAX_PER_ADDR"}},
+		{"bool", map[string]string{"SATIS_SESSION_ENABLE": "true"},
+			func(c *Config) bool { return c.Session.Enable },
+			map[string]string{"session.enable": "env SATIS_SESSION_ENABLE"}},
+		{"comma separated list", map[string]string{"SATIS_OFFICIAL_DOMAINS": "http://a, http://b,"},
+			func(c *Config) bool { return reflect.DeepEqual(c.OfficialDomains, []string{"http://a", "http://b"}) },
+			map[string]string{"official_domains": "env SATIS_OFFICIAL_DOMAINS"}},
+		{"yaml list", map[string]string{"SATIS_OFFICIAL_DOMAINS": `["http://a,b"]`},
+			func(c *Config) bool { return reflect.DeepEqual(c.OfficialDomains, []string{"http://a,b"}) },
+			nil},
+		{"list of structs", map[string]string{"SATIS_ROUTE_POLICY_ROUTES": `[{path: /web3password/vip/price, action: deny}]`},
+			func(c *Config) bool {
+				return len(c.RoutePolicy.Routes) == 1 && c.RoutePolicy.Routes[0].Action == "deny"
+			},
+			map[string]string{"route_policy.routes": "env SATIS_ROUTE_POLICY_ROUTES"}},
+		{"unrelated variables", map[string]string{"SATIS_UNKNOWN": "x", "NODE_TOKEN": "x"},
+			func(c *Config) bool { return c.Node.Token == "test" },
+			map[string]string{"node.token": SourceFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeConfig([]byte(minimalYAML))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lookup := func(name string) (string, bool) {
+				v, ok := tt.env[name]
+				return v, ok
+			}
+			if err := c.applyOverrides(lookup); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(c) {
+				t.Errorf("override not applied: %+v", c)
+			}
+			for field, want := range tt.source {
+				if got := c.Source(field); got != want {
+					t.Errorf("source of %s is %q, want %q", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvOverrideErrors(t *testing.T) {
+	c, err := decodeConfig([]byte(minimalYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"SATIS_EVENTS_HEARTBEAT": "soon", "SATIS_SESSION_ENABLE": "maybe"}
+	err = c.applyOverrides(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+	fields := invalidFields(t, err)
+	if !reflect.DeepEqual(fields, []string{"session.enable", "events.heartbeat"}) {
+		t.Fatalf("invalid fields %v (%v)", fields, err)
+	}
+	if !strings.Contains(err.Error(), "invalid SATIS_EVENTS_HEARTBEAT") {
+		t.Errorf("error %v does not name the variable", err)
+	}
+}
+
+func TestSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "token")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	unreadable := filepath.Join(dir, "unreadable")
+	if err := os.WriteFile(unreadable, []byte("x"), 0); err != nil {
+		t.Fatal(err)
+	}
+	withFile := func(path string) string {
+		return strings.Replace(minimalYAML, "token: test", "token: \"\"\n  token_file: "+path, 1)
+	}
+	type secretCase struct {
+		name     string
+		yaml     string
+		env      map[string]string
+		token    string
+		source   string
+		errField string
+	}
+	tests := []secretCase{
+		{"file", withFile(secret), nil, "from-file", "file " + secret, ""},
+		{"file from env", minimalYAML, map[string]string{"SATIS_NODE_TOKEN_FILE": secret}, "from-file", "file " + secret, ""},
+		{"env wins over the file", withFile(secret), map[string]string{"SATIS_NODE_TOKEN": "from-env"}, "from-env", "env SATIS_NODE_TOKEN", ""},
+		{"missing file", withFile(filepath.Join(dir, "missing")), nil, "", "", "node.token_file"},
+		{"directory", withFile(dir), nil, "", "", "node.token_file"},
+	}
+	// root reads files whatever their mode
+	if os.Geteuid() != 0 {
+		tests = append(tests, secretCase{"unreadable file", withFile(unreadable), nil, "", "", "node.token_file"})
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeConfig([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.applyOverrides(func(name string) (string, bool) {
+				v, ok := tt.env[name]
+				return v, ok
+			})
+			if tt.errField != "" {
+				if fields := invalidFields(t, err); !reflect.DeepEqual(fields, []string{tt.errField}) {
+					t.Fatalf("invalid fields %v, want %s", fields, tt.errField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Node.Token != tt.token || c.Source("node.token") != tt.source {
+				t.Errorf("token %q from %q, want %q from %q", c.Node.Token, c.Source("node.token"), tt.token, tt.source)
+			}
+			if dump, err := c.Dump(); err != nil || strings.Contains(dump, tt.token) {
+				t.Errorf("dump shows the secret: %v\n%s", err, dump)
+			}
+		})
+	}
+}
+
+func TestLoadConfigReadsEnvironment(t *testing.T) {
+	t.Setenv("SATIS_NODE_TOKEN", "")
+	t.Setenv("SATIS_HTTP_SERVER_PORT", "9000")
+	_, err := LoadConfig(writeConfig(t, minimalYAML))
+	// an empty variable is set, it overrides the file and fails validation
+	if fields := invalidFields(t, err); !reflect.DeepEqual(fields, []string{"node.token"}) {
+		t.Fatalf("invalid fields %v (%v)", fields, err)
+	}
+	t.Setenv("SATIS_NODE_TOKEN", "from-env")
+	c, err := LoadConfig(writeConfig(t, minimalYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Node.Token != "from-env" || c.HttpServer.Port != "9000" {
+		t.Errorf("token %q port %q", c.Node.Token, c.HttpServer.Port)
+	}
+}
@@ -0,0 +1,346 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/web3password/satis/consts"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultMsgApi     = 1048576
+	defaultMsgFile    = 62914560
+	defaultProto      = "tcp"
+	defaultLogDir     = "logs"
+	defaultSessionTTL = 900
+	defaultIdemTTL    = 86400
+	defaultIdemStore  = "memory"
+	defaultHeartbeat  = 25
+	defaultLifetime   = 3600
+	defaultPerAddr    = 8
+	minSessionSecret  = 32
+	redactedValue     = "******"
+	secretTagName     = "secret"
+	secretTagEnable   = "true"
+)
+
+// FieldError is a validation error of one config field, named by its yaml path.
+type FieldError struct {
+	Field string
+	Msg   string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+// ValidationError lists every invalid field of a config.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// SetDefaults fills the optional fields left empty. A zero value counts as
+// unset, an explicit 0 such as msg.file: 0 takes the default as well.
+func (c *Config) SetDefaults() {
+	if len(c.OfficialDomains) == 0 && c.OfficialDomain != "" {
+		c.OfficialDomains = []string{c.OfficialDomain}
+		c.setSource("official_domains", SourceDefault)
+	}
+	if c.MsgSize.Api == 0 {
+		c.MsgSize.Api = defaultMsgApi
+		c.setSource("msg.api", SourceDefault)
+	}
+	if c.MsgSize.File == 0 {
+		c.MsgSize.File = defaultMsgFile
+		c.setSource("msg.file", SourceDefault)
+	}
+	if c.Server.Proto == "" {
+		c.Server.Proto = defaultProto
+		c.setSource("server.proto", SourceDefault)
+	}
+	if c.LogDir == "" {
+		c.LogDir = defaultLogDir
+		c.setSource("log_dir", SourceDefault)
+	}
+	if c.Session.TTL == 0 {
+		c.Session.TTL = defaultSessionTTL
+		c.setSource("session.ttl", SourceDefault)
+	}
+	if c.Idempotency.TTL == 0 {
+		c.Idempotency.TTL = defaultIdemTTL
+		c.setSource("idempotency.ttl", SourceDefault)
+	}
+	if c.Idempotency.Store == "" {
+		c.Idempotency.Store = defaultIdemStore
+		c.setSource("idempotency.store", SourceDefault)
+	}
+	if c.Events.Heartbeat == 0 {
+		c.Events.Heartbeat = defaultHeartbeat
+		c.setSource("events.heartbeat", SourceDefault)
+	}
+	if c.Events.MaxLifetime == 0 {
+		c.Events.MaxLifetime = defaultLifetime
+		c.setSource("events.max_lifetime", SourceDefault)
+	}
+	if c.Events.MaxPerAddr == 0 {
+		c.Events.MaxPerAddr = defaultPerAddr
+		c.setSource("events.max_per_addr", SourceDefault)
+	}
+}
+
+// Validate checks the config and reports every invalid field.
+func (c *Config) Validate() error {
+	var errs ValidationError
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	switch c.RunningMode {
+	case consts.RunningModeOfficial, consts.RunningModeAudit, consts.RunningModeLocal:
+	case "":
+		add("running_mode", "is required, one of official, audit, local")
+	default:
+		add("running_mode", "unknown mode %q, one of official, audit, local", c.RunningMode)
+	}
+	if c.RunningMode == consts.RunningModeAudit || c.RunningMode == consts.RunningModeLocal {
+		if len(c.OfficialDomains) == 0 {
+			add("official_domains", "at least one domain is required in %s mode", c.RunningMode)
+		}
+	}
+	for i, domain := range c.OfficialDomains {
+		u, err := url.Parse(domain)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(fmt.Sprintf("official_domains[%d]", i), "%q is not an http or https url", domain)
+		}
+	}
+
+	if c.Node.Token == "" {
+		add("node.token", "is required, set it, node.token_file, %s or %s_FILE", EnvName("node.token"), EnvName("node.token"))
+	}
+	checkPort(add, "server.port", c.Server.Port)
+	checkPort(add, "http_server.port", c.HttpServer.Port)
+	if c.Server.Proto != "tcp" && c.Server.Proto != "tcp4" && c.Server.Proto != "tcp6" {
+		add("server.proto", "unknown network %q, one of tcp, tcp4, tcp6", c.Server.Proto)
+	}
+	if c.Server.EnableTLS {
+		for field, value := range map[string]string{
+			"tls.ca":         c.Tls.Ca,
+			"tls.server.crt": c.Tls.ServerTls.Crt,
+			"tls.server.key": c.Tls.ServerTls.Key,
+			"tls.client.crt": c.Tls.ClientTls.Crt,
+			"tls.client.key": c.Tls.ClientTls.Key,
+		} {
+			if value == "" {
+				add(field, "is required when server.enable_tls is set")
+			}
+		}
+	}
+
+	if c.HttpServer.EnableTLS {
+		if c.HttpServer.Crt == "" {
+			add("http_server.crt", "is required when http_server.enable_tls is set")
+		}
+		if c.HttpServer.Key == "" {
+			add("http_server.key", "is required when http_server.enable_tls is set")
+		}
+	}
+
+	switch c.Log.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		add("log.level", "unknown level %q, one of debug, info, warn, error", c.Log.Level)
+	}
+	switch c.Log.Format {
+	case "", "json", "console":
+	default:
+		add("log.format", "unknown format %q, one of json, console", c.Log.Format)
+	}
+	switch c.Log.Redact.Address {
+	case "", "plain", "hash":
+	default:
+		add("log.redact.address", "unknown mode %q, one of plain, hash", c.Log.Redact.Address)
+	}
+
+	// 0 is the default, only a negative size is left to refuse
+	if c.MsgSize.Api < 0 {
+		add("msg.api", "must be positive")
+	}
+	if c.MsgSize.File < 0 {
+		add("msg.file", "must be positive")
+	}
+	if c.MsgSize.Api > c.MsgSize.File {
+		add("msg.api", "must not exceed msg.file (%d)", c.MsgSize.File)
+	}
+
+	if c.RoutePolicy.Default != "" && !isRouteAction(c.RoutePolicy.Default) {
+		add("route_policy.default", "unknown action %q", c.RoutePolicy.Default)
+	}
+	for i, rule := range c.RoutePolicy.Routes {
+		field := fmt.Sprintf("route_policy.routes[%d]", i)
+		if !strings.HasPrefix(rule.Path, "/") {
+			add(field+".path", "must be an absolute path")
+		}
+		if !isRouteAction(rule.Action) {
+			add(field+".action", "unknown action %q, one of local, proxy, local_report, deny", rule.Action)
+		}
+		if rule.Timeout < 0 {
+			add(field+".timeout", "must not be negative")
+		}
+	}
+	for i, l := range c.AccessControl.Lists {
+		if len(l.AllowAddrs)+len(l.DenyAddrs)+len(l.AllowOrgs)+len(l.DenyOrgs) == 0 {
+			add(fmt.Sprintf("access_control.lists[%d]", i), "has no entries")
+		}
+	}
+
+	if c.Session.Secret != "" && len(c.Session.Secret) < minSessionSecret {
+		add("session.secret", "must be at least %d bytes", minSessionSecret)
+	}
+
+	if len(c.WebAuthn.Origins) > 0 && c.WebAuthn.RPID == "" {
+		add("webauthn.rp_id", "is required when webauthn.origins is set")
+	}
+	for i, origin := range c.WebAuthn.Origins {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			add(fmt.Sprintf("webauthn.origins[%d]", i), "%q is not an http or https origin", origin)
+		}
+	}
+
+	if c.Idempotency.Store != "memory" && c.Idempotency.Store != "dir" {
+		add("idempotency.store", "unknown store %q, one of memory, dir", c.Idempotency.Store)
+	}
+	for i, route := range c.Idempotency.Routes {
+		if !strings.HasPrefix(route, "/") {
+			add(fmt.Sprintf("idempotency.routes[%d]", i), "must be an absolute path")
+		}
+	}
+
+	for field, value := range map[string]int{
+		"upstream.dial_timeout":      c.Upstream.DialTimeout,
+		"upstream.request_timeout":   c.Upstream.RequestTimeout,
+		"upstream.max_retries":       c.Upstream.MaxRetries,
+		"upstream.fail_threshold":    c.Upstream.FailThreshold,
+		"upstream.cooldown":          c.Upstream.Cooldown,
+		"report_outbox.max_attempts": c.ReportOutbox.MaxAttempts,
+		"audit.max_size":             c.Audit.MaxSize,
+		"log.max_size":               c.Log.MaxSize,
+		"log.max_age":                c.Log.MaxAge,
+		"session.ttl":                c.Session.TTL,
+		"idempotency.ttl":            c.Idempotency.TTL,
+		"events.heartbeat":           c.Events.Heartbeat,
+		"events.max_lifetime":        c.Events.MaxLifetime,
+		"events.max_per_addr":        c.Events.MaxPerAddr,
+	} {
+		if value < 0 {
+			add(field, "must not be negative")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	// map iteration above is random, keep the report stable
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+func checkPort(add func(field, format string, args ...any), field, port string) {
+	if port == "" {
+		add(field, "is required")
+		return
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		add(field, "%q is not a valid port", port)
+	}
+}
+
+func isRouteAction(action string) bool {
+	switch action {
+	case consts.RouteActionLocal, consts.RouteActionProxy, consts.RouteActionLocalReport, consts.RouteActionDeny:
+		return true
+	}
+	return false
+}
+
+// Redacted returns a copy of the config with every `secret:"true"` field masked.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	redact(reflect.ValueOf(&cp).Elem())
+	return &cp
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch {
+		case t.Field(i).Tag.Get(secretTagName) == secretTagEnable && field.Kind() == reflect.String:
+			if field.String() != "" {
+				field.SetString(redactedValue)
+			}
+		case field.Kind() == reflect.Struct:
+			redact(field)
+		}
+	}
+}
+
+// Dump renders the config as yaml with secrets redacted. Values that did not
+// come from the config file are commented with their source.
+func (c *Config) Dump() (string, error) {
+	doc := &yaml.Node{}
+	if err := doc.Encode(c.Redacted()); err != nil {
+		return "", err
+	}
+	for field, source := range c.sources {
+		if node := findNode(doc, strings.Split(field, ".")); node != nil {
+			node.LineComment = "from " + source
+		}
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// findNode returns the value node of a yaml path in a mapping node.
+func findNode(node *yaml.Node, path []string) *yaml.Node {
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// minimalYAML is the smallest valid config, the tests append to it.
+const minimalYAML = `running_mode: local
+official_domains: [http://127.0.0.1:1]
+node:
+  token: test
+http_server:
+  port: "8080"
+server:
+  port: "8081"
+`
+
+// writeConfig writes yaml to a config file of the test and returns its path.
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// invalidFields returns the fields reported by the validation error of err.
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error %v is not a validation error", err)
+	}
+	fields := make([]string, 0, len(verr))
+	for _, fe := range verr {
+		fields = append(fields, fe.Field)
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		fields []string
+	}{
+		{"minimal", minimalYAML, nil},
+		{"no running mode", strings.Replace(minimalYAML, "running_mode: local", "running_mode: \"\"", 1), []string{"running_mode"}},
+		{"unknown running mode", strings.Replace(minimalYAML, "running_mode: local", "running_mode: remote", 1), []string{"running_mode"}},
+		{"local without domains", strings.Replace(minimalYAML, "official_domains: [http://127.0.0.1:1]", "official_domains: []", 1), []string{"official_domains"}},
+		{"official without domains", strings.Replace(strings.Replace(minimalYAML, "official_domains: [http://127.0.0.1:1]", "official_domains: []", 1), "running_mode: local", "running_mode: official", 1), nil},
+		{"bad domain", strings.Replace(minimalYAML, "http://127.0.0.1:1", "ftp://x", 1), []string{"official_domains[0]"}},
+		{"no node token", strings.Replace(minimalYAML, "token: test", "token: \"\"", 1), []string{"node.token"}},
+		{"bad ports", strings.Replace(strings.Replace(minimalYAML, `"8080"`, `"0"`, 1), `"8081"`, `"x"`, 1), []string{"http_server.port", "server.port"}},
+		{"grpc tls without files", minimalYAML + "tls: {}\n", nil},
+		{"negative msg.file", minimalYAML + "msg:\n  file: -1\n", []string{"msg.api", "msg.file"}},
+		{"msg.api above msg.file", minimalYAML + "msg:\n  api: 10\n  file: 5\n", []string{"msg.api"}},
+		{"log", minimalYAML + "log:\n  level: trace\n  format: xml\n  redact:\n    address: clear\n", []string{"log.format", "log.level", "log.redact.address"}},
+		{"route policy", minimalYAML + "route_policy:\n  default: drop\n  routes:\n    - {path: web3password, action: drop, timeout: -1}\n",
+			[]string{"route_policy.default", "route_policy.routes[0].action", "route_policy.routes[0].path", "route_policy.routes[0].timeout"}},
+		{"short session secret", minimalYAML + "session:\n  secret: short\n", []string{"session.secret"}},
+		{"webauthn origins without rp_id", minimalYAML + "webauthn:\n  origins: [https://example.com/path]\n", []string{"webauthn.origins[0]", "webauthn.rp_id"}},
+		{"idempotency", minimalYAML + "idempotency:\n  store: redis\n  routes: [file/upload]\n", []string{"idempotency.routes[0]", "idempotency.store"}},
+		{"negative durations", minimalYAML + "upstream:\n  cooldown: -1\nevents:\n  max_per_addr: -1\n", []string{"events.max_per_addr", "upstream.cooldown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tt.yaml))
+			if got := invalidFields(t, err); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("invalid fields %v, want %v (%v)", got, tt.fields, err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryFieldInOrder(t *testing.T) {
+	yaml := `running_mode: remote
+official_domains: [x, y]
+node:
+  token: ""
+http_server:
+  port: "x"
+  enable_tls: true
+server:
+  port: "1"
+  enable_tls: true
+`
+	want := []string{
+		"http_server.crt", "http_server.key", "http_server.port", "node.token",
+		"official_domains[0]", "official_domains[1]", "running_mode",
+		"tls.ca", "tls.client.crt", "tls.client.key", "tls.server.crt", "tls.server.key",
+	}
+	path := writeConfig(t, yaml)
+	// the tls fields come from a map, the order must not depend on its iteration
+	for i := 0; i < 20; i++ {
+		_, err := LoadConfig(path)
+		if got := invalidFields(t, err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: invalid fields %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	c, err := LoadConfig(writeConfig(t, minimalYAML+"msg:\n  api: 0\n  file: 0\nofficial_domain: http://legacy\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.MsgSize.Api != defaultMsgApi || c.MsgSize.File != defaultMsgFile {
+		t.Errorf("msg sizes %d %d, want the defaults", c.MsgSize.Api, c.MsgSize.File)
+	}
+	for field, want := range map[string]string{
+		"msg.api":          SourceDefault,
+		"msg.file":         SourceDefault,
+		"server.proto":     SourceDefault,
+		"official_domains": SourceFile,
+		"node.token":       SourceFile,
+	} {
+		if got := c.Source(field); got != want {
+			t.Errorf("source of %s is %q, want %q", field, got, want)
+		}
+	}
+
+	// the legacy official_domain fills official_domains only when they are empty
+	c, err = LoadConfig(writeConfig(t, strings.Replace(minimalYAML, "official_domains: [http://127.0.0.1:1]", "official_domain: http://legacy", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.OfficialDomains, []string{"http://legacy"}) || c.Source("official_domains") != SourceDefault {
+		t.Errorf("official_domains %v from %s", c.OfficialDomains, c.Source("official_domains"))
+	}
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	if _, err := LoadConfig(writeConfig(t, minimalYAML+"unknown_key: 1\n")); err == nil {
+		t.Fatal("unknown key accepted")
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package config
+
+import (
+	"expvar"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce lets an editor finish writing before the file is parsed.
+const reloadDebounce = 300 * time.Millisecond
+
+// Subscriber is notified after a reload swapped in a new config, with the
+// yaml keys of the top level sections that changed.
+type Subscriber func(oldConf, newConf *Config, changed []string)
+
+// ReloadHook observes every reload attempt, err is set when the new file was
+// rejected. The log package reports them through its hook.
+type ReloadHook func(changed []string, err error)
+
+type subscriber struct {
+	name string
+	fn   Subscriber
+}
+
+var (
+	subscribersLock sync.Mutex
+	subscribers     []subscriber
+	reloadHooks     []ReloadHook
+
+	reloadSuccess   = expvar.NewInt("config_reload_success")
+	reloadFailure   = expvar.NewInt("config_reload_failure")
+	reloadTimestamp = expvar.NewInt("config_reload_last_timestamp")
+)
+
+// Subscribe registers fn to be called after every successful reload.
+func Subscribe(name string, fn Subscriber) {
+	subscribersLock.Lock()
+	defer subscribersLock.Unlock()
+	subscribers = append(subscribers, subscriber{name: name, fn: fn})
+}
+
+// OnReload registers a hook that is told the outcome of every reload.
+func OnReload(hook ReloadHook) {
+	subscribersLock.Lock()
+	defer subscribersLock.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Reload loads and validates the file and only then swaps it in. An invalid
+// or half-written file keeps the current config. Unchanged content is a no-op.
+func Reload(path string) ([]string, error) {
+	c, sum, err := loadConfigFile(path)
+	lock.Lock()
+	if err == nil && sum == configSum {
+		lock.Unlock()
+		return nil, nil
+	}
+	if err != nil {
+		lock.Unlock()
+		reloadFailure.Add(1)
+		notifyReload(nil, err)
+		return nil, err
+	}
+	old := config
+	config = c
+	configSum = sum
+	lock.Unlock()
+
+	changed := ChangedSections(old, c)
+	reloadSuccess.Add(1)
+	reloadTimestamp.Set(time.Now().Unix())
+	notifyReload(changed, nil)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	subscribersLock.Lock()
+	subs := append([]subscriber(nil), subscribers...)
+	subscribersLock.Unlock()
+	for _, sub := range subs {
+		callSubscriber(sub, old, c, changed)
+	}
+	return changed, nil
+}
+
+func callSubscriber(sub subscriber, oldConf, newConf *Config, changed []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			notifyReload(changed, fmt.Errorf("config subscriber %s panic: %v", sub.name, r))
+		}
+	}()
+	sub.fn(oldConf, newConf, changed)
+}
+
+func notifyReload(changed []string, err error) {
+	subscribersLock.Lock()
+	hooks := append([]ReloadHook(nil), reloadHooks...)
+	subscribersLock.Unlock()
+	for _, hook := range hooks {
+		hook(changed, err)
+	}
+}
+
+// ChangedSections returns the yaml keys of the top level sections that differ.
+func ChangedSections(oldConf, newConf *Config) []string {
+	var changed []string
+	if oldConf == nil || newConf == nil {
+		return changed
+	}
+	ov, nv := reflect.ValueOf(*oldConf), reflect.ValueOf(*newConf)
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			changed = append(changed, yamlKey(t.Field(i)))
+		}
+	}
+	return changed
+}
+
+func yamlKey(f reflect.StructField) string {
+	tag := f.Tag.Get("yaml")
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			tag = tag[:i]
+			break
+		}
+	}
+	if tag == "" {
+		return f.Name
+	}
+	return tag
+}
+
+// SectionChanged reports whether a section is in the changed list.
+func SectionChanged(changed []string, section string) bool {
+	for _, c := range changed {
+		if c == section {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchConfig reloads the config whenever its directory changes. Watching the
+// directory catches editors and orchestrators that rename a new file into
+// place, and the content hash skips events that did not change the file.
+func WatchConfig(path string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		notifyReload(nil, fmt.Errorf("config watcher: %w", err))
+		return
+	}
+	defer watcher.Close()
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		notifyReload(nil, fmt.Errorf("config watcher: %w", err))
+		return
+	}
+
+	timer := time.NewTimer(reloadDebounce)
+	timer.Stop()
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			timer.Reset(reloadDebounce)
+		case <-timer.C:
+			_, _ = Reload(path)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			notifyReload(nil, fmt.Errorf("config watcher: %w", err))
+		}
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	path := writeConfig(t, minimalYAML)
+	if err := ParseConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	type call struct {
+		oldLevel, newLevel string
+		changed            []string
+	}
+	var calls []call
+	var hookErrs []error
+	subscribed := true
+	Subscribe("test", func(oldConf, newConf *Config, changed []string) {
+		if subscribed {
+			calls = append(calls, call{oldConf.Log.Level, newConf.Log.Level, changed})
+		}
+	})
+	OnReload(func(changed []string, err error) {
+		if subscribed && err != nil {
+			hookErrs = append(hookErrs, err)
+		}
+	})
+	defer func() { subscribed = false }()
+	rewrite := func(yaml string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the same content is a no-op
+	before := GetConfig()
+	if changed, err := Reload(path); err != nil || changed != nil || GetConfig() != before || len(calls) != 0 {
+		t.Fatalf("reload of the same file: %v %v, %d calls", changed, err, len(calls))
+	}
+
+	rewrite(minimalYAML + "log:\n  level: debug\n")
+	changed, err := Reload(path)
+	if err != nil || !reflect.DeepEqual(changed, []string{"log"}) {
+		t.Fatalf("reload: %v %v", changed, err)
+	}
+	if GetConfig().Log.Level != "debug" {
+		t.Fatal("the new config was not swapped in")
+	}
+	if len(calls) != 1 || calls[0].oldLevel != "" || calls[0].newLevel != "debug" || !reflect.DeepEqual(calls[0].changed, []string{"log"}) {
+		t.Fatalf("subscriber calls %+v", calls)
+	}
+
+	// an invalid file keeps the current config and is reported to the hooks
+	current := GetConfig()
+	rewrite(strings.Replace(minimalYAML, "running_mode: local", "running_mode: remote", 1))
+	if _, err := Reload(path); err == nil {
+		t.Fatal("invalid config reloaded")
+	}
+	if GetConfig() != current || len(calls) != 1 || len(hookErrs) != 1 {
+		t.Fatalf("invalid reload swapped the config or notified: %d calls, %d hook errors", len(calls), len(hookErrs))
+	}
+
+	// a whitespace change has another hash but no changed section
+	rewrite(minimalYAML + "log:\n  level: debug\n\n")
+	if changed, err := Reload(path); err != nil || changed != nil || len(calls) != 1 {
+		t.Fatalf("reload without changes: %v %v, %d calls", changed, err, len(calls))
+	}
+}
+
+func TestReloadRecoversSubscriberPanic(t *testing.T) {
+	path := writeConfig(t, minimalYAML)
+	if err := ParseConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	active := true
+	var hookErrs []error
+	Subscribe("panic", func(*Config, *Config, []string) {
+		if active {
+			panic("boom")
+		}
+	})
+	OnReload(func(changed []string, err error) {
+		if active && err != nil {
+			hookErrs = append(hookErrs, err)
+		}
+	})
+	defer func() { active = false }()
+
+	if err := os.WriteFile(path, []byte(minimalYAML+"log:\n  level: warn\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reload(path); err != nil {
+		t.Fatal(err)
+	}
+	if GetConfig().Log.Level != "warn" {
+		t.Fatal("a panicking subscriber undid the reload")
+	}
+	if len(hookErrs) != 1 || !strings.Contains(hookErrs[0].Error(), "config subscriber panic panic: boom") {
+		t.Fatalf("hook errors %v", hookErrs)
+	}
+}
+
+func TestChangedSections(t *testing.T) {
+	a, err := LoadConfig(writeConfig(t, minimalYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadConfig(writeConfig(t, minimalYAML+"events:\n  heartbeat: 5\nsession:\n  ttl: 60\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ChangedSections(a, b); !reflect.DeepEqual(got, []string{"session", "events"}) {
+		t.Errorf("changed %v", got)
+	}
+	if !SectionChanged([]string{"log", "events"}, "events") || SectionChanged([]string{"log"}, "log_dir") {
+		t.Error("SectionChanged")
+	}
+}
@@ -15,6 +15,13 @@ const (
 	RunningModeLocal    = "local"
 )
 
+const (
+	RouteActionLocal       = "local"        // served by this node
+	RouteActionProxy       = "proxy"        // forwarded to the official domain
+	RouteActionLocalReport = "local_report" // served by this node and reported to the official domain
+	RouteActionDeny        = "deny"         // rejected
+)
+
 const (
 	PLATFORM_PAYPAL = "1"
 	PLATFORM_STRIPE = "2"
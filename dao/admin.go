@@ -204,6 +204,7 @@ func (d *dao) AdminOperationHistory(ctx context.Context, req *pb.AdminOperationH
 			log.Logger.Error("AdminOperationHistory json unmarshal error", log.String("trace_id", traceId), log.String("errmsg", err.Error()), log.Any("response", res.GetParams()), log.String("params", req.GetParams()))
 			return rsp, err
 		}
+		setNextCursor(ctx, res.GetParams())
 		return rsp, nil
 	case <-timer.C:
 		rsp.Code = model.StatusSystemError
@@ -491,6 +492,7 @@ func (d *dao) AdminGetMemberList(ctx context.Context, req *pb.AdminGetMemberList
 			log.Logger.Error("AdminGetMemberList response json unmarshal error", log.String("trace_id", traceId), log.String("errmsg", err.Error()))
 			return rsp, err
 		}
+		setNextCursor(ctx, res.GetParams())
 	case <-timer.C:
 		rsp.Code = model.StatusSystemError
 		rsp.Msg = model.MsgTimeoutErr
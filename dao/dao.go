@@ -21,6 +21,7 @@ import (
 	"github.com/web3password/satis/config"
 	"github.com/web3password/satis/log"
 	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/notify"
 	pb "github.com/web3password/w3p-protobuf/user"
 )
 
@@ -28,6 +29,8 @@ type DAO interface {
 	GenerateID() int64
 
 	Stream(server pb.User_StreamServer) error
+	Nodes(group string) []string
+	QueueDepth(nodeID string) int
 
 	RegisterUser(ctx context.Context, signature, params string) (*pb.RegisterRsp, error)
 	Initialize(ctx context.Context, signature, params string) error
@@ -51,6 +54,12 @@ type DAO interface {
 	GetAllCredentialTimestamp(ctx context.Context, req *pb.GetAllCredentialTimestampReq) (model.GetAllCredentialTimestampListRsp, error)
 	//GetPrimaryAddrIndexList(ctx context.Context, req *pb.GetCredentialListReq) ([]*model.GetCredentialRsp, error)
 	GetPrimaryAddrIndexList(ctx context.Context, req *pb.GetCredentialListReq) (model.GetCredentialListRsp, error)
+	SyncCredentials(ctx context.Context, signature, params string) (model.SyncCredentialsRsp, error)
+	VaultExport(ctx context.Context, cmd, group, signature, params, cursor string) (model.VaultExportRsp, error)
+	VaultImport(ctx context.Context, cmd, signature, params string, data []byte) (model.VaultImportBatchRsp, error)
+	Transaction(ctx context.Context, signature, params string, commands []byte) (model.TransactionRsp, error)
+	CheckOrgMember(ctx context.Context, signature, params string) (model.Response, error)
+	Subscribe(addr, orgId string, maxPerAddr int) (*notify.Subscription, error)
 
 	AdminRegister(ctx context.Context, req *pb.AdminRegisterReq) (*pb.AdminRegisterRsp, error)
 	AdminAddMember(ctx context.Context, req *pb.AdminAddMemberReq) (model.AdminRsp, error)
@@ -105,6 +114,7 @@ type dao struct {
 	requestChan  map[string]chan *pb.StreamRsp
 	clients      map[string]map[string]string //sign、storage、index
 	lock         sync.RWMutex
+	hub          *notify.Hub
 }
 
 func NewDAO(conf *config.Config) DAO {
@@ -122,6 +132,7 @@ func NewDAO(conf *config.Config) DAO {
 		responseWait: sync.Map{},
 		requestChan:  make(map[string]chan *pb.StreamRsp),
 		clients:      make(map[string]map[string]string),
+		hub:          notify.NewHub(),
 	}
 	go d.heartBeat()
 	return d
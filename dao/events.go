@@ -0,0 +1,97 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package dao
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/notify"
+	"github.com/web3password/satis/util"
+	pb "github.com/web3password/w3p-protobuf/user"
+)
+
+// Subscribe registers a subscriber of the notifications the nodes publish.
+func (d *dao) Subscribe(addr, orgId string, maxPerAddr int) (*notify.Subscription, error) {
+	return d.hub.Subscribe(addr, orgId, maxPerAddr)
+}
+
+// publish fans out a CMDNotify of a node, nodes get no answer to it. The
+// notification must carry node.token, like the requests satis sends.
+func (d *dao) publish(res *pb.StreamReq, nodeID string) {
+	traceId := res.GetTraceId()
+	if subtle.ConstantTimeCompare([]byte(res.GetToken()), []byte(d.conf.Node.Token)) != 1 {
+		log.Logger.Warn("notify invalid token", log.String("node", nodeID), log.String("trace_id", traceId))
+		return
+	}
+	n := &model.Notification{}
+	if err := jsoniter.UnmarshalFromString(res.GetParams(), n); err != nil || !n.Valid() {
+		log.Logger.Warn("notify invalid notification", log.String("node", nodeID), log.String("trace_id", traceId), log.String("params", res.GetParams()))
+		return
+	}
+	count := d.hub.Publish(n)
+	log.Logger.Debug("notify published", log.String("node", nodeID), log.String("trace_id", traceId), log.String("type", n.Type), log.Any("subscribers", count))
+}
+
+// CheckOrgMember asks the ares node whether the signer of params is a member
+// of their org_id.
+func (d *dao) CheckOrgMember(ctx context.Context, signature, params string) (model.Response, error) {
+	requestID := d.GenerateID()
+	traceId := util.GetTraceid(ctx)
+	rsp := model.Response{}
+
+	log.Logger.Info("CheckOrgMember start", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.String("params", params))
+
+	waitChan := d.addStreamResponseWaitChan(requestID)
+	defer d.delStreamResponseWaitChan(requestID)
+	if err := d.addStreamRequest(&pb.StreamRsp{
+		Cmd:       model.CMDCheckOrgMember,
+		Token:     d.conf.Node.Token,
+		RequestId: requestID,
+		Signature: signature,
+		Params:    params,
+		TraceId:   traceId,
+	}, model.ARES_PROXY); err != nil {
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgSystemErr
+		log.Logger.Error("CheckOrgMember add proxy request error", log.String("trace_id", traceId), log.String("errmsg", err.Error()), log.String("params", params))
+		return rsp, err
+	}
+
+	timer := time.NewTimer(model.W3PTimeoutMin * time.Second)
+	select {
+	case res := <-waitChan:
+		timer.Stop()
+		if err := jsoniter.UnmarshalFromString(res.GetParams(), &rsp); err != nil {
+			rsp.Code = model.StatusSystemError
+			rsp.Msg = model.MsgParamsErr
+			log.Logger.Error("CheckOrgMember response json unmarshal error", log.String("trace_id", traceId), log.Any("response", res.GetParams()), log.String("params", params))
+			return rsp, err
+		}
+		if rsp.Code > model.StatusSystemErrorCode {
+			log.Logger.Error("CheckOrgMember response rsp error", log.String("trace_id", traceId), log.Any("rsp", rsp))
+			return rsp, errors.New(rsp.Msg)
+		}
+	case <-timer.C:
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgTimeoutErr
+		log.Logger.Error("CheckOrgMember timeout", log.String("trace_id", traceId), log.String("params", params))
+		return rsp, fmt.Errorf("CheckOrgMember timeout")
+	}
+
+	log.Logger.Info("CheckOrgMember end", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.Any("code", rsp.Code))
+	return rsp, nil
+}
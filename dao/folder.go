@@ -411,6 +411,7 @@ func (d *dao) ShareFolderRecordList(ctx context.Context, req *pb.ShareFolderReco
 			log.Logger.Error("ShareFolderRecordList response json unmarshal error", log.String("trace_id", traceId), log.String("errmsg", err.Error()), log.Any("response", res.GetParams()), log.String("params", req.GetParams()))
 			return rsp, err
 		}
+		setNextCursor(ctx, res.GetParams())
 	case <-timer.C:
 		rsp.Code = model.StatusSystemError
 		rsp.Msg = model.MsgTimeoutErr
@@ -654,6 +655,7 @@ func (d *dao) ShareFolderMemberList(ctx context.Context, req *pb.ShareFolderMemb
 			log.Logger.Error("ShareFolderMemberList response json unmarshal error", log.String("trace_id", traceId), log.String("errmsg", err.Error()), log.Any("response", res.GetParams()), log.String("params", req.GetParams()))
 			return rsp, err
 		}
+		setNextCursor(ctx, res.GetParams())
 	case <-timer.C:
 		rsp.Code = model.StatusSystemError
 		rsp.Msg = model.MsgTimeoutErr
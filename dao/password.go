@@ -636,6 +636,7 @@ func (d *dao) GetPrimaryAddrIndexList(ctx context.Context, req *pb.GetCredential
 			}
 			rsp.List = append(rsp.List, tmpData)
 		}
+		setNextCursor(ctx, res.GetParams())
 		log.Logger.Info("GetPrimaryAddrIndexList response success", log.String("trace_id", traceId), log.Int64("requestID", requestID))
 	case <-timer.C:
 		rsp.Code = model.StatusSystemError
@@ -648,3 +649,71 @@ func (d *dao) GetPrimaryAddrIndexList(ctx context.Context, req *pb.GetCredential
 
 	return rsp, nil
 }
+
+// SyncCredentials asks the index for the credential changes after the cursor of params.
+func (d *dao) SyncCredentials(ctx context.Context, signature, params string) (model.SyncCredentialsRsp, error) {
+	requestID := d.GenerateID()
+	traceId := util.GetTraceid(ctx)
+	rsp := model.SyncCredentialsRsp{}
+	rsp.Data.Changes = make([]*model.CredentialChange, 0)
+
+	log.Logger.Info("SyncCredentials start", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.String("params", params))
+
+	waitChan := d.addStreamResponseWaitChan(requestID)
+	defer d.delStreamResponseWaitChan(requestID)
+	if err := d.addStreamRequest(&pb.StreamRsp{
+		Cmd:       model.CMDIndexSyncCredentials,
+		Token:     d.conf.Node.Token,
+		RequestId: requestID,
+		Signature: signature,
+		Params:    params,
+		TraceId:   traceId,
+	}, model.INDEX_PROXY); err != nil {
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgSystemErr
+		log.Logger.Error("SyncCredentials add proxy request error", log.String("trace_id", traceId), log.String("errmsg", err.Error()), log.String("params", params))
+		return rsp, err
+	}
+
+	timer := time.NewTimer(model.W3PTimeoutMax * time.Second)
+	select {
+	case res := <-waitChan:
+		timer.Stop()
+
+		var ret model.SyncCredentialsRsp
+		if err := jsoniter.UnmarshalFromString(res.GetParams(), &ret); err != nil {
+			rsp.Code = model.StatusSystemError
+			rsp.Msg = model.MsgParamsErr
+			log.Logger.Error("SyncCredentials response json unmarshal error", log.String("trace_id", traceId), log.Any("response", res.GetParams()), log.String("params", params))
+			return rsp, err
+		}
+
+		rsp.Code = ret.Code
+		rsp.Msg = ret.Msg
+		if ret.Code > model.StatusSystemErrorCode {
+			log.Logger.Error("SyncCredentials response rsp error", log.String("trace_id", traceId), log.Any("rsp", rsp))
+			return rsp, errors.New(ret.Msg)
+		}
+
+		if ret.Code != model.StatusOK {
+			log.Logger.Warn("SyncCredentials response not good", log.String("trace_id", traceId), log.Any("rsp", rsp))
+			return rsp, nil
+		}
+
+		if ret.Data.Changes != nil {
+			rsp.Data.Changes = ret.Data.Changes
+		}
+		rsp.Data.Cursor = ret.Data.Cursor
+		rsp.Data.HasMore = ret.Data.HasMore
+		log.Logger.Info("SyncCredentials response success", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.Any("count", len(rsp.Data.Changes)))
+	case <-timer.C:
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgTimeoutErr
+		log.Logger.Error("SyncCredentials response timeout", log.String("trace_id", traceId), log.String("params", params))
+		return rsp, fmt.Errorf("SyncCredentials timeout")
+	}
+
+	log.Logger.Info("SyncCredentials end", log.String("trace_id", traceId), log.Int64("requestID", requestID))
+
+	return rsp, nil
+}
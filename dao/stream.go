@@ -10,15 +10,20 @@ For more information, please refer to https://www.web3password.com/web3password_
 package dao
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/web3password/satis/log"
 	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/util"
 	pb "github.com/web3password/w3p-protobuf/user"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -63,6 +68,25 @@ func (d *dao) UpdateGroup(group, clientID, connKey string) {
 	return
 }
 
+// Nodes returns the ids of the nodes connected in a group.
+func (d *dao) Nodes(group string) []string {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	nodes := make([]string, 0, len(d.clients[group]))
+	for nodeID := range d.clients[group] {
+		nodes = append(nodes, nodeID)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// QueueDepth returns the number of requests waiting to be sent to a node.
+func (d *dao) QueueDepth(nodeID string) int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return len(d.requestChan[nodeID])
+}
+
 func (d *dao) Stream(server pb.User_StreamServer) error {
 	md, ok := metadata.FromIncomingContext(server.Context())
 	nodeID := "default_node"
@@ -151,6 +175,10 @@ func (d *dao) Stream(server pb.User_StreamServer) error {
 				log.Logger.Error("server RecvMsg invalid token", log.Error(err), log.String("node", client_id))
 				break
 			}*/
+			if res.GetCmd() == model.CMDNotify {
+				d.publish(res, nodeID)
+				continue
+			}
 			requestID := res.GetRequestId()
 			log.Logger.Debug("server recv msg success for requestid", log.Any("connkey", nodeConn), log.String("nodeID", nodeID), log.String("recvId", recvId), log.String("trace_id", traceId), log.Int64("requestID", requestID))
 
@@ -269,3 +297,15 @@ func (d *dao) loadStreamResponseWaitChan(requestID int64) (chan *pb.StreamReq, e
 func (d *dao) delStreamResponseWaitChan(requestID int64) {
 	d.responseWait.Delete(requestID)
 }
+
+// setNextCursor passes the next_cursor of a list response of a node to the
+// grpc caller as a response header, the pb list responses have no field for it.
+func setNextCursor(ctx context.Context, params string) {
+	cursor := jsoniter.Get([]byte(params), model.NextCursorKey).ToString()
+	if cursor == "" {
+		return
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(model.NextCursorKey, cursor)); err != nil {
+		log.Logger.Warn("setNextCursor fail", log.String("trace_id", util.GetTraceid(ctx)), log.String("errmsg", err.Error()))
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/util"
+	pb "github.com/web3password/w3p-protobuf/user"
+)
+
+// Transaction sends the checked ops to the index as one CMDIndexTransaction,
+// applied all or none.
+func (d *dao) Transaction(ctx context.Context, signature, params string, commands []byte) (model.TransactionRsp, error) {
+	requestID := d.GenerateID()
+	traceId := util.GetTraceid(ctx)
+	rsp := model.TransactionRsp{}
+	rsp.Data.FailedOp = -1
+	rsp.Data.Results = make([]*model.TxResult, 0)
+
+	log.Logger.Info("Transaction start", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.Any("size", len(commands)))
+	log.Logger.Debug("Transaction params", log.String("trace_id", traceId), log.String("params", params))
+
+	waitChan := d.addStreamResponseWaitChan(requestID)
+	defer d.delStreamResponseWaitChan(requestID)
+	if err := d.addStreamRequest(&pb.StreamRsp{
+		Cmd:       model.CMDIndexTransaction,
+		Token:     d.conf.Node.Token,
+		RequestId: requestID,
+		Signature: signature,
+		Params:    params,
+		Data:      commands,
+		TraceId:   traceId,
+	}, model.INDEX_PROXY); err != nil {
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgSystemErr
+		log.Logger.Error("Transaction add proxy request error", log.String("trace_id", traceId), log.String("errmsg", err.Error()))
+		return rsp, err
+	}
+
+	timer := time.NewTimer(model.W3PTimeoutMax * time.Second)
+	select {
+	case res := <-waitChan:
+		timer.Stop()
+
+		var ret model.TransactionRsp
+		if err := jsoniter.UnmarshalFromString(res.GetParams(), &ret); err != nil {
+			rsp.Code = model.StatusSystemError
+			rsp.Msg = model.MsgParamsErr
+			log.Logger.Error("Transaction response json unmarshal error", log.String("trace_id", traceId), log.Any("response", res.GetParams()))
+			return rsp, err
+		}
+
+		rsp.Code = ret.Code
+		rsp.Msg = ret.Msg
+		if ret.Code > model.StatusSystemErrorCode {
+			log.Logger.Error("Transaction response rsp error", log.String("trace_id", traceId), log.Any("rsp", rsp))
+			return rsp, errors.New(ret.Msg)
+		}
+
+		if ret.Code == model.StatusOK && !ret.Data.Committed {
+			rsp.Code = model.StatusSystemError
+			rsp.Msg = model.MsgSystemErr
+		}
+		if rsp.Code != model.StatusOK {
+			rsp.Data.FailedOp = ret.Data.FailedOp
+			log.Logger.Warn("Transaction response not committed", log.String("trace_id", traceId), log.Any("rsp", rsp))
+			return rsp, nil
+		}
+
+		rsp.Data.Committed = true
+		if ret.Data.Results != nil {
+			rsp.Data.Results = ret.Data.Results
+		}
+		log.Logger.Info("Transaction response committed", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.Any("count", len(rsp.Data.Results)))
+	case <-timer.C:
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgTimeoutErr
+		log.Logger.Error("Transaction response timeout", log.String("trace_id", traceId), log.Int64("requestID", requestID))
+		return rsp, fmt.Errorf("Transaction timeout")
+	}
+
+	log.Logger.Info("Transaction end", log.String("trace_id", traceId), log.Int64("requestID", requestID))
+	return rsp, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/util"
+	pb "github.com/web3password/w3p-protobuf/user"
+)
+
+// VaultExport asks a page of a vault export command of group, the one after
+// cursor. Every page carries the signed export params, the cursor goes in data.
+func (d *dao) VaultExport(ctx context.Context, cmd, group, signature, params, cursor string) (model.VaultExportRsp, error) {
+	requestID := d.GenerateID()
+	traceId := util.GetTraceid(ctx)
+	rsp := model.VaultExportRsp{}
+	rsp.Data.Items = make([]*model.VaultItem, 0)
+
+	log.Logger.Info("VaultExport start", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.String("cmd", cmd), log.String("cursor", cursor))
+
+	waitChan := d.addStreamResponseWaitChan(requestID)
+	defer d.delStreamResponseWaitChan(requestID)
+	if err := d.addStreamRequest(&pb.StreamRsp{
+		Cmd:       cmd,
+		Token:     d.conf.Node.Token,
+		RequestId: requestID,
+		Signature: signature,
+		Params:    params,
+		Data:      []byte(cursor),
+		TraceId:   traceId,
+	}, group); err != nil {
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgSystemErr
+		log.Logger.Error("VaultExport add proxy request error", log.String("trace_id", traceId), log.String("errmsg", err.Error()), log.String("cmd", cmd))
+		return rsp, err
+	}
+
+	timer := time.NewTimer(model.W3PTimeoutMax * time.Second)
+	select {
+	case res := <-waitChan:
+		timer.Stop()
+
+		var ret model.VaultExportRsp
+		if err := jsoniter.UnmarshalFromString(res.GetParams(), &ret); err != nil {
+			rsp.Code = model.StatusSystemError
+			rsp.Msg = model.MsgParamsErr
+			log.Logger.Error("VaultExport response json unmarshal error", log.String("trace_id", traceId), log.String("cmd", cmd), log.Any("response", res.GetParams()))
+			return rsp, err
+		}
+
+		rsp.Code = ret.Code
+		rsp.Msg = ret.Msg
+		if ret.Code > model.StatusSystemErrorCode {
+			log.Logger.Error("VaultExport response rsp error", log.String("trace_id", traceId), log.String("cmd", cmd), log.Any("rsp", rsp))
+			return rsp, errors.New(ret.Msg)
+		}
+
+		if ret.Code != model.StatusOK {
+			log.Logger.Warn("VaultExport response not good", log.String("trace_id", traceId), log.String("cmd", cmd), log.Any("rsp", rsp))
+			return rsp, nil
+		}
+
+		if ret.Data.Items != nil {
+			rsp.Data.Items = ret.Data.Items
+		}
+		rsp.Data.NextCursor = ret.Data.NextCursor
+	case <-timer.C:
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgTimeoutErr
+		log.Logger.Error("VaultExport response timeout", log.String("trace_id", traceId), log.String("cmd", cmd))
+		return rsp, fmt.Errorf("VaultExport %s timeout", cmd)
+	}
+
+	log.Logger.Info("VaultExport end", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.String("cmd", cmd), log.Any("count", len(rsp.Data.Items)))
+	return rsp, nil
+}
+
+// VaultImport sends a batch of an import to the index with cmd. Every batch
+// carries the signed import params, data is the BSON of a model.VaultImportBatch.
+func (d *dao) VaultImport(ctx context.Context, cmd, signature, params string, data []byte) (model.VaultImportBatchRsp, error) {
+	requestID := d.GenerateID()
+	traceId := util.GetTraceid(ctx)
+	rsp := model.VaultImportBatchRsp{}
+
+	log.Logger.Info("VaultImport start", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.String("cmd", cmd))
+
+	waitChan := d.addStreamResponseWaitChan(requestID)
+	defer d.delStreamResponseWaitChan(requestID)
+	if err := d.addStreamRequest(&pb.StreamRsp{
+		Cmd:       cmd,
+		Token:     d.conf.Node.Token,
+		RequestId: requestID,
+		Signature: signature,
+		Params:    params,
+		Data:      data,
+		TraceId:   traceId,
+	}, model.INDEX_PROXY); err != nil {
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgSystemErr
+		log.Logger.Error("VaultImport add proxy request error", log.String("trace_id", traceId), log.String("errmsg", err.Error()), log.String("cmd", cmd))
+		return rsp, err
+	}
+
+	timer := time.NewTimer(model.W3PTimeoutMax * time.Second)
+	select {
+	case res := <-waitChan:
+		timer.Stop()
+
+		var ret model.VaultImportBatchRsp
+		if err := jsoniter.UnmarshalFromString(res.GetParams(), &ret); err != nil {
+			rsp.Code = model.StatusSystemError
+			rsp.Msg = model.MsgParamsErr
+			log.Logger.Error("VaultImport response json unmarshal error", log.String("trace_id", traceId), log.String("cmd", cmd), log.Any("response", res.GetParams()))
+			return rsp, err
+		}
+
+		rsp = ret
+		if ret.Code > model.StatusSystemErrorCode {
+			log.Logger.Error("VaultImport response rsp error", log.String("trace_id", traceId), log.String("cmd", cmd), log.Any("rsp", rsp))
+			return rsp, errors.New(ret.Msg)
+		}
+
+		if ret.Code != model.StatusOK {
+			log.Logger.Warn("VaultImport response not good", log.String("trace_id", traceId), log.String("cmd", cmd), log.Any("rsp", rsp))
+			return rsp, nil
+		}
+	case <-timer.C:
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgTimeoutErr
+		log.Logger.Error("VaultImport response timeout", log.String("trace_id", traceId), log.String("cmd", cmd))
+		return rsp, fmt.Errorf("VaultImport %s timeout", cmd)
+	}
+
+	log.Logger.Info("VaultImport end", log.String("trace_id", traceId), log.Int64("requestID", requestID), log.String("cmd", cmd), log.Int64("failed", rsp.Data.Failed))
+	return rsp, nil
+}
@@ -134,7 +134,7 @@ while true; do
 #        set log dir
         log=$(echo -n "/logs/")
         logDir=$dictionary$log
-        sed -i '/^log:/!b;c\log:\ \'"${logDir}"  $web3_satis_conf_file
+        sed -i '/^log_dir:/!b;c\log_dir:\ \'"${logDir}"  $web3_satis_conf_file
         sed -i '/^log_dir:/!b;c\log_dir:\ \'"${logDir}"  $web3_ares_conf_file
         sed -i '/^log_dir:/!b;c\log_dir:\ \'"${logDir}"  $web3_storage_conf_file
         sed -i '/^  file_path:/!b;c\  file_path:\ \'"${logDir}"  $web3_index_conf_file
@@ -0,0 +1,159 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package idempotency
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/log"
+)
+
+const dirSweepInterval = time.Minute
+
+// DirStore keeps a file per key in a directory, which the instances of a
+// deployment may share. A key is reserved by hard linking a complete file to
+// its name, which fails when another instance holds it. Replacing an expired
+// record is not atomic, two instances may both take the key at its expiry.
+type DirStore struct {
+	dir string
+
+	lock      sync.Mutex
+	lastSweep time.Time
+}
+
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (d *DirStore) Reserve(key string, rec *Record) (*Record, error) {
+	d.sweep()
+	tmp, err := d.writeTemp(rec)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+	path := d.path(key)
+	for attempt := 0; attempt < 2; attempt++ {
+		err = os.Link(tmp, path)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		held, err := d.read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !held.Expired(time.Now()) {
+			return held, nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	// the key changed hands under us twice, report it as held
+	return &Record{State: StatePending, ExpireAt: time.Now().Add(PendingTTL).Unix()}, nil
+}
+
+func (d *DirStore) Save(key string, rec *Record) error {
+	tmp, err := d.writeTemp(rec)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, d.path(key)); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (d *DirStore) Release(key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// sweep removes the expired records, at most once a dirSweepInterval.
+func (d *DirStore) sweep() {
+	now := time.Now()
+	d.lock.Lock()
+	if now.Sub(d.lastSweep) < dirSweepInterval {
+		d.lock.Unlock()
+		return
+	}
+	d.lastSweep = now
+	d.lock.Unlock()
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		log.Logger.Error("idempotency dir store read dir error", log.Error(err))
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(d.dir, entry.Name())
+		if rec, err := d.read(path); err == nil && rec.Expired(now) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+func (d *DirStore) path(key string) string {
+	return filepath.Join(d.dir, key+".json")
+}
+
+func (d *DirStore) writeTemp(rec *Record) (string, error) {
+	data, err := jsoniter.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func (d *DirStore) read(path string) (*Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rec := &Record{}
+	if err := jsoniter.Unmarshal(data, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package idempotency keeps the first response of a request sent with an
+// Idempotency-Key, so its retries get that response instead of running again.
+// The records live in a Store: in memory, in a directory the instances may
+// share, or in any store registered by name.
+package idempotency
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+)
+
+const (
+	StatePending = "pending"
+	StateDone    = "done"
+)
+
+// PendingTTL bounds how long a key stays held by a request that never ended,
+// such as one of a process that crashed.
+const PendingTTL = 5 * time.Minute
+
+var ErrNotFound = errors.New("idempotency record not found")
+
+// Record is what a key holds: the fingerprint of the signed payload of its
+// first request and, once done, the response to replay.
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	State       string `json:"state"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+	ExpireAt    int64  `json:"expire_at"`
+}
+
+// Expired reports whether the record is past its ExpireAt at now.
+func (r *Record) Expired(now time.Time) bool {
+	return now.Unix() >= r.ExpireAt
+}
+
+// Store keeps the records by key. Expired records are treated as absent.
+type Store interface {
+	// Reserve stores rec under key when the key is free and returns nil,
+	// otherwise it returns the record holding the key.
+	Reserve(key string, rec *Record) (*Record, error)
+	// Save replaces the record of a reserved key.
+	Save(key string, rec *Record) error
+	// Release frees a key.
+	Release(key string) error
+}
+
+// Opener opens a store from the config.
+type Opener func(conf *config.Config) (Store, error)
+
+var (
+	openersLock sync.RWMutex
+	openers     = make(map[string]Opener)
+)
+
+// Register makes a store available as idempotency.store, registering a name
+// twice is a programming error.
+func Register(name string, open Opener) {
+	openersLock.Lock()
+	defer openersLock.Unlock()
+	if _, ok := openers[name]; ok {
+		panic(fmt.Sprintf("idempotency store %s registered twice", name))
+	}
+	openers[name] = open
+}
+
+// Names returns the registered stores, sorted.
+func Names() []string {
+	openersLock.RLock()
+	defer openersLock.RUnlock()
+	names := make([]string, 0, len(openers))
+	for name := range openers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("memory", func(*config.Config) (Store, error) {
+		return NewMemoryStore(), nil
+	})
+	Register("dir", func(conf *config.Config) (Store, error) {
+		dir := conf.Idempotency.Dir
+		if dir == "" {
+			dir = filepath.Join(conf.LogDir, "idempotency")
+		}
+		return NewDirStore(dir)
+	})
+}
+
+var (
+	storeLock sync.RWMutex
+	store     Store
+)
+
+// Init opens the configured store.
+func Init(conf *config.Config) {
+	openersLock.RLock()
+	open, ok := openers[conf.Idempotency.Store]
+	openersLock.RUnlock()
+	if !ok {
+		log.Fatalf("unknown idempotency store:%s", conf.Idempotency.Store)
+	}
+	s, err := open(conf)
+	if err != nil {
+		log.Fatalf("failed to open idempotency store:%s err:%+v", conf.Idempotency.Store, err)
+	}
+	SetStore(s)
+	log.Logger.Info("idempotency store opened", log.String("store", conf.Idempotency.Store))
+}
+
+// SetStore replaces the store, nil disables the keys.
+func SetStore(s Store) {
+	storeLock.Lock()
+	store = s
+	storeLock.Unlock()
+}
+
+// GetStore returns the store, nil when it is not opened.
+func GetStore() Store {
+	storeLock.RLock()
+	defer storeLock.RUnlock()
+	return store
+}
+
+// MemoryStore keeps the records of this process only.
+type MemoryStore struct {
+	lock      sync.Mutex
+	records   map[string]*Record
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+func (m *MemoryStore) Reserve(key string, rec *Record) (*Record, error) {
+	now := time.Now()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if now.Sub(m.lastSweep) > time.Minute {
+		for k, r := range m.records {
+			if r.Expired(now) {
+				delete(m.records, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	if held, ok := m.records[key]; ok && !held.Expired(now) {
+		copied := *held
+		return &copied, nil
+	}
+	copied := *rec
+	m.records[key] = &copied
+	return nil, nil
+}
+
+func (m *MemoryStore) Save(key string, rec *Record) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.records[key]; !ok {
+		return ErrNotFound
+	}
+	copied := *rec
+	m.records[key] = &copied
+	return nil
+}
+
+func (m *MemoryStore) Release(key string) error {
+	m.lock.Lock()
+	delete(m.records, key)
+	m.lock.Unlock()
+	return nil
+}
@@ -11,60 +11,75 @@ package log
 
 import (
 	"context"
-	"github.com/sirupsen/logrus"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
 )
 
-// log config
-type logConfig struct {
-	log struct {
-		level int `yaml:"level"`
-	} `yaml:"log"`
+const traceIDKey = "trace_id"
+
+// TraceID returns the trace id of a gin request context or of the incoming grpc metadata.
+func TraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(traceIDKey).(string); ok && id != "" {
+		return id
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(traceIDKey); len(ids) > 0 {
+			return ids[0]
+		}
+	}
+	return ""
+}
+
+// Ctx returns the logger with the trace_id of ctx attached.
+func Ctx(ctx context.Context) *zap.Logger {
+	if id := TraceID(ctx); id != "" {
+		return Logger.With(zap.String(traceIDKey, id))
+	}
+	return Logger
 }
 
-var log = logrus.New()
-
-func init() {
-	//log.SetReportCaller(true)
-	log.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat:   "2006-01-02 15:04:05",
-		DisableTimestamp:  false,
-		DisableHTMLEscape: false,
-		DataKey:           "",
-		FieldMap:          nil,
-		CallerPrettyfier:  nil,
-		PrettyPrint:       false,
-	})
-	log.SetLevel(logrus.DebugLevel)
+// sugar skips the helper frame so the caller of Infof and friends is reported.
+func sugar(logger *zap.Logger) *zap.SugaredLogger {
+	return logger.WithOptions(zap.AddCallerSkip(1)).Sugar()
 }
 
 func InfoContextf(ctx context.Context, format string, args ...any) {
-	log.WithContext(ctx).Infof(format, args...)
+	sugar(Ctx(ctx)).Infof(format, args...)
 }
 
 func DebugContextf(ctx context.Context, format string, args ...any) {
-	log.WithContext(ctx).Debugf(format, args...)
+	sugar(Ctx(ctx)).Debugf(format, args...)
 }
 
-func ErrorContextf(ctx context.Context, format string, args ...any) {
-	log.WithContext(ctx).Errorf(format, args...)
+func WarnContextf(ctx context.Context, format string, args ...any) {
+	sugar(Ctx(ctx)).Warnf(format, args...)
 }
 
-func TraceContextf(ctx context.Context, format string, args ...any) {
-	log.WithContext(ctx).Tracef(format, args...)
+func ErrorContextf(ctx context.Context, format string, args ...any) {
+	sugar(Ctx(ctx)).Errorf(format, args...)
 }
 
 func Infof(format string, args ...any) {
-	log.Infof(format, args...)
+	sugar(Logger).Infof(format, args...)
+}
+
+func Warnf(format string, args ...any) {
+	sugar(Logger).Warnf(format, args...)
 }
 
 func Errorf(format string, args ...any) {
-	log.Errorf(format, args...)
+	sugar(Logger).Errorf(format, args...)
 }
 
 func Debugf(format string, args ...any) {
-	log.Debugf(format, args...)
+	sugar(Logger).Debugf(format, args...)
 }
 
+// Fatalf logs the message and exits the process.
 func Fatalf(format string, args ...any) {
-	log.Fatalf(format, args...)
+	sugar(Logger).Fatalf(format, args...)
 }
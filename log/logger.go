@@ -10,42 +10,151 @@ For more information, please refer to https://www.web3password.com/web3password_
 package log
 
 import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/web3password/satis/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"io"
-	"os"
-	"time"
 )
 
-var Logger *zap.Logger
+const (
+	FormatJson    = "json"
+	FormatConsole = "console"
 
+	defaultMaxAge = 7 // days
+)
+
+var (
+	// Logger writes to stdout until SetLogger applies the config.
+	Logger = zap.New(zapcore.NewCore(newEncoder(FormatJson), zapcore.AddSync(os.Stdout), level), zap.AddCaller())
+
+	level  = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	output = &swapWriter{}
+)
+
+// init reports the config reloads, from before SetLogger on.
+func init() {
+	config.OnReload(func(changed []string, err error) {
+		if err != nil {
+			Logger.Error("config reload rejected, keep the current config", Error(err))
+			return
+		}
+		Logger.Info("config reloaded", Any("changed", changed))
+	})
+}
+
+// swapWriter lets a reload replace the sinks under the running logger.
+type swapWriter struct {
+	lock   sync.RWMutex
+	writer io.Writer
+	closer io.Closer
+}
+
+func (w *swapWriter) Write(p []byte) (int, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.writer.Write(p)
+}
+
+func (w *swapWriter) Sync() error {
+	return nil
+}
+
+func (w *swapWriter) set(writer io.Writer, closer io.Closer) {
+	w.lock.Lock()
+	previous := w.closer
+	w.writer, w.closer = writer, closer
+	w.lock.Unlock()
+	if previous != nil {
+		_ = previous.Close()
+	}
+}
+
+// SetLogger builds the logger from the config and follows its reloads. The
+// level, log_dir, rotation, stdout sink and redaction apply in place, the format is read
+// once at startup. It replaces Logger unsynchronized, call it before
+// config.WatchConfig and the goroutines that log.
 func SetLogger() {
+	conf := config.GetConfig()
+	if err := setOutput(conf); err != nil {
+		panic(err)
+	}
+	setRedactor(conf.Log.Redact, conf.RunningMode)
+	Logger = zap.New(zapcore.NewCore(newEncoder(conf.Log.Format), output, level), zap.AddCaller())
+	config.Subscribe("log", func(oldConf, newConf *config.Config, changed []string) {
+		if !config.SectionChanged(changed, "log") && !config.SectionChanged(changed, "log_dir") {
+			return
+		}
+		if err := setOutput(newConf); err != nil {
+			Logger.Error("logger reload error, keep the current logger", Error(err))
+		}
+		setRedactor(newConf.Log.Redact, newConf.RunningMode)
+		if oldConf.Log.Format != newConf.Log.Format {
+			Logger.Warn("log format changed, restart satis to apply it")
+		}
+	})
+}
+
+// setOutput applies the level and replaces the file and stdout sinks.
+func setOutput(conf *config.Config) error {
+	if err := setLevel(conf.Log.Level); err != nil {
+		return err
+	}
+	file, err := newFileWriter(conf.LogDir, "satis", conf.Log)
+	if err != nil {
+		return err
+	}
+	var writer io.Writer = file
+	if conf.Log.Stdout {
+		writer = io.MultiWriter(file, os.Stdout)
+	}
+	output.set(writer, file)
+	return nil
+}
+
+func setLevel(name string) error {
+	if name == "" {
+		level.SetLevel(zapcore.InfoLevel)
+		return nil
+	}
+	l, err := zapcore.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(l)
+	return nil
+}
+
+func newEncoder(format string) zapcore.Encoder {
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.000")
 	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
-	//encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
 	encoderConfig.LevelKey = "level"
 	encoderConfig.TimeKey = "time"
 	encoderConfig.MessageKey = "msg"
-
-	level := zap.NewAtomicLevel()
-	level.SetLevel(zapcore.DebugLevel)
-	writer := getWriter(config.GetConfig().LogDir, "satis")
-	jsonEncoder := zapcore.NewJSONEncoder(encoderConfig)
-	core := zapcore.NewTee(
-		zapcore.NewCore(jsonEncoder, zapcore.AddSync(writer), level),
-	)
-	Logger = zap.New(core, zap.AddCaller())
-	defer Logger.Sync()
+	if format == FormatConsole {
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	}
+	return zapcore.NewJSONEncoder(encoderConfig)
 }
 
+// Any logs v with its sensitive fields redacted.
 func Any(k string, v interface{}) zapcore.Field {
-	return zap.Any(k, v)
+	return zap.Any(k, Redact(k, v))
 }
+
+// String logs v redacted by its name, json values are redacted by field.
 func String(k string, v string) zapcore.Field {
-	return zap.String(k, v)
+	redacted := Redact(k, v)
+	if s, ok := redacted.(string); ok {
+		return zap.String(k, s)
+	}
+	return zap.Any(k, redacted)
 }
 func Int64(k string, v int64) zapcore.Field {
 	return zap.Int64(k, v)
@@ -55,20 +164,21 @@ func Error(err error) zapcore.Field {
 	return zap.Error(err)
 }
 
-func getWriter(log_dir, prefix string) io.Writer {
+// newFileWriter rotates daily, and by size when max_size is set.
+func newFileWriter(logDir, prefix string, conf config.Log) (*rotatelogs.RotateLogs, error) {
 	hostname, err := os.Hostname()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	p := log_dir + prefix + "_%Y%m%d_" + hostname + ".log"
-
-	hook, err := rotatelogs.New(
-		p,
-		rotatelogs.WithMaxAge(time.Hour*24*7),
-	)
-
-	if err != nil {
-		panic(err)
+	maxAge := conf.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultMaxAge
+	}
+	options := []rotatelogs.Option{
+		rotatelogs.WithMaxAge(time.Duration(maxAge) * 24 * time.Hour),
+	}
+	if conf.MaxSize > 0 {
+		options = append(options, rotatelogs.WithRotationSize(int64(conf.MaxSize)*1024*1024))
 	}
-	return hook
+	return rotatelogs.New(filepath.Join(logDir, prefix+"_%Y%m%d_"+hostname+".log"), options...)
 }
@@ -0,0 +1,208 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/consts"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	AddressPlain = "plain"
+	AddressHash  = "hash"
+)
+
+var (
+	// sensitiveNames are masked when a field name equals them.
+	sensitiveNames = []string{"data", "content", "body"}
+	// sensitiveParts are masked when a field name contains them.
+	sensitiveParts = []string{"signature", "mnemonic", "password", "secret", "privatekey", "token"}
+	// defaultHeaders are the request headers logged when none are configured.
+	defaultHeaders = []string{"Content-Type", "Content-Length", "User-Agent", "X-Trace-Id", "X-Forwarded-For", "X-Real-Ip"}
+)
+
+type redactor struct {
+	disabled    bool
+	names       map[string]bool
+	hashAddress bool
+	headers     []string
+}
+
+var currentRedactor atomic.Pointer[redactor]
+
+func init() {
+	setRedactor(config.LogRedact{}, "")
+}
+
+// setRedactor applies the redact config. Official mode always redacts and
+// hashes addresses unless address: plain is set explicitly.
+func setRedactor(conf config.LogRedact, runningMode string) {
+	official := runningMode == consts.RunningModeOfficial
+	r := &redactor{
+		disabled:    conf.Disable && !official,
+		names:       make(map[string]bool),
+		hashAddress: conf.Address == AddressHash || (official && conf.Address == ""),
+		headers:     conf.Headers,
+	}
+	for _, name := range sensitiveNames {
+		r.names[name] = true
+	}
+	for _, name := range conf.Fields {
+		r.names[normalizeName(name)] = true
+	}
+	if len(r.headers) == 0 {
+		r.headers = defaultHeaders
+	}
+	currentRedactor.Store(r)
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+func (r *redactor) sensitive(name string) bool {
+	name = normalizeName(name)
+	if r.names[name] {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdentityName reports whether a field names an address or an org id.
+func isIdentityName(name string) bool {
+	name = normalizeName(name)
+	return strings.Contains(name, "addr") || name == "orgid"
+}
+
+func mask(v any) string {
+	switch x := v.(type) {
+	case string:
+		return fmt.Sprintf("[redacted %d chars]", len(x))
+	case []byte:
+		return fmt.Sprintf("[redacted %d bytes]", len(x))
+	}
+	return "[redacted]"
+}
+
+// HashAddress returns a stable short digest of an address, so log lines of one
+// user can be correlated without logging the address itself.
+func HashAddress(addr string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(addr)))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// field redacts one named value.
+func (r *redactor) field(name string, v any) any {
+	if r.disabled {
+		return v
+	}
+	if r.sensitive(name) {
+		return mask(v)
+	}
+	if s, ok := v.(string); ok && r.hashAddress && isIdentityName(name) && s != "" {
+		return HashAddress(s)
+	}
+	return r.value(v)
+}
+
+// value redacts the nested fields of v. Scalars are kept, structs, maps and
+// json strings are walked by field name.
+func (r *redactor) value(v any) any {
+	switch x := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, error:
+		return v
+	case proto.Message:
+		// walked below, String() would print every field
+	case fmt.Stringer:
+		return x.String()
+	case []byte:
+		return mask(x)
+	case string:
+		t := strings.TrimSpace(x)
+		if !strings.HasPrefix(t, "{") && !strings.HasPrefix(t, "[") {
+			return v
+		}
+		var doc any
+		if err := jsoniter.UnmarshalFromString(t, &doc); err != nil {
+			return v
+		}
+		return r.walk(doc)
+	}
+	data, err := jsoniter.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("[unloggable %T]", v)
+	}
+	var doc any
+	if err := jsoniter.Unmarshal(data, &doc); err != nil {
+		return fmt.Sprintf("[unloggable %T]", v)
+	}
+	return r.walk(doc)
+}
+
+func (r *redactor) walk(doc any) any {
+	switch x := doc.(type) {
+	case map[string]any:
+		for k, v := range x {
+			x[k] = r.field(k, v)
+		}
+	case []any:
+		for i, v := range x {
+			x[i] = r.walk(v)
+		}
+	case string:
+		return r.value(x)
+	}
+	return doc
+}
+
+// Redact returns v with the sensitive fields masked, as it would be logged.
+func Redact(name string, v any) any {
+	return currentRedactor.Load().field(name, v)
+}
+
+// Hashed logs an address or org id as its HashAddress digest whatever the
+// address setting, for the lines that must not identify users in any mode.
+func Hashed(k, v string) zapcore.Field {
+	if currentRedactor.Load().disabled || v == "" {
+		return zap.String(k, v)
+	}
+	return zap.String(k, HashAddress(v))
+}
+
+// Headers keeps the allow-listed request headers only.
+func Headers(k string, h http.Header) zapcore.Field {
+	r := currentRedactor.Load()
+	if r.disabled {
+		return zap.Any(k, h)
+	}
+	kept := make(map[string]string, len(r.headers))
+	for _, name := range r.headers {
+		if value := h.Get(name); value != "" {
+			kept[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	return zap.Any(k, kept)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/consts"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	testAddr   = "0xAbCdEf0123456789aBcDeF0123456789AbCdEf01"
+	testOrg    = "org-secret-42"
+	testSecret = "s3cret-value"
+)
+
+// captureLogger points Logger at a buffer for the test.
+func captureLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	previous := Logger
+	Logger = zap.New(zapcore.NewCore(newEncoder(FormatJson), zapcore.AddSync(buf), zapcore.DebugLevel))
+	t.Cleanup(func() { Logger = previous })
+	return buf
+}
+
+func withRedactor(t *testing.T, conf config.LogRedact, runningMode string) {
+	t.Helper()
+	setRedactor(conf, runningMode)
+	t.Cleanup(func() { setRedactor(config.LogRedact{}, "") })
+}
+
+func TestSensitiveFieldsNeverLogged(t *testing.T) {
+	params := `{"addr":"` + testAddr + `","org_id":"` + testOrg + `","signature":"` + testSecret + `",` +
+		`"ops":[{"route":"/web3password/addCredential","params":"{\"token\":\"` + testSecret + `\",\"mnemonic\":\"` + testSecret + `\"}","data":"` + testSecret + `"}]}`
+	tests := []struct {
+		name   string
+		conf   config.LogRedact
+		mode   string
+		hidden []string
+		shown  []string
+	}{
+		{"local", config.LogRedact{}, consts.RunningModeLocal, []string{testSecret}, []string{testAddr, testOrg}},
+		{"hash", config.LogRedact{Address: AddressHash}, consts.RunningModeLocal, []string{testSecret, testAddr, testOrg}, []string{HashAddress(testAddr)}},
+		{"official", config.LogRedact{Disable: true}, consts.RunningModeOfficial, []string{testSecret, testAddr, testOrg}, nil},
+		{"custom field", config.LogRedact{Fields: []string{"org_id"}}, consts.RunningModeLocal, []string{testSecret, testOrg}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRedactor(t, tt.conf, tt.mode)
+			buf := captureLogger(t)
+			Logger.Info("line",
+				String("params", params),
+				String("signature", testSecret),
+				Any("request", map[string]any{"Signature": testSecret, "Params": params, "AppendData": []byte(testSecret)}),
+				Any("data", []byte(testSecret)))
+			out := buf.String()
+			for _, s := range tt.hidden {
+				if strings.Contains(out, s) {
+					t.Errorf("%q logged: %s", s, out)
+				}
+			}
+			for _, s := range tt.shown {
+				if !strings.Contains(out, s) {
+					t.Errorf("%q not logged: %s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestHashedIgnoresAddressSetting(t *testing.T) {
+	withRedactor(t, config.LogRedact{Address: AddressPlain}, consts.RunningModeLocal)
+	buf := captureLogger(t)
+	Logger.Info("line", Hashed("addr", testAddr), Hashed("org_id", testOrg))
+	out := buf.String()
+	if strings.Contains(out, testAddr) || strings.Contains(out, testOrg) {
+		t.Fatalf("identity logged: %s", out)
+	}
+	if !strings.Contains(out, HashAddress(testAddr)) || !strings.Contains(out, HashAddress(testOrg)) {
+		t.Fatalf("digests not logged: %s", out)
+	}
+
+	withRedactor(t, config.LogRedact{Disable: true}, consts.RunningModeLocal)
+	buf.Reset()
+	Logger.Info("line", Hashed("addr", testAddr))
+	if !strings.Contains(buf.String(), testAddr) {
+		t.Fatalf("disabled redaction hides the address: %s", buf.String())
+	}
+}
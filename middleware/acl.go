@@ -0,0 +1,499 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gin-gonic/gin"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/service/handlers"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	aclScopeAll   = "*"
+	aclScopeLocal = "local"
+)
+
+// orgMatcher matches org ids exactly, or against "*" glob patterns.
+type orgMatcher struct {
+	exact    map[string]bool
+	patterns []string
+}
+
+func newOrgMatcher(entries []string) orgMatcher {
+	m := orgMatcher{exact: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "*") {
+			m.patterns = append(m.patterns, entry)
+			continue
+		}
+		m.exact[entry] = true
+	}
+	return m
+}
+
+func (m orgMatcher) empty() bool {
+	return len(m.exact) == 0 && len(m.patterns) == 0
+}
+
+// match returns the matching entry.
+func (m orgMatcher) match(org string) (string, bool) {
+	if org == "" {
+		return "", false
+	}
+	if m.exact[org] {
+		return org, true
+	}
+	for _, pattern := range m.patterns {
+		if ok, _ := path.Match(pattern, org); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func newAddrSet(entries []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry != "" {
+			set[entry] = true
+		}
+	}
+	return set
+}
+
+type accessList struct {
+	name       string
+	scope      []string
+	allowAddrs map[string]bool
+	denyAddrs  map[string]bool
+	allowOrgs  orgMatcher
+	denyOrgs   orgMatcher
+}
+
+func (l *accessList) applies(route, action string) bool {
+	group := routeGroup(route)
+	for _, scope := range l.scope {
+		switch scope {
+		case aclScopeAll, route, group:
+			return true
+		case aclScopeLocal:
+			if action != consts.RouteActionProxy {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evaluate returns whether the list allows the request and why.
+func (l *accessList) evaluate(addr, org string) (bool, string) {
+	if l.denyAddrs[addr] {
+		return false, fmt.Sprintf("list %s denies addr", l.name)
+	}
+	if entry, ok := l.denyOrgs.match(org); ok {
+		return false, fmt.Sprintf("list %s denies org by %s", l.name, entry)
+	}
+	if len(l.allowAddrs) == 0 && l.allowOrgs.empty() {
+		return true, fmt.Sprintf("list %s has no allow entries", l.name)
+	}
+	if l.allowAddrs[addr] {
+		return true, fmt.Sprintf("list %s allows addr", l.name)
+	}
+	if entry, ok := l.allowOrgs.match(org); ok {
+		return true, fmt.Sprintf("list %s allows org by %s", l.name, entry)
+	}
+	return false, fmt.Sprintf("list %s does not allow addr or org", l.name)
+}
+
+// routeGroup returns the route group of a path, e.g. /web3password/admin/addMember is admin.
+func routeGroup(route string) string {
+	rest := strings.TrimPrefix(route, "/web3password/")
+	if i := strings.Index(rest, "/"); i > 0 {
+		return rest[:i]
+	}
+	return "user"
+}
+
+// ACL is a compiled set of access lists.
+type ACL struct {
+	lists []*accessList
+}
+
+// CompileACL compiles the configured lists, the lists of the external file and
+// the legacy personal_white_list and org_white_list.
+func CompileACL(conf *config.Config, fileLists []config.AccessList) *ACL {
+	acl := &ACL{}
+	if conf.PersonalWhiteList != "" || conf.OrgWhiteList != "" {
+		acl.add(config.AccessList{
+			Name:       "white_list",
+			AllowAddrs: splitList(conf.PersonalWhiteList),
+			AllowOrgs:  splitList(conf.OrgWhiteList),
+		})
+	}
+	for _, l := range conf.AccessControl.Lists {
+		acl.add(l)
+	}
+	for _, l := range fileLists {
+		acl.add(l)
+	}
+	return acl
+}
+
+func (a *ACL) add(l config.AccessList) {
+	name := l.Name
+	if name == "" {
+		name = fmt.Sprintf("#%d", len(a.lists))
+	}
+	scope := l.Scope
+	if len(scope) == 0 {
+		scope = []string{aclScopeLocal}
+	}
+	a.lists = append(a.lists, &accessList{
+		name:       name,
+		scope:      scope,
+		allowAddrs: newAddrSet(l.AllowAddrs),
+		denyAddrs:  newAddrSet(l.DenyAddrs),
+		allowOrgs:  newOrgMatcher(l.AllowOrgs),
+		denyOrgs:   newOrgMatcher(l.DenyOrgs),
+	})
+}
+
+// Applies reports whether any list is in scope for the route.
+func (a *ACL) Applies(route, action string) bool {
+	for _, l := range a.lists {
+		if l.applies(route, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate checks every list in scope, the request must be allowed by all of them.
+func (a *ACL) Evaluate(route, action, addr, org string) (bool, string) {
+	addr = strings.ToLower(addr)
+	var reasons []string
+	for _, l := range a.lists {
+		if !l.applies(route, action) {
+			continue
+		}
+		allowed, why := l.evaluate(addr, org)
+		if !allowed {
+			return false, why
+		}
+		reasons = append(reasons, why)
+	}
+	if len(reasons) == 0 {
+		return true, "no list in scope"
+	}
+	return true, strings.Join(reasons, "; ")
+}
+
+// splitList splits a legacy list separated by commas, semicolons or whitespace.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// aclFile keeps the lists of the external file, reloading them when it changes.
+type aclFile struct {
+	path    string
+	lock    sync.RWMutex
+	lists   []config.AccessList
+	version int
+	done    chan struct{}
+	once    sync.Once
+}
+
+type aclFileContent struct {
+	Lists []config.AccessList `yaml:"lists"`
+}
+
+func newACLFile(path string) *aclFile {
+	f := &aclFile{path: path, done: make(chan struct{})}
+	if err := f.load(); err != nil {
+		log.Logger.Error("acl file load error", log.String("file", path), log.Error(err))
+	}
+	go f.watch()
+	return f
+}
+
+// load replaces the lists, an invalid file keeps the previous lists.
+func (f *aclFile) load() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	content := aclFileContent{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	f.lists = content.Lists
+	f.version++
+	f.lock.Unlock()
+	log.Logger.Info("acl file loaded", log.String("file", f.path), log.Any("lists", len(content.Lists)))
+	return nil
+}
+
+// stop ends the watch of a file the config no longer names.
+func (f *aclFile) stop() {
+	f.once.Do(func() { close(f.done) })
+}
+
+func (f *aclFile) get() ([]config.AccessList, int) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.lists, f.version
+}
+
+// watch follows the directory, so editors that rename the file into place are seen too.
+func (f *aclFile) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Logger.Error("acl file watch error", log.String("file", f.path), log.Error(err))
+		return
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(f.path)); err != nil {
+		log.Logger.Error("acl file watch error", log.String("file", f.path), log.Error(err))
+		return
+	}
+	name := filepath.Clean(f.path)
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != name || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			if err := f.load(); err != nil {
+				log.Logger.Error("acl file reload error", log.String("file", f.path), log.Error(err))
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Logger.Error("acl file watch error", log.String("file", f.path), log.Error(err))
+		case <-f.done:
+			log.Logger.Info("acl file watch stopped", log.String("file", f.path))
+			return
+		}
+	}
+}
+
+var (
+	aclLock        sync.Mutex
+	aclConfig      *config.Config
+	aclFileVersion int
+	aclFiles       = make(map[string]*aclFile)
+	acl            *ACL
+)
+
+func init() {
+	config.Subscribe("acl", func(_, newConf *config.Config, changed []string) {
+		if config.SectionChanged(changed, "access_control") {
+			pruneACLFiles(newConf.AccessControl.File)
+		}
+	})
+}
+
+// pruneACLFiles stops the watchers of every file but keep.
+func pruneACLFiles(keep string) {
+	aclLock.Lock()
+	defer aclLock.Unlock()
+	for path, f := range aclFiles {
+		if path != keep {
+			f.stop()
+			delete(aclFiles, path)
+		}
+	}
+}
+
+// currentACL returns the ACL compiled from the current config and access list file.
+func currentACL() *ACL {
+	conf := config.GetConfig()
+	aclLock.Lock()
+	defer aclLock.Unlock()
+	var fileLists []config.AccessList
+	version := 0
+	if path := conf.AccessControl.File; path != "" {
+		f, ok := aclFiles[path]
+		if !ok {
+			f = newACLFile(path)
+			aclFiles[path] = f
+		}
+		fileLists, version = f.get()
+	}
+	if acl == nil || aclConfig != conf || aclFileVersion != version {
+		acl = CompileACL(conf, fileLists)
+		aclConfig = conf
+		aclFileVersion = version
+		log.Logger.Info("acl compiled", log.Any("lists", len(acl.lists)))
+	}
+	return acl
+}
+
+// AccessControl enforces the access lists on the address and org_id of each request.
+func AccessControl(runningMode string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ensureTraceID(ctx)
+		route := ctx.Request.URL.Path
+		action := consts.RouteActionLocal
+		if runningMode != consts.RunningModeOfficial {
+			action = currentRoutePolicy(runningMode).Action(route)
+		}
+		acl := currentACL()
+		if !acl.Applies(route, action) {
+			ctx.Next()
+			return
+		}
+
+		paramsStr, err := peekParams(ctx.Request)
+		params := model.CommonParams{}
+		if err == nil {
+			err = jsoniter.UnmarshalFromString(paramsStr, &params)
+		}
+		if err != nil {
+			log.Logger.Warn("acl params error", log.String("route", route), log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
+			handlers.Response(ctx, model.StatusParamsErr, "params error", []byte(""))
+			ctx.Abort()
+			return
+		}
+
+		allowed, reason := acl.Evaluate(route, action, params.Address, params.OrgId)
+		decision := log.Logger.Debug
+		if !allowed {
+			decision = log.Logger.Warn
+		}
+		decision("acl decision", log.String("route", route), log.Hashed("addr", params.Address), log.Hashed("org_id", params.OrgId),
+			log.Any("allowed", allowed), log.String("reason", reason), log.String("trace_id", ctx.GetString("trace_id")))
+		if !allowed {
+			handlers.ResponseError(ctx, model.NewError(model.CodeForbidden, ""))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// aclMaxPeek bounds what peekParams buffers ahead of the data of a request.
+const aclMaxPeek = 1 << 20
+
+// peekParams returns the params of a BSON request, leaving its body intact.
+// Clients encode the signature and the params ahead of the data, so only they
+// are buffered and the data, possibly a large upload, stays streamed. Any other
+// layout falls back to reading the whole body.
+func peekParams(req *http.Request) (string, error) {
+	body := req.Body
+	prefix := &bytes.Buffer{}
+	r := io.TeeReader(io.LimitReader(body, aclMaxPeek), prefix)
+	params, ok, err := scanParams(r)
+	if err == nil && !ok {
+		var rest []byte
+		if rest, err = io.ReadAll(body); err == nil {
+			prefix.Write(rest)
+			var request *encode.Web3PasswordRequestBsonStruct
+			if request, err = decodeRequest(prefix.Bytes()); err == nil {
+				params = request.ParamsStr
+			}
+		}
+	}
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix.Bytes()), body), body}
+	return params, err
+}
+
+// scanParams reads the request frame, a 2 byte version, a 4 byte length and a
+// BSON document, up to the params string. ok is false when another element
+// than the signature comes first.
+func scanParams(r io.Reader) (params string, ok bool, err error) {
+	header := make([]byte, 2+4+4)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return "", false, err
+	}
+	for {
+		var kind [1]byte
+		if _, err = io.ReadFull(r, kind[:]); err != nil {
+			return "", false, err
+		}
+		if kind[0] != bsonTypeString {
+			return "", false, nil
+		}
+		name, err := readCString(r)
+		if err != nil {
+			return "", false, err
+		}
+		var size [4]byte
+		if _, err = io.ReadFull(r, size[:]); err != nil {
+			return "", false, err
+		}
+		n := int(binary.LittleEndian.Uint32(size[:]))
+		if n < 1 || n > aclMaxPeek {
+			return "", false, fmt.Errorf("bson string of %d bytes", n)
+		}
+		value := make([]byte, n)
+		if _, err = io.ReadFull(r, value); err != nil {
+			return "", false, err
+		}
+		switch name {
+		case "params":
+			return string(value[:n-1]), true, nil
+		case "signature":
+		default:
+			return "", false, nil
+		}
+	}
+}
+
+const bsonTypeString = 0x02
+
+func readCString(r io.Reader) (string, error) {
+	var name []byte
+	var c [1]byte
+	for len(name) < 64 {
+		if _, err := io.ReadFull(r, c[:]); err != nil {
+			return "", err
+		}
+		if c[0] == 0 {
+			return string(name), nil
+		}
+		name = append(name, c[0])
+	}
+	return "", fmt.Errorf("bson element name too long")
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/middleware"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAccessControlLogsNoIdentity(t *testing.T) {
+	const (
+		denied  = "0x2222222222222222222222222222222222222222"
+		allowed = "0x3333333333333333333333333333333333333333"
+		org     = "org-7f3a"
+	)
+	loadConfig(t, consts.RunningModeLocal, "access_control:\n  lists:\n    - name: deny\n      deny_addrs: ["+denied+"]\n")
+	buf := &bytes.Buffer{}
+	previous := log.Logger
+	log.Logger = zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zapcore.DebugLevel))
+	defer func() { log.Logger = previous }()
+
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	engine.Use(middleware.AccessControl(consts.RunningModeLocal))
+	engine.POST("/web3password/addCredential", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	for _, addr := range []string{denied, allowed} {
+		body, err := encode.Web3PasswordRequestBsonEncode("0xsignature", `{"addr":"`+addr+`","org_id":"`+org+`"}`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/web3password/addCredential", bytes.NewReader(body)))
+	}
+	out := buf.String()
+	if strings.Count(out, "acl decision") != 2 {
+		t.Fatalf("decisions not logged: %s", out)
+	}
+	for _, s := range []string{denied, allowed, org, "0xsignature"} {
+		if strings.Contains(out, s) {
+			t.Errorf("%q logged: %s", s, out)
+		}
+	}
+}
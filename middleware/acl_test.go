@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/web3password/jewel/encode"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// countingReader counts the bytes read from the wire.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func (c *countingReader) Close() error { return nil }
+
+func TestPeekParamsLeavesDataStreamed(t *testing.T) {
+	params := `{"addr":"0xabc","org_id":"org"}`
+	data := bytes.Repeat([]byte{7}, 4<<20)
+	body, err := encode.Web3PasswordRequestBsonEncode("sig", params, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wire := &countingReader{r: bytes.NewReader(body)}
+	req, _ := http.NewRequest(http.MethodPost, "/web3password/file/upload", wire)
+	got, err := peekParams(req)
+	if err != nil || got != params {
+		t.Fatalf("params %q err %v", got, err)
+	}
+	if wire.n >= len(data) {
+		t.Fatalf("read %d bytes to peek the params", wire.n)
+	}
+	rest, err := io.ReadAll(req.Body)
+	if err != nil || !bytes.Equal(rest, body) {
+		t.Fatalf("body changed by the peek, err %v", err)
+	}
+}
+
+func TestPeekParamsOtherLayout(t *testing.T) {
+	params := `{"addr":"0xabc"}`
+	doc, err := bson.Marshal(bson.D{{Name: "data", Value: []byte("xyz")}, {Name: "params", Value: params}, {Name: "signature", Value: "sig"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := append([]byte(encode.RequestVersion), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(body[2:], uint32(len(doc)))
+	body = append(body, doc...)
+	req, _ := http.NewRequest(http.MethodPost, "/web3password/addCredential", bytes.NewReader(body))
+	got, err := peekParams(req)
+	if err != nil || got != params {
+		t.Fatalf("params %q err %v", got, err)
+	}
+	rest, _ := io.ReadAll(req.Body)
+	if !bytes.Equal(rest, body) {
+		t.Fatal("body changed by the peek")
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "/web3password/addCredential", bytes.NewReader(body[:5]))
+	if _, err := peekParams(req); err == nil {
+		t.Fatal("truncated body accepted")
+	}
+}
+
+func TestPruneACLFilesStopsWatchers(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml")}
+	files := make([]*aclFile, 0, len(paths))
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte("lists: []\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		f := newACLFile(path)
+		aclLock.Lock()
+		aclFiles[path] = f
+		aclLock.Unlock()
+		files = append(files, f)
+	}
+	pruneACLFiles(paths[1])
+	select {
+	case <-files[0].done:
+	case <-time.After(time.Second):
+		t.Fatal("watcher of the dropped file not stopped")
+	}
+	aclLock.Lock()
+	_, kept := aclFiles[paths[1]]
+	n := len(aclFiles)
+	aclLock.Unlock()
+	if !kept || n != 1 {
+		t.Fatalf("kept %v of %d files", kept, n)
+	}
+	pruneACLFiles("")
+}
@@ -12,136 +12,147 @@ package middleware
 
 import (
 	"bytes"
-	"crypto/rand"
-	"encoding/base64"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	jsoniter "github.com/json-iterator/go"
-	"github.com/web3password/jewel/encode"
-	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/audit"
 	"github.com/web3password/satis/consts"
 	"github.com/web3password/satis/log"
 	"github.com/web3password/satis/model"
 	"github.com/web3password/satis/service/handlers"
 	"io"
-	"math/big"
 	"net/http"
-	"strings"
+	"os"
 )
 
 func Agent(runningMode string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		bodyBytes, err := io.ReadAll(ctx.Request.Body)
-		ctx.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-		if runningMode == consts.RunningModeAudit {
-			log.Logger.Info(ctx.Request.RequestURI, log.String("audit_log", base64.StdEncoding.EncodeToString(bodyBytes)))
-		}
-		if err != nil {
-			handlers.Response(ctx, model.StatusParamsErr, "params error", []byte(""))
-			ctx.Abort()
-			return
-		}
-		personalWhiteList := config.GetConfig().PersonalWhiteList
-		orgWhiteList := config.GetConfig().OrgWhiteList
-		params := model.CommonParams{}
-		if len(personalWhiteList) > 0 || len(orgWhiteList) > 0 {
-			request, _ := encode.Web3PasswordRequestBsonDecode(bodyBytes)
-			if err := jsoniter.UnmarshalFromString(request.ParamsStr, &params); err != nil {
+		ensureTraceID(ctx)
+		policy := currentRoutePolicy(runningMode)
+		action := policy.Action(ctx.Request.URL.Path)
+		retry := IsReadOnlyRoute(ctx.Request.URL.Path)
+
+		// proxied bodies are streamed, unless they must be inspected or replayed on another domain
+		var bodyBytes []byte
+		if action != consts.RouteActionProxy || runningMode == consts.RunningModeAudit || retry {
+			var err error
+			bodyBytes, err = io.ReadAll(ctx.Request.Body)
+			ctx.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+			if err != nil {
 				handlers.Response(ctx, model.StatusParamsErr, "params error", []byte(""))
 				ctx.Abort()
 				return
 			}
+			if runningMode == consts.RunningModeAudit {
+				auditRequest(ctx, bodyBytes)
+			}
 		}
-		if runningMode == consts.RunningModeLocal &&
-			(strings.Contains(ctx.Request.RequestURI, "/file") ||
-				strings.Contains(ctx.Request.RequestURI, "/sharefolder") ||
-				strings.Contains(ctx.Request.RequestURI, "/getLatestBlockTimestamp") ||
-				strings.Contains(ctx.Request.RequestURI, "/checkTx") ||
-				strings.Contains(ctx.Request.RequestURI, "/addCredential") ||
-				strings.Contains(ctx.Request.RequestURI, "/deleteCredential") ||
-				strings.Contains(ctx.Request.RequestURI, "/getCredential") ||
-				strings.Contains(ctx.Request.RequestURI, "/deleteAllCredential") ||
-				strings.Contains(ctx.Request.RequestURI, "/getAllCredentialTimestamp") ||
-				strings.Contains(ctx.Request.RequestURI, "/getCredentialList")) {
-			if (len(personalWhiteList) > 0 || len(orgWhiteList) > 0) && !strings.Contains(personalWhiteList, params.Address) && !strings.Contains(orgWhiteList, params.OrgId) {
-				handlers.Response(ctx, model.StatusForbiddenErr, "current node forbid error", []byte(""))
-				ctx.Abort()
+
+		switch action {
+		case consts.RouteActionDeny:
+			handlers.ResponseError(ctx, model.NewError(model.CodeForbidden, ""))
+			ctx.Abort()
+			return
+		case consts.RouteActionLocal:
+			ctx.Next()
+			return
+		case consts.RouteActionLocalReport:
+			writer := &recordingWriter{ResponseWriter: ctx.Writer}
+			ctx.Writer = writer
+			ctx.Next()
+			ctx.Writer = writer.ResponseWriter
+			if !succeeded(writer.Status(), writer.body.Bytes()) {
+				log.Logger.Info("report official skipped, request failed", log.String("uri", ctx.Request.RequestURI), log.String("trace_id", ctx.GetString("trace_id")))
 				return
 			}
-			ctx.Next()
+			ReportOfficial(ctx, bodyBytes)
 			return
 		}
-		// admin
-		if runningMode == consts.RunningModeLocal && (strings.Contains(ctx.Request.RequestURI, "/admin")) {
-			if strings.Contains(ctx.Request.RequestURI, "/register") ||
-				strings.Contains(ctx.Request.RequestURI, "/addMember") ||
-				strings.Contains(ctx.Request.RequestURI, "/removeMember") ||
-				strings.Contains(ctx.Request.RequestURI, "/transferSuperAdmin") {
-				defer ReportOfficial(ctx)
+
+		if bodyBytes != nil {
+			ctx.Request.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(bodyBytes)), nil
 			}
-			ctx.Next()
-			return
 		}
-		officialURL := randomOfficeUrl() + ctx.Request.RequestURI
-		res := DoRequestBinary(officialURL, bodyBytes, "POST")
-		handlers.Response(ctx, res.Code, res.Msg, res.Data)
+		ProxyOfficial(ctx, policy.Timeout(ctx.Request.URL.Path), retry)
 		ctx.Abort()
-		return
 	}
 }
 
-func randomOfficeUrl() string {
-	domains := config.GetConfig().OfficialDomains
-	randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(domains))))
-	if err != nil {
-		return domains[0]
+// ensureTraceID sets the trace id ahead of ParamsCheck, so middlewares can log and forward it.
+func ensureTraceID(ctx *gin.Context) {
+	if ctx.GetString("trace_id") == "" {
+		ctx.Set("trace_id", uuid.NewString())
 	}
-	return domains[randomIndex.Int64()]
 }
 
-func ReportOfficial(ctx *gin.Context) {
-	officialURL := randomOfficeUrl() + ctx.Request.RequestURI
-	log.Logger.Info("default official URL:", log.String("official", officialURL))
-	bytes, err := io.ReadAll(ctx.Request.Body)
-	log.Infof("officialURL: %+v", officialURL)
-	if err != nil {
-		ctx.Abort()
+// auditRequest appends the request to the audit sink.
+func auditRequest(ctx *gin.Context, body []byte) {
+	sink := audit.GetSink()
+	if sink == nil {
 		return
 	}
-	DoRequestBinary(officialURL, bytes, "POST")
-	return
+	params := model.CommonParams{}
+	if request, err := decodeRequest(body); err == nil {
+		_ = jsoniter.UnmarshalFromString(request.ParamsStr, &params)
+	}
+	if err := sink.Write(ctx.Request.URL.Path, params.Address, params.OrgId, ctx.GetString("trace_id"), body); err != nil {
+		log.Logger.Error("audit sink write error", log.String("uri", ctx.Request.RequestURI), log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
+	}
 }
 
-func DoRequestBinary(url string, body []byte, method string) *encode.Web3PasswordResponseBsonStruct {
-	if method == "" {
-		method = "POST"
+// ReportOfficial queues the original signed request for delivery to the official domain.
+func ReportOfficial(ctx *gin.Context, body []byte) {
+	outbox := GetOutbox()
+	if outbox == nil {
+		log.Logger.Error("report official outbox not enabled", log.String("uri", ctx.Request.RequestURI), log.String("trace_id", ctx.GetString("trace_id")))
+		return
 	}
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/octet-stream")
+	event, err := outbox.Enqueue(ctx.Request.RequestURI, ctx.GetString("trace_id"), body)
 	if err != nil {
-		//log.Logger.Error("NewRequest url:%s, err:%+v\n", url, err)
-		return nil
+		log.Logger.Error("report official enqueue error", log.String("uri", ctx.Request.RequestURI), log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
+		return
 	}
-	var client = &http.Client{}
-	rsp, err := client.Do(req)
-	if err != nil {
-		//log.Logger.Error("request failed, url:%s err:%+v\n", url, err)
-		return nil
+	log.Logger.Info("report official enqueued", log.String("uri", ctx.Request.RequestURI), log.String("id", event.ID), log.String("trace_id", ctx.GetString("trace_id")))
+}
+
+// succeeded reports whether a response is a BSON response with StatusOK.
+func succeeded(status int, body []byte) bool {
+	if status != http.StatusOK {
+		return false
 	}
-	defer func() { _ = rsp.Body.Close() }()
-	statusCode := rsp.StatusCode
-	if statusCode != 200 {
-		log.Infof("request failed, statusCode:%d url:%s\n", statusCode, url)
-		return nil
+	ret, err := decodeResponse(body)
+	return err == nil && ret.Code == model.StatusOK
+}
+
+// GetOutboxStatus reports the delivery status of the report outbox.
+func GetOutboxStatus(ctx *gin.Context) {
+	outbox := GetOutbox()
+	if outbox == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "report outbox not enabled"})
+		return
 	}
-	r, err := io.ReadAll(rsp.Body)
-	if err != nil {
-		//log.Logger.Error("read body err:%+v\n", err)
-		return nil
+	ctx.JSON(http.StatusOK, outbox.Status())
+}
+
+// RequeueOutbox moves the failed report events back to pending, the one of
+// the id query parameter or all of them.
+func RequeueOutbox(ctx *gin.Context) {
+	outbox := GetOutbox()
+	if outbox == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "report outbox not enabled"})
+		return
+	}
+	n, err := outbox.Requeue(ctx.Query("id"))
+	if errors.Is(err, os.ErrNotExist) {
+		ctx.JSON(http.StatusNotFound, gin.H{"msg": "failed event not found", "requeued": n})
+		return
 	}
-	decode, err := encode.Web3PasswordResponseBsonDecode(r)
 	if err != nil {
-		//log.Logger.Error("response decode failed, url: %s, err: %s\n", url, err)
-		return nil
+		log.Logger.Error("report outbox requeue error", log.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"msg": err.Error(), "requeued": n})
+		return
 	}
-	return decode
+	ctx.JSON(http.StatusOK, gin.H{"requeued": n})
 }
@@ -0,0 +1,39 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"errors"
+
+	"github.com/web3password/jewel/encode"
+)
+
+// frameHeaderLength is the version and the length that start a BSON frame.
+// jewel slices them before checking the length, a shorter body panics.
+const frameHeaderLength = 2 + 4
+
+var errShortFrame = errors.New("bson frame is too short")
+
+// decodeRequest decodes a BSON request frame, refusing the ones too short to decode.
+func decodeRequest(body []byte) (*encode.Web3PasswordRequestBsonStruct, error) {
+	if len(body) < frameHeaderLength {
+		return nil, errShortFrame
+	}
+	return encode.Web3PasswordRequestBsonDecode(body)
+}
+
+// decodeResponse decodes a BSON response frame, refusing the ones too short to decode.
+func decodeResponse(body []byte) (*encode.Web3PasswordResponseBsonStruct, error) {
+	if len(body) < frameHeaderLength {
+		return nil, errShortFrame
+	}
+	return encode.Web3PasswordResponseBsonDecode(body)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/idempotency"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/service/handlers"
+	"github.com/web3password/satis/util"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyKeyParam       = "idempotency_key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// idempotentRoutes accept an idempotency key, more are added by idempotency.routes.
+var idempotentRoutes = map[string]bool{
+	"/web3password/addCredential":         true,
+	"/web3password/batchAddCredential":    true,
+	"/web3password/vip/createOrder":       true,
+	"/web3password/sharefolder/addmember": true,
+}
+
+func isIdempotentRoute(conf config.Idempotency, path string) bool {
+	if idempotentRoutes[path] {
+		return true
+	}
+	for _, route := range conf.Routes {
+		if route == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Idempotency replays the stored response of a request to the retries of the
+// same signed payload under the same key, the key being scoped to the route
+// and the address. Another payload under the key is a conflict. Unsigned
+// requests and system errors are never stored, the latter so a retry runs again.
+func Idempotency() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		conf := config.GetConfig().Idempotency
+		store := idempotency.GetStore()
+		route := ctx.Request.URL.Path
+		if !conf.Enable || store == nil || !isIdempotentRoute(conf, route) {
+			ctx.Next()
+			return
+		}
+		ensureTraceID(ctx)
+		traceID := ctx.GetString("trace_id")
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			ctx.Next()
+			return
+		}
+		request, err := decodeRequest(body)
+		if err != nil {
+			ctx.Next()
+			return
+		}
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			key = jsoniter.Get([]byte(request.ParamsStr), IdempotencyKeyParam).ToString()
+		}
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > util.W3PMaxNonceLength {
+			handlers.ResponseError(ctx, model.NewError(model.CodeParams, "invalid idempotency key"))
+			ctx.Abort()
+			return
+		}
+		addr := jsoniter.Get([]byte(request.ParamsStr), "addr").ToString()
+		if !util.CheckSignature(addr, request.SignatureStr, request.ParamsStr) {
+			// the handler refuses it, nobody may hold a key of addr without its signature
+			ctx.Next()
+			return
+		}
+
+		storeKey := idempotencyStoreKey(route, addr, key)
+		now := time.Now()
+		rec := &idempotency.Record{
+			Fingerprint: idempotencyFingerprint(request),
+			State:       idempotency.StatePending,
+			CreatedAt:   now.Unix(),
+			ExpireAt:    now.Add(idempotency.PendingTTL).Unix(),
+		}
+		held, err := store.Reserve(storeKey, rec)
+		if err != nil {
+			log.Logger.Error("idempotency reserve error", log.String("route", route), log.String("trace_id", traceID), log.Error(err))
+			ctx.Next()
+			return
+		}
+		if held != nil {
+			replayIdempotent(ctx, held, rec.Fingerprint)
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		if !storableResponse(writer.Status(), writer.body.Bytes()) {
+			if err := store.Release(storeKey); err != nil {
+				log.Logger.Error("idempotency release error", log.String("route", route), log.String("trace_id", traceID), log.Error(err))
+			}
+			return
+		}
+		rec.State = idempotency.StateDone
+		rec.Status = writer.Status()
+		rec.ContentType = writer.Header().Get("Content-Type")
+		rec.Body = writer.body.Bytes()
+		rec.ExpireAt = time.Now().Add(time.Duration(conf.TTL) * time.Second).Unix()
+		if err := store.Save(storeKey, rec); err != nil {
+			log.Logger.Error("idempotency save error", log.String("route", route), log.String("trace_id", traceID), log.Error(err))
+			return
+		}
+		log.Logger.Info("idempotency response stored", log.String("route", route), log.String("trace_id", traceID))
+	}
+}
+
+// replayIdempotent answers a request whose key is held by rec.
+func replayIdempotent(ctx *gin.Context, rec *idempotency.Record, fingerprint string) {
+	route, traceID := ctx.Request.URL.Path, ctx.GetString("trace_id")
+	switch {
+	case rec.Fingerprint != fingerprint:
+		log.Logger.Warn("idempotency key conflict", log.String("route", route), log.String("trace_id", traceID))
+		handlers.ResponseError(ctx, model.NewError(model.CodeIdempotencyConflict, ""))
+	case rec.State != idempotency.StateDone:
+		log.Logger.Warn("idempotency key in progress", log.String("route", route), log.String("trace_id", traceID))
+		handlers.ResponseError(ctx, model.NewError(model.CodeIdempotencyInProgress, ""))
+	default:
+		log.Logger.Info("idempotency response replayed", log.String("route", route), log.String("trace_id", traceID), log.Any("created_at", rec.CreatedAt))
+		if rec.ContentType != "" {
+			ctx.Header("Content-Type", rec.ContentType)
+		}
+		if handlers.IsHttpWithTraceID() {
+			ctx.Header("X-Trace-id", traceID)
+		}
+		ctx.Header(IdempotencyReplayedHeader, "true")
+		ctx.Status(rec.Status)
+		_, _ = ctx.Writer.Write(rec.Body)
+	}
+	ctx.Abort()
+}
+
+// storableResponse reports whether a response is final, system errors and
+// responses that are not BSON are not.
+func storableResponse(status int, body []byte) bool {
+	if status != http.StatusOK {
+		return false
+	}
+	ret, err := decodeResponse(body)
+	if err != nil {
+		return false
+	}
+	if c, ok := model.LookupCode(int32(ret.Code)); ok && c.Retryable {
+		return false
+	}
+	return ret.Code <= model.StatusSystemErrorCode
+}
+
+func idempotencyStoreKey(route, addr, key string) string {
+	sum := sha256.Sum256([]byte(route + "\n" + strings.ToLower(addr) + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyFingerprint covers what the client signs, the params and the data.
+func idempotencyFingerprint(request *encode.Web3PasswordRequestBsonStruct) string {
+	h := sha256.New()
+	h.Write([]byte(request.ParamsStr))
+	h.Write([]byte{0})
+	h.Write(request.AppendData)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response body.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
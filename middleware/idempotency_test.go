@@ -0,0 +1,222 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/client"
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/idempotency"
+	"github.com/web3password/satis/middleware"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/service/handlers"
+)
+
+const idempotentRoute = "/web3password/addCredential"
+
+// idempotencyServer serves idempotentRoute behind the Idempotency middleware,
+// answering with the code of reply and counting the calls of the handler.
+type idempotencyServer struct {
+	engine  *gin.Engine
+	calls   atomic.Int32
+	reply   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+	signer  client.Signer
+}
+
+func newIdempotencyServer(t *testing.T, store idempotency.Store) *idempotencyServer {
+	t.Helper()
+	loadConfig(t, consts.RunningModeLocal, "idempotency:\n  enable: true")
+	idempotency.SetStore(store)
+	t.Cleanup(func() { idempotency.SetStore(nil) })
+	signer, err := client.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &idempotencyServer{engine: gin.New(), signer: signer}
+	s.reply.Store(model.StatusOK)
+	s.engine.Use(middleware.Idempotency())
+	s.engine.POST(idempotentRoute, func(ctx *gin.Context) {
+		s.calls.Add(1)
+		if s.started != nil {
+			s.started <- struct{}{}
+			<-s.release
+		}
+		handlers.Response(ctx, int(s.reply.Load()), "reply", []byte("data"))
+	})
+	return s
+}
+
+// body signs params with the signer of the server.
+func (s *idempotencyServer) body(t *testing.T, params map[string]any, data []byte) []byte {
+	t.Helper()
+	c := client.New("", s.signer)
+	route, _ := client.LookupRoute(idempotentRoute)
+	b, err := json.Marshal(c.Params(route, params, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign, err := s.signer.Sign(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := encode.Web3PasswordRequestBsonEncode(sign, string(b), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// post sends body under key and returns the recorded response and its code.
+func (s *idempotencyServer) post(t *testing.T, key string, body []byte) (*httptest.ResponseRecorder, int) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, idempotentRoute, bytes.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	s.engine.ServeHTTP(rec, req)
+	ret, err := encode.Web3PasswordResponseBsonDecode(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("response %q: %v", rec.Body.Bytes(), err)
+	}
+	return rec, ret.Code
+}
+
+// idempotencyStores runs a test against the memory and the dir store.
+func idempotencyStores(t *testing.T, test func(t *testing.T, s *idempotencyServer)) {
+	gin.SetMode(gin.ReleaseMode)
+	for name, open := range map[string]func(t *testing.T) idempotency.Store{
+		"memory": func(*testing.T) idempotency.Store { return idempotency.NewMemoryStore() },
+		"dir": func(t *testing.T) idempotency.Store {
+			store, err := idempotency.NewDirStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			test(t, newIdempotencyServer(t, open(t)))
+		})
+	}
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	idempotencyStores(t, func(t *testing.T, s *idempotencyServer) {
+		body := s.body(t, map[string]any{"id": "cred-1"}, []byte("credential"))
+		first, code := s.post(t, "key-1", body)
+		if code != model.StatusOK || first.Header().Get(middleware.IdempotencyReplayedHeader) != "" {
+			t.Fatalf("first request answered %d", code)
+		}
+		replay, code := s.post(t, "key-1", body)
+		if code != model.StatusOK || replay.Header().Get(middleware.IdempotencyReplayedHeader) != "true" {
+			t.Fatalf("retry answered %d, replayed %q", code, replay.Header().Get(middleware.IdempotencyReplayedHeader))
+		}
+		if !bytes.Equal(replay.Body.Bytes(), first.Body.Bytes()) || s.calls.Load() != 1 {
+			t.Fatalf("retry ran the handler again, %d calls", s.calls.Load())
+		}
+
+		// the key in params works as the header does
+		body = s.body(t, map[string]any{"id": "cred-2", middleware.IdempotencyKeyParam: "key-2"}, []byte("credential"))
+		s.post(t, "", body)
+		if _, code := s.post(t, "", body); code != model.StatusOK || s.calls.Load() != 2 {
+			t.Fatalf("retry with the key in params answered %d, %d calls", code, s.calls.Load())
+		}
+
+		// without a key every request runs
+		body = s.body(t, map[string]any{"id": "cred-3"}, []byte("credential"))
+		s.post(t, "", body)
+		s.post(t, "", body)
+		if s.calls.Load() != 4 {
+			t.Fatalf("requests without a key: %d calls, want 4", s.calls.Load())
+		}
+	})
+}
+
+func TestIdempotencyConflict(t *testing.T) {
+	idempotencyStores(t, func(t *testing.T, s *idempotencyServer) {
+		s.post(t, "key-1", s.body(t, map[string]any{"id": "cred-1"}, []byte("credential")))
+		for name, body := range map[string][]byte{
+			"params": s.body(t, map[string]any{"id": "cred-2"}, []byte("credential")),
+			"data":   s.body(t, map[string]any{"id": "cred-1"}, []byte("another credential")),
+		} {
+			rec, code := s.post(t, "key-1", body)
+			if code != model.StatusIdempotencyConflict || rec.Header().Get("X-Error-Key") != model.CodeIdempotencyConflict.Key {
+				t.Errorf("another %s under the key answered %d", name, code)
+			}
+		}
+		if s.calls.Load() != 1 {
+			t.Fatalf("conflicts ran the handler, %d calls", s.calls.Load())
+		}
+
+		if _, code := s.post(t, strings.Repeat("k", 200), s.body(t, map[string]any{"id": "cred-3"}, nil)); code != model.StatusParamsErr {
+			t.Fatalf("a too long key answered %d", code)
+		}
+	})
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	idempotencyStores(t, func(t *testing.T, s *idempotencyServer) {
+		s.started, s.release = make(chan struct{}), make(chan struct{})
+		body := s.body(t, map[string]any{"id": "cred-1"}, []byte("credential"))
+		done := make(chan int)
+		go func() {
+			_, code := s.post(t, "key-1", body)
+			done <- code
+		}()
+		<-s.started
+		if _, code := s.post(t, "key-1", body); code != model.StatusIdempotencyInProgress {
+			t.Errorf("retry during the first request answered %d", code)
+		}
+		close(s.release)
+		if code := <-done; code != model.StatusOK {
+			t.Fatalf("first request answered %d", code)
+		}
+		s.started = nil
+		if rec, _ := s.post(t, "key-1", body); rec.Header().Get(middleware.IdempotencyReplayedHeader) != "true" {
+			t.Fatal("retry after the first request was not replayed")
+		}
+	})
+}
+
+func TestIdempotencyReleasesSystemErrors(t *testing.T) {
+	idempotencyStores(t, func(t *testing.T, s *idempotencyServer) {
+		body := s.body(t, map[string]any{"id": "cred-1"}, []byte("credential"))
+		for _, code := range []int32{model.StatusSystemError, model.StatusServiceCheckErr} {
+			s.reply.Store(code)
+			if _, got := s.post(t, "key-1", body); got != int(code) {
+				t.Fatalf("answered %d, want %d", got, code)
+			}
+		}
+		// a final refusal is stored like a success
+		s.reply.Store(model.StatusParamsErr)
+		s.post(t, "key-1", body)
+		s.reply.Store(model.StatusOK)
+		rec, code := s.post(t, "key-1", body)
+		if code != model.StatusParamsErr || rec.Header().Get(middleware.IdempotencyReplayedHeader) != "true" {
+			t.Fatalf("retry of a refusal answered %d", code)
+		}
+		if s.calls.Load() != 3 {
+			t.Fatalf("%d calls, want the two system errors and the refusal", s.calls.Load())
+		}
+	})
+}
@@ -0,0 +1,40 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+)
+
+// OpsAuth admits the requests bearing ops.token. Without a token the ops
+// endpoints are disabled and answer 404, as if they did not exist.
+func OpsAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := config.GetConfig().Ops.Token
+		if token == "" {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		got := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			log.Logger.Warn("ops request unauthorized", log.String("uri", ctx.Request.URL.Path), log.String("remote", ctx.ClientIP()))
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/service"
+)
+
+func TestOpsEndpointsNeedToken(t *testing.T) {
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/satis/metrics"},
+		{http.MethodGet, "/satis/outbox/status"},
+		{http.MethodPost, "/satis/outbox/requeue"},
+	}
+	serve := func(method, path, auth string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		service.Routers().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	loadConfig(t, consts.RunningModeLocal)
+	for _, r := range routes {
+		if code := serve(r.method, r.path, "Bearer "); code != http.StatusNotFound {
+			t.Errorf("%s without ops.token: status %d, want 404", r.path, code)
+		}
+	}
+
+	loadConfig(t, consts.RunningModeLocal, "ops:\n  token: s3cret\n")
+	for _, r := range routes {
+		for _, auth := range []string{"", "Bearer wrong", "s3cret x"} {
+			if code := serve(r.method, r.path, auth); code != http.StatusUnauthorized {
+				t.Errorf("%s with %q: status %d, want 401", r.path, auth, code)
+			}
+		}
+	}
+	if code := serve(http.MethodGet, "/satis/metrics", "Bearer s3cret"); code != http.StatusOK {
+		t.Errorf("metrics with the token: status %d, want 200", code)
+	}
+}
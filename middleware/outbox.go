@@ -0,0 +1,391 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+)
+
+const (
+	outboxPendingDir      = "pending"
+	outboxFailedDir       = "failed"
+	outboxDefaultAttempts = 20
+	outboxBaseBackoff     = 2 * time.Second
+	outboxMaxBackoff      = 10 * time.Minute
+	outboxScanInterval    = time.Second
+)
+
+var errReportRejected = errors.New("report rejected by official domain")
+
+// ReportEvent is a signed request waiting to be reported to the official domain.
+type ReportEvent struct {
+	ID        string `json:"id"`  // idempotency key
+	Seq       uint64 `json:"seq"` // enqueue order
+	URI       string `json:"uri"`
+	Body      []byte `json:"body"`
+	TraceID   string `json:"trace_id"`
+	CreatedAt int64  `json:"created_at"`
+	Attempts  int    `json:"attempts"`
+	NextAt    int64  `json:"next_at"`
+	LastError string `json:"last_error"`
+}
+
+// OutboxStatus is the delivery status of the report outbox.
+type OutboxStatus struct {
+	Pending         int    `json:"pending"`
+	Failed          int    `json:"failed"`
+	Delivered       int64  `json:"delivered"`
+	Attempts        int64  `json:"attempts"`
+	LastError       string `json:"last_error"`
+	LastDeliveredAt int64  `json:"last_delivered_at"`
+}
+
+// Outbox persists report events on disk and delivers them in enqueue order
+// with exponential backoff, an event waiting for a retry holds back the later
+// ones. Events are never dropped, events that exhaust their attempts are
+// parked under failed/ until requeued.
+type Outbox struct {
+	dir         string
+	maxAttempts int
+	notify      chan struct{}
+	seq         uint64 // last assigned Seq, guarded by lock
+
+	lock   sync.Mutex
+	status OutboxStatus
+}
+
+var reportOutbox *Outbox
+
+// InitOutbox opens the report outbox and starts its delivery loop.
+func InitOutbox(conf *config.Config) {
+	dir := conf.ReportOutbox.Dir
+	if dir == "" {
+		dir = filepath.Join(conf.LogDir, "report_outbox")
+	}
+	o, err := NewOutbox(dir, conf.ReportOutbox.MaxAttempts)
+	if err != nil {
+		log.Fatalf("failed to open report outbox dir:%s err:%+v", dir, err)
+	}
+	reportOutbox = o
+	go o.Run()
+}
+
+// GetOutbox returns the report outbox, nil when it is not enabled.
+func GetOutbox() *Outbox {
+	return reportOutbox
+}
+
+// NewOutbox .
+func NewOutbox(dir string, maxAttempts int) (*Outbox, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = outboxDefaultAttempts
+	}
+	for _, sub := range []string{outboxPendingDir, outboxFailedDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, err
+		}
+	}
+	o := &Outbox{
+		dir:         dir,
+		maxAttempts: maxAttempts,
+		notify:      make(chan struct{}, 1),
+	}
+	for _, sub := range []string{outboxPendingDir, outboxFailedDir} {
+		for _, event := range o.events(sub) {
+			if event.Seq > o.seq {
+				o.seq = event.Seq
+			}
+		}
+	}
+	return o, nil
+}
+
+// Enqueue durably stores a report event. The same uri and body always map to
+// the same idempotency key, so a replayed client request is only reported once.
+func (o *Outbox) Enqueue(uri, traceID string, body []byte) (*ReportEvent, error) {
+	sum := sha256.Sum256(append([]byte(uri+"\n"), body...))
+	event := &ReportEvent{
+		ID:        hex.EncodeToString(sum[:]),
+		URI:       uri,
+		Body:      body,
+		TraceID:   traceID,
+		CreatedAt: time.Now().Unix(),
+	}
+	path := o.path(outboxPendingDir, event.ID)
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if _, err := os.Stat(path); err == nil {
+		return event, nil
+	}
+	event.Seq = o.seq + 1
+	if err := o.write(path, event); err != nil {
+		return nil, err
+	}
+	o.seq = event.Seq
+	o.wake()
+	return event, nil
+}
+
+// Requeue moves the failed event id, or every failed event when id is empty,
+// back to pending with its attempts reset. The events keep their Seq, so they
+// are delivered ahead of the ones enqueued after them.
+func (o *Outbox) Requeue(id string) (int, error) {
+	var events []*ReportEvent
+	if id == "" {
+		events = o.events(outboxFailedDir)
+	} else {
+		if _, err := hex.DecodeString(id); err != nil || len(id) != sha256.Size*2 {
+			return 0, os.ErrNotExist
+		}
+		event, err := o.read(o.path(outboxFailedDir, id))
+		if err != nil {
+			return 0, err
+		}
+		events = append(events, event)
+	}
+	n := 0
+	for _, event := range events {
+		event.Attempts, event.NextAt, event.LastError = 0, 0, ""
+		if err := o.write(o.path(outboxPendingDir, event.ID), event); err != nil {
+			return n, err
+		}
+		if err := os.Remove(o.path(outboxFailedDir, event.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return n, err
+		}
+		log.Logger.Info("report outbox event requeued", log.String("id", event.ID), log.String("uri", event.URI), log.String("trace_id", event.TraceID))
+		n++
+	}
+	if n > 0 {
+		o.wake()
+	}
+	return n, nil
+}
+
+func (o *Outbox) wake() {
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Status returns the current delivery status.
+func (o *Outbox) Status() OutboxStatus {
+	o.lock.Lock()
+	status := o.status
+	o.lock.Unlock()
+	status.Pending = o.count(outboxPendingDir)
+	status.Failed = o.count(outboxFailedDir)
+	return status
+}
+
+// Run delivers pending events until the process exits.
+func (o *Outbox) Run() {
+	ticker := time.NewTicker(outboxScanInterval)
+	defer ticker.Stop()
+	for {
+		o.deliverDue()
+		select {
+		case <-ticker.C:
+		case <-o.notify:
+		}
+	}
+}
+
+// deliverDue delivers the pending events in order, stopping at the first one
+// that is not due or is left for a retry.
+func (o *Outbox) deliverDue() {
+	now := time.Now().Unix()
+	for _, event := range o.events(outboxPendingDir) {
+		if event.NextAt > now {
+			return
+		}
+		if !o.attempt(o.path(outboxPendingDir, event.ID), event) {
+			return
+		}
+	}
+}
+
+// events reads the events under sub, oldest first. Events written before Seq
+// existed sort first, by their creation time.
+func (o *Outbox) events(sub string) []*ReportEvent {
+	entries, err := os.ReadDir(filepath.Join(o.dir, sub))
+	if err != nil {
+		log.Logger.Error("report outbox read dir error", log.String("dir", sub), log.Error(err))
+		return nil
+	}
+	events := make([]*ReportEvent, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		event, err := o.read(filepath.Join(o.dir, sub, entry.Name()))
+		if err != nil {
+			log.Logger.Error("report outbox read event error", log.String("file", entry.Name()), log.Error(err))
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.Seq != b.Seq {
+			return a.Seq < b.Seq
+		}
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.ID < b.ID
+	})
+	return events
+}
+
+// attempt delivers event and reports whether it left the pending queue,
+// delivered or parked as failed.
+func (o *Outbox) attempt(path string, event *ReportEvent) bool {
+	event.Attempts++
+	err := o.deliver(event)
+	o.lock.Lock()
+	o.status.Attempts++
+	if err == nil {
+		o.status.Delivered++
+		o.status.LastDeliveredAt = time.Now().Unix()
+	} else {
+		o.status.LastError = err.Error()
+	}
+	o.lock.Unlock()
+
+	if err == nil {
+		log.Logger.Info("report outbox delivered", log.String("id", event.ID), log.String("uri", event.URI), log.String("trace_id", event.TraceID), log.Any("attempts", event.Attempts))
+		if err := os.Remove(path); err != nil {
+			log.Logger.Error("report outbox remove event error", log.String("id", event.ID), log.Error(err))
+			return false
+		}
+		return true
+	}
+
+	event.LastError = err.Error()
+	if errors.Is(err, errReportRejected) || event.Attempts >= o.maxAttempts {
+		log.Logger.Error("report outbox event failed", log.String("id", event.ID), log.String("uri", event.URI), log.String("trace_id", event.TraceID), log.Any("attempts", event.Attempts), log.Error(err))
+		if err := o.write(o.path(outboxFailedDir, event.ID), event); err != nil {
+			log.Logger.Error("report outbox park event error", log.String("id", event.ID), log.Error(err))
+			return false
+		}
+		return os.Remove(path) == nil
+	}
+
+	backoff := outboxBaseBackoff << uint(event.Attempts-1)
+	if backoff <= 0 || backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	event.NextAt = time.Now().Add(backoff).Unix()
+	log.Logger.Warn("report outbox deliver retry", log.String("id", event.ID), log.String("uri", event.URI), log.String("trace_id", event.TraceID), log.Any("attempts", event.Attempts), log.Any("backoff", backoff.String()), log.Error(err))
+	if err := o.write(path, event); err != nil {
+		log.Logger.Error("report outbox update event error", log.String("id", event.ID), log.Error(err))
+	}
+	return false
+}
+
+func (o *Outbox) deliver(event *ReportEvent) error {
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Idempotency-Key", event.ID)
+	header.Set("X-Trace-id", event.TraceID)
+	rsp, err := currentUpstreamPool().Do(http.MethodPost, event.URI, event.Body, header, true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rsp.Body.Close() }()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("official domain status code %d", rsp.StatusCode)
+	}
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	ret, err := decodeResponse(body)
+	if err != nil {
+		return err
+	}
+	if ret.Code == model.StatusOK {
+		return nil
+	}
+	if ret.Code > model.StatusSystemErrorCode {
+		return fmt.Errorf("official domain code %d msg %s", ret.Code, ret.Msg)
+	}
+	return fmt.Errorf("%w: code %d msg %s", errReportRejected, ret.Code, ret.Msg)
+}
+
+func (o *Outbox) path(sub, id string) string {
+	return filepath.Join(o.dir, sub, id+".json")
+}
+
+// write replaces the event file atomically so a crash never leaves a torn event.
+func (o *Outbox) write(path string, event *ReportEvent) error {
+	data, err := jsoniter.Marshal(event)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (o *Outbox) read(path string) (*ReportEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	event := &ReportEvent{}
+	if err := jsoniter.Unmarshal(data, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (o *Outbox) count(sub string) int {
+	entries, err := os.ReadDir(filepath.Join(o.dir, sub))
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".json" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,141 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/web3password/satis/config"
+)
+
+func TestOutboxOrderAndRequeue(t *testing.T) {
+	dir := t.TempDir()
+	o, err := NewOutbox(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for i := 0; i < 20; i++ {
+		event, err := o.Enqueue("/web3password/admin/addMember", "trace", []byte(fmt.Sprintf("body %d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, event.ID)
+	}
+	if _, err := o.Enqueue("/web3password/admin/addMember", "trace", []byte("body 0")); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder := func(sub string, want []string) {
+		t.Helper()
+		events := o.events(sub)
+		if len(events) != len(want) {
+			t.Fatalf("%s: %d events, want %d", sub, len(events), len(want))
+		}
+		for i, event := range events {
+			if event.ID != want[i] {
+				t.Fatalf("%s: event %d is %s, want %s", sub, i, event.ID, want[i])
+			}
+		}
+	}
+	assertOrder(outboxPendingDir, ids)
+
+	// park the first two as failed, a reopened outbox continues the sequence
+	for _, id := range ids[:2] {
+		event, err := o.read(o.path(outboxPendingDir, id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		event.Attempts = 3
+		if err := o.write(o.path(outboxFailedDir, id), event); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(o.path(outboxPendingDir, id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	o, err = NewOutbox(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := o.Enqueue("/web3password/admin/removeMember", "trace", []byte("last"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Seq != uint64(len(ids)+1) {
+		t.Fatalf("seq %d after reopen, want %d", last.Seq, len(ids)+1)
+	}
+
+	if _, err := o.Requeue("../pending/x"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("requeue of a bad id: %v", err)
+	}
+	if n, err := o.Requeue(ids[1]); err != nil || n != 1 {
+		t.Fatalf("requeue one: %d %v", n, err)
+	}
+	if n, err := o.Requeue(""); err != nil || n != 1 {
+		t.Fatalf("requeue all: %d %v", n, err)
+	}
+	assertOrder(outboxFailedDir, nil)
+	assertOrder(outboxPendingDir, append(append([]string{}, ids...), last.ID))
+	for _, event := range o.events(outboxPendingDir) {
+		if event.Attempts != 0 {
+			t.Fatalf("event %s kept %d attempts", event.ID, event.Attempts)
+		}
+	}
+}
+
+// useOfficialDomain makes url the only official domain of the current config.
+func useOfficialDomain(t *testing.T, url string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(`running_mode: local
+official_domains: [%s]
+node:
+  token: test
+log_dir: %s
+http_server:
+  port: "8080"
+server:
+  port: "8081"
+`, url, t.TempDir())
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.ParseConfig(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxDeliverShortBody(t *testing.T) {
+	for _, body := range []string{"", "\x00", "\x00\x01\x00\x00\x00"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		useOfficialDomain(t, server.URL)
+		o, err := NewOutbox(t.TempDir(), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event, err := o.Enqueue("/web3password/admin/addMember", "trace", []byte("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := o.deliver(event); !errors.Is(err, errShortFrame) {
+			t.Errorf("deliver of a %d bytes response: %v, want %v", len(body), err, errShortFrame)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/log"
+)
+
+// localRoutes is the built-in policy of local mode, everything else is proxied.
+// The batch credential routes stay proxied, as they were before the table.
+var localRoutes = map[string]string{
+	"/web3password/getLatestBlockTimestamp":   consts.RouteActionLocal,
+	"/web3password/checkTx":                   consts.RouteActionLocal,
+	"/web3password/addCredential":             consts.RouteActionLocal,
+	"/web3password/getCredential":             consts.RouteActionLocal,
+	"/web3password/deleteCredential":          consts.RouteActionLocal,
+	"/web3password/deleteAllCredential":       consts.RouteActionLocal,
+	"/web3password/getAllCredentialTimestamp": consts.RouteActionLocal,
+	"/web3password/getCredentialList":         consts.RouteActionLocal,
+	"/web3password/syncCredentials":           consts.RouteActionLocal,
+	"/web3password/session":                   consts.RouteActionLocal,
+	"/web3password/transaction":               consts.RouteActionLocal,
+	"/web3password/events":                    consts.RouteActionLocal,
+
+	"/web3password/vault/export":       consts.RouteActionLocal,
+	"/web3password/vault/import":       consts.RouteActionLocal,
+	"/web3password/vault/importStatus": consts.RouteActionLocal,
+
+	"/web3password/admin/authorization":         consts.RouteActionLocal,
+	"/web3password/admin/addMember":             consts.RouteActionLocalReport,
+	"/web3password/admin/batchImportMember":     consts.RouteActionLocal,
+	"/web3password/admin/updateMember":          consts.RouteActionLocal,
+	"/web3password/admin/removeMember":          consts.RouteActionLocalReport,
+	"/web3password/admin/transferSuperAdmin":    consts.RouteActionLocalReport,
+	"/web3password/admin/getMemberList":         consts.RouteActionLocal,
+	"/web3password/admin/getOrgInfo":            consts.RouteActionLocal,
+	"/web3password/admin/updateOrgInfo":         consts.RouteActionLocal,
+	"/web3password/admin/operationHistory":      consts.RouteActionLocal,
+	"/web3password/admin/getAdminShareMnemonic": consts.RouteActionLocal,
+	"/web3password/vip/register":                consts.RouteActionLocalReport,
+
+	"/web3password/file/upload":       consts.RouteActionLocal,
+	"/web3password/file/uploadIocopy": consts.RouteActionLocal,
+	"/web3password/file/uploadBufio":  consts.RouteActionLocal,
+	"/web3password/file/download":     consts.RouteActionLocal,
+	"/web3password/file/attachment":   consts.RouteActionLocal,
+	"/web3password/file/report":       consts.RouteActionLocal,
+
+	"/web3password/sharefolder/create":          consts.RouteActionLocal,
+	"/web3password/sharefolder/update":          consts.RouteActionLocal,
+	"/web3password/sharefolder/destroy":         consts.RouteActionLocal,
+	"/web3password/sharefolder/addrecord":       consts.RouteActionLocal,
+	"/web3password/sharefolder/deleterecord":    consts.RouteActionLocal,
+	"/web3password/sharefolder/addmember":       consts.RouteActionLocal,
+	"/web3password/sharefolder/updatemember":    consts.RouteActionLocal,
+	"/web3password/sharefolder/memberlist":      consts.RouteActionLocal,
+	"/web3password/sharefolder/memberexit":      consts.RouteActionLocal,
+	"/web3password/sharefolder/deletemember":    consts.RouteActionLocal,
+	"/web3password/sharefolder/batchUpdate":     consts.RouteActionLocal,
+	"/web3password/sharefolder/folderlist":      consts.RouteActionLocal,
+	"/web3password/sharefolder/recordlist":      consts.RouteActionLocal,
+	"/web3password/sharefolder/recordlistbyrid": consts.RouteActionLocal,
+}
+
+// readOnlyRoutes do not change any state, so they are safe to retry on another official domain.
+var readOnlyRoutes = map[string]bool{
+	"/web3password/getPersonalSignAddress":    true,
+	"/web3password/getVipInfo":                true,
+	"/web3password/userInfo":                  true,
+	"/web3password/getLatestBlockTimestamp":   true,
+	"/web3password/checkTx":                   true,
+	"/web3password/batchCheckTx":              true,
+	"/web3password/getCredential":             true,
+	"/web3password/getAllCredentialTimestamp": true,
+	"/web3password/getCredentialList":         true,
+	"/web3password/syncCredentials":           true,
+	"/web3password/storageStat":               true,
+	"/web3password/getVersionConfig":          true,
+	"/web3password/events":                    true,
+
+	"/web3password/vault/export":       true,
+	"/web3password/vault/importStatus": true,
+
+	"/web3password/admin/getMemberList":         true,
+	"/web3password/admin/getOrgInfo":            true,
+	"/web3password/admin/operationHistory":      true,
+	"/web3password/admin/getAdminShareMnemonic": true,
+
+	"/web3password/file/download":   true,
+	"/web3password/file/attachment": true,
+
+	"/web3password/sharefolder/memberlist":      true,
+	"/web3password/sharefolder/folderlist":      true,
+	"/web3password/sharefolder/recordlist":      true,
+	"/web3password/sharefolder/recordlistbyrid": true,
+
+	"/web3password/vip/getConfig":        true,
+	"/web3password/vip/subscriptionList": true,
+	"/web3password/vip/checkOrder":       true,
+	"/web3password/vip/paymentList":      true,
+	"/web3password/vip/discount":         true,
+	"/web3password/vip/getOrderList":     true,
+	"/web3password/vip/price":            true,
+}
+
+// IsReadOnlyRoute reports whether the route is safe to retry.
+func IsReadOnlyRoute(path string) bool {
+	return readOnlyRoutes[path]
+}
+
+// RoutePolicy is a compiled route policy table.
+type RoutePolicy struct {
+	defaultAction string
+	routes        map[string]string
+	timeouts      map[string]time.Duration
+}
+
+// Action returns the action for an exact request path.
+func (p *RoutePolicy) Action(path string) string {
+	if action, ok := p.routes[path]; ok {
+		return action
+	}
+	return p.defaultAction
+}
+
+// Timeout returns the proxy timeout of the route, zero when it uses the upstream default.
+func (p *RoutePolicy) Timeout(path string) time.Duration {
+	return p.timeouts[path]
+}
+
+// CompileRoutePolicy merges the configured rules over the built-in table of the running mode.
+func CompileRoutePolicy(runningMode string, conf config.RoutePolicy) *RoutePolicy {
+	p := &RoutePolicy{
+		defaultAction: consts.RouteActionProxy,
+		routes:        make(map[string]string),
+		timeouts:      make(map[string]time.Duration),
+	}
+	if runningMode == consts.RunningModeLocal {
+		for path, action := range localRoutes {
+			p.routes[path] = action
+		}
+	}
+	if conf.Default != "" {
+		if isRouteAction(conf.Default) {
+			p.defaultAction = conf.Default
+		} else {
+			log.Logger.Warn("route policy invalid default action", log.String("action", conf.Default))
+		}
+	}
+	for _, rule := range conf.Routes {
+		if rule.Path == "" || !isRouteAction(rule.Action) {
+			log.Logger.Warn("route policy invalid rule", log.String("path", rule.Path), log.String("action", rule.Action))
+			continue
+		}
+		p.routes[rule.Path] = rule.Action
+		if rule.Timeout > 0 {
+			p.timeouts[rule.Path] = time.Duration(rule.Timeout) * time.Second
+		}
+	}
+	return p
+}
+
+func isRouteAction(action string) bool {
+	switch action {
+	case consts.RouteActionLocal, consts.RouteActionProxy, consts.RouteActionLocalReport, consts.RouteActionDeny:
+		return true
+	}
+	return false
+}
+
+var (
+	policyLock   sync.Mutex
+	policyConfig *config.Config
+	policy       *RoutePolicy
+)
+
+// currentRoutePolicy returns the policy compiled from the current config,
+// recompiling it whenever the config has been reloaded.
+func currentRoutePolicy(runningMode string) *RoutePolicy {
+	conf := config.GetConfig()
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	if policy == nil || policyConfig != conf {
+		policy = CompileRoutePolicy(runningMode, conf.RoutePolicy)
+		policyConfig = conf
+		log.Logger.Info("route policy compiled", log.String("running_mode", runningMode), log.Any("routes", len(policy.routes)), log.String("default", policy.defaultAction))
+	}
+	return policy
+}
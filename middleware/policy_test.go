@@ -0,0 +1,218 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/middleware"
+	"github.com/web3password/satis/service"
+)
+
+const (
+	local       = consts.RouteActionLocal
+	proxy       = consts.RouteActionProxy
+	localReport = consts.RouteActionLocalReport
+)
+
+// localModeActions is the action of every route of service.Routers in local mode.
+var localModeActions = map[string]string{
+	"/web3password/userRegister":              proxy,
+	"/web3password/getPersonalSignAddress":    proxy,
+	"/web3password/getVipInfo":                proxy,
+	"/web3password/userInfo":                  proxy,
+	"/web3password/getLatestBlockTimestamp":   local,
+	"/web3password/checkTx":                   local,
+	"/web3password/batchCheckTx":              proxy,
+	"/web3password/addCredential":             local,
+	"/web3password/batchAddCredential":        proxy,
+	"/web3password/getCredential":             local,
+	"/web3password/deleteCredential":          local,
+	"/web3password/batchDeleteCredential":     proxy,
+	"/web3password/deleteAllCredential":       local,
+	"/web3password/getAllCredentialTimestamp": local,
+	"/web3password/getCredentialList":         local,
+	"/web3password/syncCredentials":           local,
+	"/web3password/storageStat":               proxy,
+	"/web3password/getVersionConfig":          proxy,
+	"/web3password/session":                   local,
+	"/web3password/transaction":               local,
+	"/web3password/events":                    local,
+
+	"/web3password/vault/export":       local,
+	"/web3password/vault/import":       local,
+	"/web3password/vault/importStatus": local,
+
+	"/web3password/admin/authorization":         local,
+	"/web3password/admin/addMember":             localReport,
+	"/web3password/admin/batchImportMember":     local,
+	"/web3password/admin/updateMember":          local,
+	"/web3password/admin/removeMember":          localReport,
+	"/web3password/admin/transferSuperAdmin":    localReport,
+	"/web3password/admin/getMemberList":         local,
+	"/web3password/admin/getOrgInfo":            local,
+	"/web3password/admin/updateOrgInfo":         local,
+	"/web3password/admin/operationHistory":      local,
+	"/web3password/admin/getAdminShareMnemonic": local,
+
+	"/web3password/file/upload":       local,
+	"/web3password/file/uploadIocopy": local,
+	"/web3password/file/uploadBufio":  local,
+	"/web3password/file/download":     local,
+	"/web3password/file/attachment":   local,
+	"/web3password/file/report":       local,
+
+	"/web3password/sharefolder/create":          local,
+	"/web3password/sharefolder/update":          local,
+	"/web3password/sharefolder/destroy":         local,
+	"/web3password/sharefolder/addrecord":       local,
+	"/web3password/sharefolder/deleterecord":    local,
+	"/web3password/sharefolder/addmember":       local,
+	"/web3password/sharefolder/updatemember":    local,
+	"/web3password/sharefolder/memberlist":      local,
+	"/web3password/sharefolder/memberexit":      local,
+	"/web3password/sharefolder/deletemember":    local,
+	"/web3password/sharefolder/batchUpdate":     local,
+	"/web3password/sharefolder/folderlist":      local,
+	"/web3password/sharefolder/recordlist":      local,
+	"/web3password/sharefolder/recordlistbyrid": local,
+
+	"/web3password/vip/getConfig":                           proxy,
+	"/web3password/vip/subscriptionList":                    proxy,
+	"/web3password/vip/createOrder":                         proxy,
+	"/web3password/vip/checkOrder":                          proxy,
+	"/web3password/vip/apple/in-app-purchase/verifyReceipt": proxy,
+	"/web3password/vip/register":                            localReport,
+	"/web3password/vip/paymentList":                         proxy,
+	"/web3password/vip/discount":                            proxy,
+	"/web3password/vip/getOrderList":                        proxy,
+	"/web3password/vip/getVipIOSPromotionSign":              proxy,
+	"/web3password/vip/price":                               proxy,
+}
+
+// loadConfig makes a minimal config of mode, plus the extra yaml, the current one.
+func loadConfig(t *testing.T, mode string, extra ...string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(`running_mode: %s
+official_domains: [http://127.0.0.1:1]
+node:
+  token: test
+log_dir: %s
+http_server:
+  port: "8080"
+server:
+  port: "8081"
+`, mode, t.TempDir()) + strings.Join(extra, "\n")
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.ParseConfig(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// apiRoutes are the POST routes of the agent, not the satis own endpoints.
+func apiRoutes(t *testing.T) []string {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+	var paths []string
+	for _, r := range service.Routers().Routes() {
+		if r.Method == "POST" && strings.HasPrefix(r.Path, "/web3password/") {
+			paths = append(paths, r.Path)
+		}
+	}
+	return paths
+}
+
+func TestRoutePolicyCoversRouters(t *testing.T) {
+	loadConfig(t, consts.RunningModeLocal)
+	registered := make(map[string]bool)
+	for _, path := range apiRoutes(t) {
+		registered[path] = true
+		if _, ok := localModeActions[path]; !ok {
+			t.Errorf("route %s has no expected action", path)
+		}
+	}
+	for path := range localModeActions {
+		if !registered[path] {
+			t.Errorf("expected action for %s, which is not a route", path)
+		}
+	}
+}
+
+func TestRoutePolicyActions(t *testing.T) {
+	loadConfig(t, consts.RunningModeLocal)
+	paths := apiRoutes(t)
+	rules := config.RoutePolicy{Routes: []config.RouteRule{
+		{Path: "/web3password/vip/createOrder", Action: consts.RouteActionDeny},
+		{Path: "/web3password/file/download", Action: proxy, Timeout: 300},
+	}}
+	tests := []struct {
+		name     string
+		mode     string
+		conf     config.RoutePolicy
+		expected func(path string) string
+	}{
+		{"local", consts.RunningModeLocal, config.RoutePolicy{}, func(path string) string {
+			return localModeActions[path]
+		}},
+		{"audit proxies everything", consts.RunningModeAudit, config.RoutePolicy{}, func(string) string {
+			return proxy
+		}},
+		{"local with rules", consts.RunningModeLocal, rules, func(path string) string {
+			switch path {
+			case "/web3password/vip/createOrder":
+				return consts.RouteActionDeny
+			case "/web3password/file/download":
+				return proxy
+			}
+			return localModeActions[path]
+		}},
+		{"default action", consts.RunningModeAudit, config.RoutePolicy{Default: local}, func(string) string {
+			return local
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := middleware.CompileRoutePolicy(tt.mode, tt.conf)
+			for _, path := range paths {
+				if got, want := p.Action(path), tt.expected(path); got != want {
+					t.Errorf("%s: action %s, want %s", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRoutePolicyExactMatch(t *testing.T) {
+	p := middleware.CompileRoutePolicy(consts.RunningModeLocal, config.RoutePolicy{})
+	for _, path := range []string{
+		"/web3password/file/upload/extra",
+		"/web3password/getCredentialListX",
+		"/web3password/admin/register",
+		"/file",
+	} {
+		if got := p.Action(path); got != proxy {
+			t.Errorf("%s: action %s, want proxy", path, got)
+		}
+	}
+	if got := p.Timeout("/web3password/file/download"); got != 0 {
+		t.Errorf("timeout %s, want the upstream default", got)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/service/handlers"
+)
+
+// proxyFlushInterval keeps large file downloads flowing to the client while they stream.
+const proxyFlushInterval = 100 * time.Millisecond
+
+// ProxyOfficial forwards the request to an official domain. Bodies are streamed
+// in both directions unchanged and the upstream status and headers are kept.
+// A zero timeout falls back to the upstream request timeout.
+func ProxyOfficial(ctx *gin.Context, timeout time.Duration, retry bool) {
+	pool := currentUpstreamPool()
+	if timeout <= 0 {
+		timeout = time.Duration(pool.conf.RequestTimeout) * time.Second
+	}
+	traceID := ctx.GetString("trace_id")
+	rctx, cancel := context.WithTimeout(WithUpstreamRetry(ctx.Request.Context(), retry), timeout)
+	defer cancel()
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetXForwarded()
+			pr.Out.Header.Set("X-Trace-id", traceID)
+		},
+		Transport:     pool,
+		FlushInterval: proxyFlushInterval,
+		ModifyResponse: func(rsp *http.Response) error {
+			if handlers.IsHttpWithTraceID() {
+				rsp.Header.Set("X-Trace-id", traceID)
+			}
+			log.Logger.Info("agent official response", log.String("uri", ctx.Request.RequestURI), log.String("domain", rsp.Request.URL.Host), log.Any("status", rsp.StatusCode), log.String("trace_id", traceID))
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Logger.Error("agent official request error", log.String("uri", ctx.Request.RequestURI), log.String("trace_id", traceID), log.Error(err))
+			if ctx.Writer.Written() {
+				return
+			}
+			handlers.Response(ctx, model.StatusSystemError, model.MsgUpstreamErr, []byte(""))
+		},
+	}
+	proxy.ServeHTTP(ctx.Writer, ctx.Request.WithContext(rctx))
+}
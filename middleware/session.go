@@ -0,0 +1,43 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/service/handlers"
+	"github.com/web3password/satis/session"
+	"github.com/web3password/satis/signature"
+)
+
+// SessionRoutes refuses the requests signed with a session token outside of
+// session.ReadOnlyRoutes, before any middleware or handler verifies them.
+func SessionRoutes() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.Request.URL.Path
+		if !config.GetConfig().Session.Enable || session.Accepts(route) {
+			ctx.Next()
+			return
+		}
+		params, err := peekParams(ctx.Request)
+		if err != nil || signature.TypeOf(params) != session.Type {
+			// ParamsCheck refuses the requests it cannot decode
+			ctx.Next()
+			return
+		}
+		ensureTraceID(ctx)
+		log.Logger.Warn("session token refused", log.String("route", route), log.String("trace_id", ctx.GetString("trace_id")))
+		handlers.ResponseError(ctx, model.NewError(model.CodeSignature, "session token not accepted for "+route))
+		ctx.Abort()
+	}
+}
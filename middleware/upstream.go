@@ -0,0 +1,394 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+)
+
+const (
+	upstreamDefaultDialTimeout    = 5
+	upstreamDefaultRequestTimeout = 60
+	upstreamDefaultMaxRetries     = 2
+	upstreamDefaultFailThreshold  = 3
+	upstreamDefaultCooldown       = 30
+	upstreamDefaultHealthInterval = 10
+	upstreamDefaultHealthPath     = "/favicon.ico"
+)
+
+// ErrNoUpstream is returned when no official domain is configured.
+var ErrNoUpstream = errors.New("no official domain configured")
+
+// UpstreamError describes a failed request to an official domain.
+type UpstreamError struct {
+	Domain     string
+	StatusCode int
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("official domain %s: %s", e.Domain, e.Err.Error())
+	}
+	return fmt.Sprintf("official domain %s: status code %d", e.Domain, e.StatusCode)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+type upstreamDomain struct {
+	url       string
+	fails     int
+	downUntil time.Time
+}
+
+func (d *upstreamDomain) available(now time.Time) bool {
+	return !now.Before(d.downUntil)
+}
+
+// UpstreamPool balances requests over the official domains. Domains that fail
+// repeatedly are skipped for a cooldown, and an active checker brings them back.
+type UpstreamPool struct {
+	conf      config.Upstream
+	client    *http.Client
+	transport *http.Transport
+
+	done chan struct{}
+
+	lock    sync.Mutex
+	domains []*upstreamDomain
+}
+
+// NewUpstreamPool .
+func NewUpstreamPool(conf config.Upstream, domains []string) *UpstreamPool {
+	if conf.DialTimeout <= 0 {
+		conf.DialTimeout = upstreamDefaultDialTimeout
+	}
+	if conf.RequestTimeout <= 0 {
+		conf.RequestTimeout = upstreamDefaultRequestTimeout
+	}
+	if conf.MaxRetries <= 0 {
+		conf.MaxRetries = upstreamDefaultMaxRetries
+	}
+	if conf.FailThreshold <= 0 {
+		conf.FailThreshold = upstreamDefaultFailThreshold
+	}
+	if conf.Cooldown <= 0 {
+		conf.Cooldown = upstreamDefaultCooldown
+	}
+	if conf.HealthInterval == 0 {
+		conf.HealthInterval = upstreamDefaultHealthInterval
+	}
+	if conf.HealthPath == "" {
+		conf.HealthPath = upstreamDefaultHealthPath
+	}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(conf.DialTimeout) * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   time.Duration(conf.DialTimeout) * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	p := &UpstreamPool{
+		conf:      conf,
+		transport: transport,
+		done:      make(chan struct{}),
+	}
+	p.client = &http.Client{
+		Transport: p,
+		Timeout:   time.Duration(conf.RequestTimeout) * time.Second,
+	}
+	p.SetDomains(domains)
+	return p
+}
+
+// SetDomains replaces the domain list, keeping the health state of known domains.
+func (p *UpstreamPool) SetDomains(domains []string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	known := make(map[string]*upstreamDomain, len(p.domains))
+	for _, d := range p.domains {
+		known[d.url] = d
+	}
+	list := make([]*upstreamDomain, 0, len(domains))
+	for _, url := range domains {
+		if d, ok := known[url]; ok {
+			list = append(list, d)
+			continue
+		}
+		list = append(list, &upstreamDomain{url: url})
+	}
+	p.domains = list
+}
+
+// pick returns a random available domain not yet tried. When every domain is
+// down it falls back to the one that recovers first rather than failing outright.
+func (p *UpstreamPool) pick(tried map[string]bool) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(p.domains) == 0 {
+		return "", ErrNoUpstream
+	}
+	now := time.Now()
+	var candidates []*upstreamDomain
+	var fallback *upstreamDomain
+	for _, d := range p.domains {
+		if tried[d.url] {
+			continue
+		}
+		if d.available(now) {
+			candidates = append(candidates, d)
+		} else if fallback == nil || d.downUntil.Before(fallback.downUntil) {
+			fallback = d
+		}
+	}
+	if len(candidates) == 0 {
+		if fallback == nil {
+			return "", ErrNoUpstream
+		}
+		return fallback.url, nil
+	}
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates))))
+	if err != nil {
+		return candidates[0].url, nil
+	}
+	return candidates[index.Int64()].url, nil
+}
+
+func (p *UpstreamPool) markFail(url string, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, d := range p.domains {
+		if d.url != url {
+			continue
+		}
+		d.fails++
+		if d.fails >= p.conf.FailThreshold {
+			d.downUntil = time.Now().Add(time.Duration(p.conf.Cooldown) * time.Second)
+			log.Logger.Warn("upstream domain marked down", log.String("domain", url), log.Any("fails", d.fails), log.Error(err))
+		}
+		return
+	}
+}
+
+func (p *UpstreamPool) markOK(url string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, d := range p.domains {
+		if d.url != url {
+			continue
+		}
+		if d.fails >= p.conf.FailThreshold {
+			log.Logger.Info("upstream domain recovered", log.String("domain", url))
+		}
+		d.fails = 0
+		d.downUntil = time.Time{}
+		return
+	}
+}
+
+type upstreamRetryKey struct{}
+
+// WithUpstreamRetry marks whether a request may be retried on another domain.
+func WithUpstreamRetry(ctx context.Context, retry bool) context.Context {
+	return context.WithValue(ctx, upstreamRetryKey{}, retry)
+}
+
+// Do sends the request to an official domain, see RoundTrip for the retry rules.
+func (p *UpstreamPool) Do(method, uri string, body []byte, header http.Header, retry bool) (*http.Response, error) {
+	// the host is a placeholder, RoundTrip picks the official domain
+	req, err := http.NewRequestWithContext(WithUpstreamRetry(context.Background(), retry), method, "http://official"+uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return p.client.Do(req)
+}
+
+// RoundTrip sends the request to an official domain, only the path and query
+// of the request URL are used. Requests marked for retry whose body can be
+// replayed are retried on another domain after failures and 5xx responses;
+// other requests only after connection failures, since they never reached the domain.
+func (p *UpstreamPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	retry, _ := req.Context().Value(upstreamRetryKey{}).(bool)
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	tried := make(map[string]bool)
+	var lastErr error
+	for attempt := 0; attempt <= p.conf.MaxRetries; attempt++ {
+		domain, err := p.pick(tried)
+		if err != nil {
+			closeRequestBody(req)
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		tried[domain] = true
+		target, err := url.Parse(domain)
+		if err != nil {
+			closeRequestBody(req)
+			return nil, &UpstreamError{Domain: domain, Err: err}
+		}
+		out := req.Clone(req.Context())
+		out.URL.Scheme = target.Scheme
+		out.URL.Host = target.Host
+		out.URL.Path = strings.TrimSuffix(target.Path, "/") + req.URL.Path
+		out.URL.RawPath = ""
+		out.Host = target.Host
+		if attempt > 0 && req.GetBody != nil {
+			if out.Body, err = req.GetBody(); err != nil {
+				closeRequestBody(req)
+				return nil, &UpstreamError{Domain: domain, Err: err}
+			}
+		}
+		rsp, err := p.transport.RoundTrip(out)
+		if err != nil {
+			p.markFail(domain, err)
+			lastErr = &UpstreamError{Domain: domain, Err: err}
+			if replayable && (retry || isDialError(err)) && req.Context().Err() == nil {
+				continue
+			}
+			return nil, lastErr
+		}
+		if rsp.StatusCode >= http.StatusInternalServerError {
+			p.markFail(domain, fmt.Errorf("status code %d", rsp.StatusCode))
+			if retry && replayable && attempt < p.conf.MaxRetries {
+				_, _ = io.Copy(io.Discard, rsp.Body)
+				_ = rsp.Body.Close()
+				lastErr = &UpstreamError{Domain: domain, StatusCode: rsp.StatusCode}
+				continue
+			}
+			return rsp, nil
+		}
+		p.markOK(domain)
+		return rsp, nil
+	}
+	return nil, lastErr
+}
+
+// closeRequestBody closes the body of a request that is not handed to the
+// transport, a RoundTripper must close it even on errors. Closing a body the
+// transport already closed is harmless.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// healthCheck probes every domain once.
+func (p *UpstreamPool) healthCheck() {
+	p.lock.Lock()
+	urls := make([]string, 0, len(p.domains))
+	for _, d := range p.domains {
+		urls = append(urls, d.url)
+	}
+	p.lock.Unlock()
+	for _, url := range urls {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.conf.DialTimeout)*time.Second)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+p.conf.HealthPath, nil)
+		if err != nil {
+			cancel()
+			continue
+		}
+		rsp, err := p.transport.RoundTrip(req)
+		if err == nil {
+			_, _ = io.Copy(io.Discard, rsp.Body)
+			_ = rsp.Body.Close()
+			if rsp.StatusCode >= http.StatusInternalServerError {
+				err = fmt.Errorf("status code %d", rsp.StatusCode)
+			}
+		}
+		cancel()
+		if err != nil {
+			log.Logger.Debug("upstream health check fail", log.String("domain", url), log.Error(err))
+			p.markFail(url, err)
+			continue
+		}
+		p.markOK(url)
+	}
+}
+
+func (p *UpstreamPool) healthLoop() {
+	if p.conf.HealthInterval < 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(p.conf.HealthInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.healthCheck()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// stop ends the health checks and closes idle connections of a replaced pool.
+func (p *UpstreamPool) stop() {
+	close(p.done)
+	p.transport.CloseIdleConnections()
+}
+
+var (
+	upstreamLock   sync.Mutex
+	upstreamConfig *config.Config
+	upstream       *UpstreamPool
+)
+
+// currentUpstreamPool returns the shared pool, picking up domain changes after
+// a config reload and rebuilding it when the upstream section changed.
+func currentUpstreamPool() *UpstreamPool {
+	conf := config.GetConfig()
+	upstreamLock.Lock()
+	defer upstreamLock.Unlock()
+	if upstream == nil || upstreamConfig.Upstream != conf.Upstream {
+		if upstream != nil {
+			upstream.stop()
+		}
+		upstream = NewUpstreamPool(conf.Upstream, conf.OfficialDomains)
+		upstreamConfig = conf
+		go upstream.healthLoop()
+	} else if upstreamConfig != conf {
+		upstream.SetDomains(conf.OfficialDomains)
+		upstreamConfig = conf
+	}
+	return upstream
+}
@@ -0,0 +1,50 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/web3password/satis/config"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestUpstreamRoundTripClosesBodyWithoutUpstream(t *testing.T) {
+	for _, domains := range [][]string{nil, {"http://[::1"}} {
+		p := NewUpstreamPool(config.Upstream{}, domains)
+		body := &closeRecorder{Reader: strings.NewReader("body")}
+		req, err := http.NewRequest(http.MethodPost, "http://satis/web3password/userInfo", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.RoundTrip(req); err == nil {
+			t.Fatalf("%v: round trip succeeded", domains)
+		} else if domains == nil && !errors.Is(err, ErrNoUpstream) {
+			t.Fatalf("round trip error %v, want %v", err, ErrNoUpstream)
+		}
+		if !body.closed {
+			t.Errorf("%v: request body left open", domains)
+		}
+	}
+}
@@ -10,60 +10,21 @@ For more information, please refer to https://www.web3password.com/web3password_
 
 package model
 
-import (
-	"fmt"
-	"github.com/web3password/jewel/tools"
-	"github.com/web3password/satis/util"
-	"time"
-)
-
 type AdminRsp struct {
 	Code int32  `json:"code"`
 	Msg  string `json:"msg"`
 }
 
 type AdminCommonParams struct {
-	Address   string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=optional"`
 }
 
-func (s AdminCommonParams) Check(token string, timestamp int64, data []byte) (string, bool) {
-	nowTimestamp := time.Now().Unix()
-
-	if !tools.IsValidAddress(s.Address) {
-		return "invalid address " + s.Address, false
-	}
-
-	if len(s.Nonce) > util.W3PMaxNonceLength || len(s.Hash) > util.W3PMaxNonceLength {
-		return "invalid nonce or hash", false
-	}
-
-	if s.Timestamp == 0 {
-		msg := fmt.Sprintf("invalid timestamp , req timestamp is zero : %d", s.Timestamp)
-		return msg, false
-	}
-	if timestamp > nowTimestamp {
-		msg := fmt.Sprintf("invalid timestamp(client time>system time) , server timestamp=%d, client timestamp=%d", nowTimestamp, s.Timestamp)
-		return msg, false
-	}
-	if nowTimestamp-timestamp > util.W3PTimeout {
-		msg := fmt.Sprintf("invalid timestamp(client timeout, above 5s) , server timestamp=%d, client timestamp=%d", nowTimestamp, s.Timestamp)
-		return msg, false
-	}
-	if s.Token != token {
-		return "invalid token " + s.Token, false
-	}
-
-	if len(data) > 0 {
-		if isEqual, serverHash := tools.CompareHash(data, s.Hash); !isEqual {
-			return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, s.Hash), false
-		}
-	}
-
-	return "", true
+func (s AdminCommonParams) Check(token string, data []byte) (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{token}, Data: data})
 }
 
 type AdminMemberInfo struct {
@@ -77,7 +38,8 @@ type AdminMemberInfo struct {
 }
 
 type AdminMemberListRsp struct {
-	List []*AdminMemberInfo `json:"list" bson:"list"`
+	List       []*AdminMemberInfo `json:"list" bson:"list"`
+	NextCursor string             `json:"next_cursor,omitempty" bson:"next_cursor,omitempty"`
 }
 
 type AdminShareMnemonicRsp struct {
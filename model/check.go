@@ -0,0 +1,337 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/web3password/jewel/tools"
+	"github.com/web3password/satis/signature"
+	"github.com/web3password/satis/util"
+)
+
+// checkTagName holds the comma separated rules of a params field:
+//
+//	required         not empty, or not zero for numbers
+//	address          an address of any signature scheme
+//	token            one of the tokens accepted by the request
+//	max=N            at most N bytes, N items for a list, or N for numbers
+//	min=N            at least N, for numbers
+//	itemmax=N        every item of a list is at most N bytes
+//	timestamp[=N]    at most N seconds before now, util.W3PTimeout by default
+//	sha256           the sha256 of the data
+//	sha256=optional  the sha256 of the data when there is data
+//	sha256=required  the data is not empty and this is its sha256
+//	oneof=a|b        one of the listed values
+//	valid            the Check() bool of the field type passes
+//
+// N may be a number or one of the names in checkLimits and checkWindows.
+// Embedded structs are checked with their own tags, rules run in field order
+// and the first failure is reported as "invalid <json name>: <detail>". The
+// rules of a type are compiled once, those of checkedParams at init, and an
+// unknown rule or argument fails the compilation.
+const checkTagName = "check"
+
+var (
+	checkLimits = map[string]int{
+		"nonce":   util.W3PMaxNonceLength,
+		"general": util.W3PMaxGeneralLenth,
+		"record":  util.W3PMaxRecordLength,
+		"body":    util.W3PMaxBodyLength,
+		"batch":   util.W3PMaxBatchRecordNumber,
+	}
+	checkWindows = map[string]int64{
+		"attachment": util.W3PTimeoutAttachment,
+		"upload":     util.W3PTimeoutUpload,
+	}
+)
+
+// CheckInput is what a params check needs besides the params.
+type CheckInput struct {
+	Tokens  []string // accepted tokens
+	Data    []byte   // request data, matched by the sha256 rules
+	MaxData int      // max data length, 0 for no limit
+}
+
+type checkRule struct {
+	name   string
+	arg    string
+	n      int   // limit of max, min and itemmax
+	window int64 // window of timestamp
+}
+
+type checkField struct {
+	index []int
+	name  string
+	rules []checkRule
+}
+
+type checkFieldsEntry struct {
+	fields []checkField
+	err    error
+}
+
+var checkFields sync.Map // reflect.Type -> checkFieldsEntry
+
+// checkedParams are the params types compiled at init, so a bad check tag
+// stops satis at startup instead of failing its requests.
+var checkedParams = []any{
+	AddCredentialParams{}, AdminAddOrUpdateMemberParams{}, AdminAuthorizationParams{}, AdminBatchImportMemberParams{},
+	AdminCommonParams{}, AdminGetMemberListParams{}, AdminGetOrgInfoParams{}, AdminRegisterParams{},
+	AdminRemoveMemberParams{}, BatchAddCredentialParams{}, BatchCheckTxParams{}, BatchDeleteCredentialParams{},
+	CheckTxParams{}, DeleteAllCredentialParams{}, DeleteCredentialParams{}, EventsParams{},
+	FileAttachmentReqParams{}, FileDownloadReqParams{}, FileReportReqParams{}, FileUploadReqParams{},
+	GetAdminMnemonicParams{}, GetAllCredentialTimestampParams{}, GetCredentialListParams{}, GetCredentialParams{},
+	GetDiscountCodeInfoParams{}, GetOrderListParams{}, GetPersonalSignAddressParams{}, GetUserInfoParams{},
+	GetVIPInfoParams{}, GetVersionConfigParams{}, GetVersionDescParams{}, InitializeParams{},
+	OperationHistoryParams{}, RegisterParams{}, SessionParams{}, ShareFolderAddMemberDataReq{},
+	ShareFolderAddRecordParams{}, ShareFolderCommonParams{}, ShareFolderDeleteRecordParams{}, ShareFolderListParams{},
+	ShareFolderParams{}, ShareFolderUpdateParams{}, StorageReportParams{}, StorageStatParams{},
+	SyncCredentialsParams{}, TransactionData{}, TransactionParams{}, TransferSuperAdminParams{},
+	VaultExportParams{}, VaultImportParams{}, VaultImportStatusParams{}, VipAppleVerifyReceiptParams{},
+	VipCheckOrderParams{}, VipCreateOrderParams{}, VipGetConfigParams{}, VipIOSPromotionSignParams{},
+	VipPaymentListParams{}, VipSubscriptionListParams{},
+}
+
+func init() {
+	for _, params := range checkedParams {
+		if _, err := parseCheckFields(reflect.TypeOf(params)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// checkParams runs the check rules of params, a struct value.
+func checkParams(params any, in CheckInput) (string, bool) {
+	v := reflect.ValueOf(params)
+	fields, err := parseCheckFields(v.Type())
+	if err != nil {
+		return err.Error(), false
+	}
+	for _, f := range fields {
+		fv := v.FieldByIndex(f.index)
+		for _, rule := range f.rules {
+			if detail, ok := rule.check(fv, in); !ok {
+				return fmt.Sprintf("invalid %s: %s", f.name, detail), false
+			}
+		}
+	}
+	if in.MaxData > 0 && len(in.Data) > in.MaxData {
+		return fmt.Sprintf("invalid data: longer than %d bytes", in.MaxData), false
+	}
+	return "", true
+}
+
+// parseCheckFields compiles the check tags of t once, an unknown rule or a
+// bad argument is an error of the type.
+func parseCheckFields(t reflect.Type) ([]checkField, error) {
+	if entry, ok := checkFields.Load(t); ok {
+		e := entry.(checkFieldsEntry)
+		return e.fields, e.err
+	}
+	var fields []checkField
+	var err error
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField() && err == nil; i++ {
+			f := t.Field(i)
+			fieldIndex := append(append([]int{}, index...), i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				walk(f.Type, fieldIndex)
+				continue
+			}
+			tag := f.Tag.Get(checkTagName)
+			if tag == "" {
+				continue
+			}
+			field := checkField{index: fieldIndex, name: checkFieldName(f)}
+			for _, item := range strings.Split(tag, ",") {
+				name, arg, _ := strings.Cut(strings.TrimSpace(item), "=")
+				rule, rerr := compileCheckRule(name, arg, f.Type)
+				if rerr != nil {
+					err = fmt.Errorf("check tag of %s.%s: %w", t.Name(), f.Name, rerr)
+					return
+				}
+				field.rules = append(field.rules, rule)
+			}
+			fields = append(fields, field)
+		}
+	}
+	walk(t, nil)
+	checkFields.Store(t, checkFieldsEntry{fields: fields, err: err})
+	return fields, err
+}
+
+// compileCheckRule validates a rule against the field type and parses its argument.
+func compileCheckRule(name, arg string, t reflect.Type) (checkRule, error) {
+	rule := checkRule{name: name, arg: arg}
+	kind := t.Kind()
+	isInt := kind >= reflect.Int && kind <= reflect.Int64
+	var err error
+	switch name {
+	case "required":
+	case "address", "token", "sha256", "oneof":
+		if kind != reflect.String {
+			return rule, fmt.Errorf("rule %s on a %s", name, t)
+		}
+		if name == "sha256" && arg != "" && arg != "optional" && arg != "required" {
+			return rule, fmt.Errorf("invalid sha256 argument %q", arg)
+		}
+		if name == "oneof" && arg == "" {
+			return rule, fmt.Errorf("oneof without values")
+		}
+	case "max", "min", "itemmax":
+		if rule.n, err = checkLimit(arg); err != nil {
+			return rule, err
+		}
+		switch {
+		case name == "min" && !isInt:
+			return rule, fmt.Errorf("rule min on a %s", t)
+		case name == "itemmax" && (kind != reflect.Slice || (t.Elem().Kind() != reflect.String && t.Elem().Kind() != reflect.Slice)):
+			return rule, fmt.Errorf("rule itemmax on a %s", t)
+		case name == "max" && !isInt && kind != reflect.String && kind != reflect.Slice && kind != reflect.Map:
+			return rule, fmt.Errorf("rule max on a %s", t)
+		}
+	case "timestamp":
+		if !isInt {
+			return rule, fmt.Errorf("rule timestamp on a %s", t)
+		}
+		if rule.window, err = checkWindow(arg); err != nil {
+			return rule, err
+		}
+	case "valid":
+		if !t.Implements(reflect.TypeOf((*interface{ Check() bool })(nil)).Elem()) {
+			return rule, fmt.Errorf("rule valid on %s without a Check() bool", t)
+		}
+	default:
+		return rule, fmt.Errorf("unknown check rule %q", name)
+	}
+	return rule, nil
+}
+
+// checkFieldName is the json name of a field, the bson or go name without one.
+func checkFieldName(f reflect.StructField) string {
+	for _, key := range []string{"json", "bson"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func checkLimit(arg string) (int, error) {
+	if n, ok := checkLimits[arg]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid check limit %q", arg)
+	}
+	return n, nil
+}
+
+func checkWindow(arg string) (int64, error) {
+	if arg == "" {
+		return util.W3PTimeout, nil
+	}
+	if n, ok := checkWindows[arg]; ok {
+		return n, nil
+	}
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid check window %q", arg)
+	}
+	return n, nil
+}
+
+func (r checkRule) check(v reflect.Value, in CheckInput) (string, bool) {
+	switch r.name {
+	case "required":
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+			if v.Len() == 0 {
+				return "empty", false
+			}
+		default:
+			if v.IsZero() {
+				return fmt.Sprintf("%v", v.Interface()), false
+			}
+		}
+	case "address":
+		if !signature.ValidAddress(v.String()) {
+			return v.String(), false
+		}
+	case "token":
+		for _, token := range in.Tokens {
+			if v.String() == token {
+				return "", true
+			}
+		}
+		return v.String(), false
+	case "max":
+		n := r.n
+		if v.CanInt() {
+			if v.Int() > int64(n) {
+				return fmt.Sprintf("more than %d", n), false
+			}
+			return "", true
+		}
+		if v.Len() > n {
+			if v.Kind() == reflect.Slice {
+				return fmt.Sprintf("more than %d items", n), false
+			}
+			return fmt.Sprintf("longer than %d bytes", n), false
+		}
+	case "min":
+		if n := r.n; v.Int() < int64(n) {
+			return fmt.Sprintf("less than %d", n), false
+		}
+	case "itemmax":
+		n := r.n
+		for i := 0; i < v.Len(); i++ {
+			if v.Index(i).Len() > n {
+				return fmt.Sprintf("item %d longer than %d bytes", i, n), false
+			}
+		}
+	case "timestamp":
+		if !util.CheckTimestamp(v.Int(), r.window) {
+			return fmt.Sprintf("server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Int()), false
+		}
+	case "sha256":
+		if len(in.Data) == 0 {
+			switch r.arg {
+			case "optional":
+				return "", true
+			case "required":
+				return "empty data", false
+			}
+		}
+		if isEqual, serverHash := tools.CompareHash(in.Data, v.String()); !isEqual {
+			return fmt.Sprintf("server hash=%s, client hash=%s", serverHash, v.String()), false
+		}
+	case "oneof":
+		for _, value := range strings.Split(r.arg, "|") {
+			if v.String() == value {
+				return "", true
+			}
+		}
+		return v.String(), false
+	case "valid":
+		if checker, ok := v.Interface().(interface{ Check() bool }); ok && !checker.Check() {
+			return fmt.Sprintf("%v", v.Interface()), false
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,295 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const checkTestAddr = "0x1111111111111111111111111111111111111111"
+
+type checkLevel int
+
+func (l checkLevel) Check() bool { return l >= 0 && l <= 2 }
+
+type checkEmbedded struct {
+	Nonce string `json:"nonce" check:"max=4"`
+}
+
+type checkSample struct {
+	checkEmbedded
+	Addr      string     `json:"addr" check:"address"`
+	Token     string     `json:"token" check:"token"`
+	Timestamp int64      `json:"timestamp" check:"timestamp"`
+	Old       int64      `json:"old" check:"timestamp=100"`
+	ID        string     `json:"id" check:"required"`
+	Count     int32      `json:"count" check:"min=1,max=3"`
+	Tags      []string   `json:"tags" check:"max=2,itemmax=3"`
+	Kind      string     `json:"kind" check:"oneof=a|b"`
+	Level     checkLevel `json:"level" check:"valid"`
+	Hash      string     `json:"hash" check:"sha256=optional"`
+}
+
+func validSample() checkSample {
+	now := time.Now().Unix()
+	return checkSample{
+		Addr: checkTestAddr, Token: "t", Timestamp: now, Old: now - 50, ID: "id",
+		Count: 1, Tags: []string{"abc"}, Kind: "a", Level: 1,
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCheckRules(t *testing.T) {
+	data := []byte("payload")
+	tests := []struct {
+		name   string
+		edit   func(s *checkSample)
+		data   []byte
+		maxLen int
+		fail   string // prefix of the failure, empty to pass
+	}{
+		{"valid", func(s *checkSample) {}, nil, 0, ""},
+		{"valid with data", func(s *checkSample) { s.Hash = sha256Hex(data) }, data, 0, ""},
+		{"embedded max", func(s *checkSample) { s.Nonce = "12345" }, nil, 0, "invalid nonce: longer than 4 bytes"},
+		{"address", func(s *checkSample) { s.Addr = "0x12" }, nil, 0, "invalid addr: 0x12"},
+		{"token", func(s *checkSample) { s.Token = "other" }, nil, 0, "invalid token: other"},
+		{"timestamp zero", func(s *checkSample) { s.Timestamp = 0 }, nil, 0, "invalid timestamp: server timestamp="},
+		{"timestamp future", func(s *checkSample) { s.Timestamp += 60 }, nil, 0, "invalid timestamp"},
+		{"timestamp stale", func(s *checkSample) { s.Timestamp -= 60 }, nil, 0, "invalid timestamp"},
+		{"timestamp window", func(s *checkSample) { s.Old -= 100 }, nil, 0, "invalid old"},
+		{"required", func(s *checkSample) { s.ID = "" }, nil, 0, "invalid id: empty"},
+		{"min", func(s *checkSample) { s.Count = 0 }, nil, 0, "invalid count: less than 1"},
+		{"max number", func(s *checkSample) { s.Count = 4 }, nil, 0, "invalid count: more than 3"},
+		{"max items", func(s *checkSample) { s.Tags = []string{"a", "b", "c"} }, nil, 0, "invalid tags: more than 2 items"},
+		{"itemmax", func(s *checkSample) { s.Tags = []string{"abcd"} }, nil, 0, "invalid tags: item 0 longer than 3 bytes"},
+		{"oneof", func(s *checkSample) { s.Kind = "c" }, nil, 0, "invalid kind: c"},
+		{"valid rule", func(s *checkSample) { s.Level = 3 }, nil, 0, "invalid level: 3"},
+		{"sha256 mismatch", func(s *checkSample) { s.Hash = sha256Hex([]byte("other")) }, data, 0, "invalid hash: server hash="},
+		{"max data", func(s *checkSample) { s.Hash = sha256Hex(data) }, data, 3, "invalid data: longer than 3 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := validSample()
+			tt.edit(&s)
+			msg, ok := checkParams(s, CheckInput{Tokens: []string{"t"}, Data: tt.data, MaxData: tt.maxLen})
+			if tt.fail == "" {
+				if !ok {
+					t.Fatalf("failed: %s", msg)
+				}
+				return
+			}
+			if ok || !strings.HasPrefix(msg, tt.fail) {
+				t.Fatalf("got %v %q, want failure %q", ok, msg, tt.fail)
+			}
+		})
+	}
+}
+
+func TestCheckSha256Modes(t *testing.T) {
+	type modes struct {
+		Plain    string `json:"plain" check:"sha256"`
+		Required string `json:"required" check:"sha256=required"`
+	}
+	if msg, ok := checkParams(modes{Plain: sha256Hex(nil)}, CheckInput{}); ok || msg != "invalid required: empty data" {
+		t.Fatalf("empty data: %v %q", ok, msg)
+	}
+	data := []byte("x")
+	if msg, ok := checkParams(modes{Plain: sha256Hex(data), Required: sha256Hex(data)}, CheckInput{Data: data}); !ok {
+		t.Fatal(msg)
+	}
+}
+
+func TestCheckRulesFailAtCompile(t *testing.T) {
+	tests := []struct {
+		name   string
+		params any
+		err    string
+	}{
+		{"unknown rule", struct {
+			A string `check:"required,nosuch"`
+		}{}, `unknown check rule "nosuch"`},
+		{"bad limit", struct {
+			A string `check:"max=lots"`
+		}{}, `invalid check limit "lots"`},
+		{"bad window", struct {
+			A int64 `check:"timestamp=soon"`
+		}{}, `invalid check window "soon"`},
+		{"min on a string", struct {
+			A string `check:"min=1"`
+		}{}, "rule min on a string"},
+		{"address on a number", struct {
+			A int64 `check:"address"`
+		}{}, "rule address on a int64"},
+		{"valid without Check", struct {
+			A string `check:"valid"`
+		}{}, "rule valid on string"},
+		{"bad sha256 mode", struct {
+			A string `check:"sha256=maybe"`
+		}{}, `invalid sha256 argument "maybe"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCheckFields(reflect.TypeOf(tt.params)); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("compile error %v, want %q", err, tt.err)
+			}
+			// a type missed by checkedParams fails its requests, it never panics
+			if msg, ok := checkParams(tt.params, CheckInput{}); ok || !strings.Contains(msg, tt.err) {
+				t.Fatalf("check %v %q", ok, msg)
+			}
+		})
+	}
+}
+
+// TestCheckedParamsCoverTaggedTypes makes sure every type of the package with
+// check tags is compiled at init, directly or embedded in a listed type.
+func TestCheckedParamsCoverTaggedTypes(t *testing.T) {
+	covered := make(map[string]bool)
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		covered[t.Name()] = true
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.Anonymous && f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+			}
+		}
+	}
+	for _, params := range checkedParams {
+		walk(reflect.TypeOf(params))
+	}
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range st.Fields.List {
+				if field.Tag != nil && strings.Contains(field.Tag.Value, checkTagName+`:"`) {
+					if !covered[spec.Name.Name] {
+						t.Errorf("%s: %s has check tags but is not in checkedParams", file, spec.Name.Name)
+					}
+					break
+				}
+			}
+			return true
+		})
+	}
+}
+
+// TestSignedParamsCheckToken makes sure the params of every route check the
+// route token, a signature of the params of one route is no good for another.
+func TestSignedParamsCheckToken(t *testing.T) {
+	var hasRule func(t reflect.Type, json, rule string) bool
+	hasRule = func(t reflect.Type, json, rule string) bool {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				if hasRule(f.Type, json, rule) {
+					return true
+				}
+				continue
+			}
+			if strings.Split(f.Tag.Get("json"), ",")[0] != json {
+				continue
+			}
+			for _, r := range strings.Split(f.Tag.Get(checkTagName), ",") {
+				if r == rule {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, params := range checkedParams {
+		typ := reflect.TypeOf(params)
+		if hasRule(typ, "addr", "address") && !hasRule(typ, "token", "token") {
+			t.Errorf("%s is signed by addr but does not check its token", typ.Name())
+		}
+	}
+}
+
+func TestMigratedParams(t *testing.T) {
+	now := time.Now().Unix()
+	data := []byte("credential")
+
+	add := AddCredentialParams{Address: checkTestAddr, OpTimestamp: now, Timestamp: now, Token: AddCredentialToken, ID: "1", Hash: sha256Hex(data)}
+	if msg, ok := add.Check(data); !ok {
+		t.Fatalf("addCredential: %s", msg)
+	}
+	add.Hash = sha256Hex([]byte("tampered"))
+	if _, ok := add.Check(data); ok {
+		t.Fatal("addCredential accepted a hash of other data")
+	}
+
+	get := GetCredentialParams{Address: checkTestAddr, Timestamp: now, ID: "1", Token: GetCredentialToken}
+	if msg, ok := get.Check(); !ok {
+		t.Fatalf("getCredential: %s", msg)
+	}
+	get.Token = AddCredentialToken
+	if _, ok := get.Check(); ok {
+		t.Fatal("getCredential accepted the addCredential token")
+	}
+
+	sync := SyncCredentialsParams{Address: checkTestAddr, Timestamp: now, Token: SyncCredentialsToken, Cursor: 10, Limit: 500}
+	if msg, ok := sync.Check(); !ok {
+		t.Fatalf("syncCredentials: %s", msg)
+	}
+	for _, bad := range []SyncCredentialsParams{{Cursor: -1}, {Limit: -1}, {Limit: 501}} {
+		bad.Address, bad.Timestamp, bad.Token = checkTestAddr, now, SyncCredentialsToken
+		if _, ok := bad.Check(); ok {
+			t.Fatalf("syncCredentials accepted cursor %d limit %d", bad.Cursor, bad.Limit)
+		}
+	}
+
+	// share folder updates keep their lenient rules, the index checks the rest
+	update := ShareFolderUpdateParams{Token: ShareFolderUpdateToken, Timestamp: now - 3600}
+	if msg, ok := update.Check(nil); !ok {
+		t.Fatalf("sharefolder/update: %s", msg)
+	}
+	update.Nonce = strings.Repeat("n", 1000)
+	if _, ok := update.Check(nil); ok {
+		t.Fatal("sharefolder/update accepted a long nonce")
+	}
+
+	// the timestamp window is checked by the params, not again by the handler
+	org := AdminGetOrgInfoParams{Address: checkTestAddr, Timestamp: now - 3600, Token: AdminGetOrgInfoToken}
+	if msg, ok := org.Check(AdminGetOrgInfoToken); ok || !strings.HasPrefix(msg, "invalid timestamp") {
+		t.Fatalf("admin/getOrgInfo stale timestamp: %v %q", ok, msg)
+	}
+}
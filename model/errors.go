@@ -0,0 +1,182 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Category groups codes by how a client should react, it maps to an HTTP status.
+type Category string
+
+const (
+	CategoryOK                 Category = "ok"
+	CategoryInvalidArgument    Category = "invalid_argument"
+	CategoryUnauthenticated    Category = "unauthenticated"
+	CategoryForbidden          Category = "forbidden"
+	CategoryNotFound           Category = "not_found"
+	CategoryResourceExhausted  Category = "resource_exhausted"
+	CategoryFailedPrecondition Category = "failed_precondition"
+	CategoryInternal           Category = "internal"
+	CategoryUnavailable        Category = "unavailable"
+)
+
+// HTTPStatus returns the HTTP status of the category.
+func (c Category) HTTPStatus() int {
+	switch c {
+	case CategoryOK:
+		return http.StatusOK
+	case CategoryInvalidArgument:
+		return http.StatusBadRequest
+	case CategoryUnauthenticated:
+		return http.StatusUnauthorized
+	case CategoryForbidden:
+		return http.StatusForbidden
+	case CategoryNotFound:
+		return http.StatusNotFound
+	case CategoryResourceExhausted:
+		return http.StatusTooManyRequests
+	case CategoryFailedPrecondition:
+		return http.StatusConflict
+	case CategoryUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorCode is one entry of the catalog. Code and Key are stable and may be
+// relied on by clients, Msg is the default message.
+type ErrorCode struct {
+	Code      int32
+	Category  Category
+	Key       string
+	Msg       string
+	Retryable bool // the same request may succeed later
+}
+
+var catalog = make(map[int32]*ErrorCode)
+
+// register adds a code to the catalog, a code or key used twice is a programming error.
+func register(code int32, category Category, key, msg string, retryable bool) *ErrorCode {
+	if _, ok := catalog[code]; ok {
+		panic(fmt.Sprintf("error code %d registered twice", code))
+	}
+	for _, c := range catalog {
+		if c.Key == key {
+			panic(fmt.Sprintf("error key %s registered twice", key))
+		}
+	}
+	c := &ErrorCode{Code: code, Category: category, Key: key, Msg: msg, Retryable: retryable}
+	catalog[code] = c
+	return c
+}
+
+var (
+	CodeOK                    = register(StatusOK, CategoryOK, "ok", MsgOK, false)
+	CodeServiceCheck          = register(StatusServiceCheckErr, CategoryInternal, "service_check_failed", MsgServiceCheckErr, true)
+	CodeParams                = register(StatusParamsErr, CategoryInvalidArgument, "params_invalid", MsgParamsErr, false)
+	CodeSignature             = register(StatusSignatureErr, CategoryUnauthenticated, "signature_invalid", MsgSignatureErr, false)
+	CodeTimestamp             = register(StatusTimestampErr, CategoryInvalidArgument, "timestamp_invalid", MsgTimestamp, false)
+	CodeAuth                  = register(StatusAuthErr, CategoryUnauthenticated, "auth_invalid", MsgAuthErr, false)
+	CodeDataEmpty             = register(StatusDataEmpty, CategoryNotFound, "data_empty", MsgDataEmpty, false)
+	CodeLimit                 = register(StatusLimitCheckErr, CategoryResourceExhausted, "limit_reached", MsgLimit, false)
+	CodeLogic                 = register(StatusLogicCheckErr, CategoryFailedPrecondition, "logic_check_failed", MsgLogicErr, false)
+	CodeForbidden             = register(StatusForbiddenErr, CategoryForbidden, "forbidden", MsgForbiddenErr, false)
+	CodeIdempotencyConflict   = register(StatusIdempotencyConflict, CategoryFailedPrecondition, "idempotency_key_conflict", MsgIdempotencyConflict, false)
+	CodeIdempotencyInProgress = register(StatusIdempotencyInProgress, CategoryUnavailable, "idempotency_key_in_progress", MsgIdempotencyInProgress, true)
+	CodeSystem                = register(StatusSystemError, CategoryInternal, "system_error", MsgSystemErr, true)
+)
+
+// LookupCode returns the catalog entry of a code.
+func LookupCode(code int32) (*ErrorCode, bool) {
+	c, ok := catalog[code]
+	return c, ok
+}
+
+// Codes returns the catalog sorted by code.
+func Codes() []*ErrorCode {
+	codes := make([]*ErrorCode, 0, len(catalog))
+	for _, c := range catalog {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// Error is an error with a catalog code, Msg overrides the default message.
+type Error struct {
+	Code *ErrorCode
+	Msg  string
+	Err  error
+}
+
+// NewError returns an error of the code, msg may be empty for the default message.
+func NewError(code *ErrorCode, msg string) *Error {
+	return &Error{Code: code, Msg: msg}
+}
+
+// WrapError returns an error of the code caused by err, the cause is not sent to clients.
+func WrapError(code *ErrorCode, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("%d %s: %s", e.Code.Code, e.Code.Key, e.Message())
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same code, so errors.Is(err, model.NewError(model.CodeParams, "")) works.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Message is the message sent to clients.
+func (e *Error) Message() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return e.Code.Msg
+}
+
+// AsError returns err as a catalog error, any other error is a system error.
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return WrapError(CodeSystem, err)
+}
+
+// CodesMarkdown renders the catalog as a markdown reference table.
+func CodesMarkdown() string {
+	var b strings.Builder
+	b.WriteString("# Error codes\n\nGenerated by `make errors-doc` from model/errors.go, do not edit.\n\n")
+	b.WriteString("Every response carries its code in the BSON body and is sent with HTTP 200. With\n")
+	b.WriteString("`http_server.error_status` set, errors written from the catalog, with the key in the\n")
+	b.WriteString("X-Error-Key header, carry the HTTP status of their category instead.\n\n")
+	b.WriteString("| Code | Key | Category | HTTP | Retryable | Message |\n")
+	b.WriteString("|------|-----|----------|------|-----------|---------|\n")
+	for _, c := range Codes() {
+		fmt.Fprintf(&b, "| %d | %s | %s | %d | %t | %s |\n", c.Code, c.Key, c.Category, c.Category.HTTPStatus(), c.Retryable, c.Msg)
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestTxStatusFailedKeepsWireValue(t *testing.T) {
+	// the IsSuccess of a failed checkTx, clients compare against it
+	if TxStatusFailed != 222226 {
+		t.Fatalf("TxStatusFailed %d, want 222226", TxStatusFailed)
+	}
+}
+
+func TestCategoryHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code   *ErrorCode
+		status int
+	}{
+		{CodeOK, http.StatusOK},
+		{CodeParams, http.StatusBadRequest},
+		{CodeSignature, http.StatusUnauthorized},
+		{CodeForbidden, http.StatusForbidden},
+		{CodeDataEmpty, http.StatusNotFound},
+		{CodeLimit, http.StatusTooManyRequests},
+		{CodeIdempotencyConflict, http.StatusConflict},
+		{CodeIdempotencyInProgress, http.StatusServiceUnavailable},
+		{CodeSystem, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := tt.code.Category.HTTPStatus(); got != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.code.Key, got, tt.status)
+		}
+	}
+}
+
+func TestAsError(t *testing.T) {
+	cause := errors.New("disk full")
+	wrapped := fmt.Errorf("save: %w", NewError(CodeLimit, "too many"))
+	if e := AsError(wrapped); e.Code != CodeLimit || e.Message() != "too many" {
+		t.Fatalf("wrapped catalog error became %v", e)
+	}
+	if e := AsError(cause); e.Code != CodeSystem || !errors.Is(e, cause) || e.Message() != MsgSystemErr {
+		t.Fatalf("plain error became %v", e)
+	}
+	if !errors.Is(wrapped, NewError(CodeLimit, "")) || errors.Is(wrapped, NewError(CodeParams, "")) {
+		t.Fatal("errors.Is does not match by code")
+	}
+}
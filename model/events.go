@@ -0,0 +1,65 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package model
+
+// Notification types, the event names of the /events stream.
+const (
+	NotifyCredentialChanged   = "credential_changed"
+	NotifyFolderRecordAdded   = "folder_record_added"
+	NotifyFolderMemberAdded   = "folder_member_added"
+	NotifyFolderMemberRemoved = "folder_member_removed"
+	NotifyVipChanged          = "vip_changed"
+)
+
+// Notification is published by a node with CMDNotify, json in params. It goes
+// to the subscribers of every address of Addrs and, when OrgId is set, to the
+// ones of the org. Addrs is never sent to the clients.
+type Notification struct {
+	Type        string   `json:"type"`
+	Addrs       []string `json:"addrs,omitempty"`
+	OrgId       string   `json:"org_id,omitempty"`
+	FolderId    string   `json:"folder_id,omitempty"`
+	Id          string   `json:"id,omitempty"`
+	OpTimestamp int64    `json:"op_timestamp,omitempty"`
+}
+
+// Valid reports whether n is of a known type and has a recipient.
+func (n *Notification) Valid() bool {
+	switch n.Type {
+	case NotifyCredentialChanged, NotifyFolderRecordAdded, NotifyFolderMemberAdded, NotifyFolderMemberRemoved, NotifyVipChanged:
+	default:
+		return false
+	}
+	return len(n.Addrs) > 0 || n.OrgId != ""
+}
+
+// EventsParams subscribe to the notifications of addr and, when set, of
+// org_id, which the ares node must confirm addr is a member of.
+type EventsParams struct {
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	OrgId     string `json:"org_id" check:"max=nonce"`
+}
+
+func (e EventsParams) Check() (string, bool) {
+	return checkParams(e, CheckInput{Tokens: []string{EventsToken}})
+}
+
+type EventsReq struct {
+	Signature string
+	Params    string
+}
+
+type EventsRsp struct {
+	Code int32
+	Msg  string
+}
@@ -11,10 +11,6 @@ For more information, please refer to https://www.web3password.com/web3password_
 package model
 
 import (
-	"fmt"
-	"time"
-
-	"github.com/web3password/jewel/tools"
 	"github.com/web3password/satis/util"
 )
 
@@ -23,15 +19,21 @@ type ShareFolderRsp struct {
 	Msg  string `json:"msg"`
 }
 type ShareFolderParams struct {
-	Address   string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=required"`
 }
 
+// ShareFolderUpdateParams only bound the lengths and the token, the update
+// data is checked by the index node, as before the rules were declared.
 type ShareFolderUpdateParams struct {
-	ShareFolderParams
+	Address   string `json:"addr"`
+	Timestamp int64  `json:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce"`
 }
 
 //// MemberList .
@@ -69,190 +71,72 @@ type PublicKey struct {
 }
 
 func (f ShareFolderParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(f.Address) {
-		return "invalid address " + f.Address, false
-	}
-
-	if f.Token != ShareFolderCreateToken {
-		return "invalid token " + f.Token, false
-	}
-
-	if !util.CheckTimestamp(f.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), f.Timestamp)
-		return msg, false
-	}
-
-	if len(data) == 0 {
-		return "invalid empty data ", false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.Hash) > util.W3PMaxNonceLength || len(data) > util.W3PMax2048Lenth {
-		return "invalid nonce or hash data-length", false
-	}
-
-	if isEqual, serverHash := tools.CompareHash(data, f.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, f.Hash), false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{ShareFolderCreateToken}, Data: data, MaxData: util.W3PMax2048Lenth})
 }
 
+// Check bounds the lengths and checks the update token.
 func (f ShareFolderUpdateParams) Check(data []byte) (string, bool) {
-	if f.Token != ShareFolderUpdateToken {
-		return "invalid token " + f.Token, false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.Hash) > util.W3PMaxNonceLength || len(data) > util.W3PMax2048Lenth {
-		return "invalid nonce or hash or data-length", false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{ShareFolderUpdateToken}, Data: data, MaxData: util.W3PMax2048Lenth})
 }
 
 type ShareFolderCommonParams struct {
-	Address   string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=optional"`
 }
 
-func (s ShareFolderCommonParams) Check(token string, timestamp int64, data []byte) (string, bool) {
-	nowTimestamp := time.Now().Unix()
-
-	if !tools.IsValidAddress(s.Address) {
-		return "invalid address " + s.Address, false
-	}
-	if s.Timestamp == 0 {
-		msg := fmt.Sprintf("invalid timestamp , req timestamp is zero : %d", s.Timestamp)
-		return msg, false
-	}
-	if timestamp > nowTimestamp {
-		msg := fmt.Sprintf("invalid timestamp(client time>system time) , server timestamp=%d, client timestamp=%d", nowTimestamp, s.Timestamp)
-		return msg, false
-	}
-	if nowTimestamp-timestamp > 12 {
-		msg := fmt.Sprintf("invalid timestamp(client timeout, above 5s) , server timestamp=%d, client timestamp=%d", nowTimestamp, s.Timestamp)
-		return msg, false
-	}
-	if s.Token != token {
-		return "invalid token " + s.Token, false
-	}
-
-	if len(s.Nonce) > util.W3PMaxNonceLength || len(s.Hash) > util.W3PMaxNonceLength || len(data) > util.W3PMax2048Lenth {
-		return "invalid nonce or hash or data-length", false
-	}
+func (s ShareFolderCommonParams) Check(token string, data []byte) (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{token}, Data: data, MaxData: util.W3PMax2048Lenth})
+}
 
-	if len(data) > 0 {
-		if isEqual, serverHash := tools.CompareHash(data, s.Hash); !isEqual {
-			return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, s.Hash), false
-		}
-	}
+// ShareFolderListParams are the params of the paged folder record and member lists.
+type ShareFolderListParams struct {
+	ShareFolderCommonParams
+	Page
+}
 
-	return "", true
+func (s ShareFolderListParams) Check(token string, data []byte) (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{token}, Data: data, MaxData: util.W3PMax2048Lenth})
 }
 
 type ShareFolderAddMemberDataReq struct {
-	MemberAddr     string `bson:"member_addr"`
+	MemberAddr     string `bson:"member_addr" check:"required"`
 	MemberSign     string `bson:"member_sign"`
-	FolderId       string `bson:"folder_id"`
-	FolderMnemonic []byte `bson:"folder_mnemonic"`
-	MemberName     []byte `bson:"member_name"`
+	FolderId       string `bson:"folder_id" check:"required"`
+	FolderMnemonic []byte `bson:"folder_mnemonic" check:"required"`
+	MemberName     []byte `bson:"member_name" check:"required"`
 }
 
-func (s ShareFolderAddMemberDataReq) Check() bool {
-	if s.MemberAddr == "" {
-		return false
-	}
-	if s.FolderId == "" {
-		return false
-	}
-	if s.FolderId == "" {
-		return false
-	}
-	if len(s.FolderMnemonic) <= 0 {
-		return false
-	}
-	if len(s.MemberName) <= 0 {
-		return false
-	}
-	return true
+func (s ShareFolderAddMemberDataReq) Check() (string, bool) {
+	return checkParams(s, CheckInput{})
 }
 
 type ShareFolderAddRecordParams struct {
-	Address   string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=required"`
 }
 
 func (f ShareFolderAddRecordParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(f.Address) {
-		return "invalid address " + f.Address, false
-	}
-
-	if f.Token != ShareFolderAddRecordToken {
-		return "invalid token " + f.Token, false
-	}
-
-	if !util.CheckTimestamp(f.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), f.Timestamp)
-		return msg, false
-	}
-
-	if len(data) == 0 {
-		return "invalid empty data ", false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.Hash) > util.W3PMaxNonceLength {
-		return "invalid nonce or hash", false
-	}
-
-	if isEqual, serverHash := tools.CompareHash(data, f.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, f.Hash), false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{ShareFolderAddRecordToken}, Data: data})
 }
 
 type ShareFolderDeleteRecordParams struct {
-	Address   string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=required"`
 	FolderId  string `json:"folder_id"`
 	RecordId  string `json:"record_id"`
 }
 
 func (f ShareFolderDeleteRecordParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(f.Address) {
-		return "invalid address " + f.Address, false
-	}
-
-	if f.Token != ShareFolderDeleteRecordToken {
-		return "invalid token " + f.Token, false
-	}
-
-	if !util.CheckTimestamp(f.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), f.Timestamp)
-		return msg, false
-	}
-
-	if len(data) == 0 {
-		return "invalid empty data ", false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.Hash) > util.W3PMaxNonceLength {
-		return "invalid nonce or hash", false
-	}
-
-	if isEqual, serverHash := tools.CompareHash(data, f.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, f.Hash), false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{ShareFolderDeleteRecordToken}, Data: data})
 }
 
 type ShareFolder struct {
@@ -277,7 +161,8 @@ type ShareFolderRecord struct {
 }
 
 type ShareFolderRecordRsp struct {
-	List []*ShareFolderRecord `json:"list" bson:"list"`
+	List       []*ShareFolderRecord `json:"list" bson:"list"`
+	NextCursor string               `json:"next_cursor,omitempty" bson:"next_cursor,omitempty"`
 }
 
 type ShareFolderRecordByRid struct {
@@ -301,5 +186,6 @@ type ShareFolderMemberInfo struct {
 	FolderMnemonic []byte `json:"folder_mnemonic" bson:"folder_mnemonic"`
 }
 type ShareFolderMemberListRsp struct {
-	List []*ShareFolderMemberInfo `json:"list" bson:"list"`
+	List       []*ShareFolderMemberInfo `json:"list" bson:"list"`
+	NextCursor string                   `json:"next_cursor,omitempty" bson:"next_cursor,omitempty"`
 }
@@ -11,8 +11,7 @@ package model
 
 const (
 	// StatusOK request success
-	StatusOK     = 111111
-	StatusFAILED = 222226
+	StatusOK = 111111
 
 	// StatusServiceCheckErr request system error
 	StatusServiceCheckErr = 222222
@@ -27,29 +26,59 @@ const (
 	StatusLimitCheckErr = 222228
 	StatusLogicCheckErr = 222229
 	StatusForbiddenErr  = 222403
+	// StatusIdempotencyConflict an idempotency key reused for another payload
+	StatusIdempotencyConflict = 222409
+	// StatusIdempotencyInProgress the first request of an idempotency key has not ended yet
+	StatusIdempotencyInProgress = 222410
 
-	StatusSystemError     = 333333
+	StatusSystemError = 333333
+	// StatusSystemErrorCode codes above it are internal errors of the official service
 	StatusSystemErrorCode = 300000
 
+	// TxStatusFailed is the IsSuccess value of a transaction that is not on chain,
+	// the StatusFAILED clients compare against. It is a result flag and not a
+	// response code, its value is shared with StatusAuthErr
+	TxStatusFailed = 222226
+
 	ARES_PROXY    = "ares"
 	INDEX_PROXY   = "index"
 	STORAGE_PROXY = "storage"
 
-	MsgOK                = "success"
-	MsgParamsErr         = "params fail"
-	MsgSystemErr         = "system fail"
-	MsgSignatureErr      = "signature fail"
-	MsgTimestamp         = "timestamp fail"
-	NoAdminShareMnemonic = "no admin share mnemonic"
-	MsgLimit             = "You have reached the item limit and cannot add any more."
-	MsgRepeat            = "You have already added this member, please do not add again."
-	MsgTimeoutErr        = "service timeout error"
+	MsgOK                    = "success"
+	MsgParamsErr             = "params fail"
+	MsgSystemErr             = "system fail"
+	MsgSignatureErr          = "signature fail"
+	MsgTimestamp             = "timestamp fail"
+	NoAdminShareMnemonic     = "no admin share mnemonic"
+	MsgLimit                 = "You have reached the item limit and cannot add any more."
+	MsgRepeat                = "You have already added this member, please do not add again."
+	MsgTimeoutErr            = "service timeout error"
+	MsgUpstreamErr           = "official service unavailable"
+	MsgServiceCheckErr       = "system error"
+	MsgAuthErr               = "auth fail"
+	MsgDataEmpty             = "data empty"
+	MsgLogicErr              = "logic check fail"
+	MsgForbiddenErr          = "current node forbid error"
+	MsgIdempotencyConflict   = "idempotency key already used for another request"
+	MsgIdempotencyInProgress = "a request with this idempotency key is in progress"
 
 	W3PTimeoutMin            = 12
 	W3PTimeoutMax            = 15
 	W3PTimeoutFileAttachment = 60
 )
 
+// NextCursorKey is the response field, and the grpc header between the service
+// and the handlers, holding the cursor of the next page of a list.
+const NextCursorKey = "next_cursor"
+
+// Page is the cursor pagination of a list request: at most Limit items after
+// Cursor, the next_cursor of the previous page. An empty cursor asks the first
+// page and limit 0 the page size of the backend.
+type Page struct {
+	Limit  int32  `json:"limit,omitempty" check:"min=0,max=batch"`
+	Cursor string `json:"cursor,omitempty" check:"max=general"`
+}
+
 type Response struct {
 	Code int32  `json:"code"`
 	Msg  string `json:"msg"`
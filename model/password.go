@@ -59,7 +59,8 @@ type BatchDeleteCredentialRspDataListItem struct {
 }
 
 type GetCredentialRspData struct {
-	List []*GetCredentialRsp `json:"list" bson:"list"`
+	List       []*GetCredentialRsp `json:"list" bson:"list"`
+	NextCursor string              `json:"next_cursor,omitempty" bson:"next_cursor,omitempty"`
 }
 
 type GetCredentialListRsp struct {
@@ -75,6 +76,41 @@ type GetCredentialRsp struct {
 	Msg         string `json:"msg,omitempty" bson:"msg"`
 }
 
+// Ops of a credential change.
+const (
+	SyncOpAdd    = "add"
+	SyncOpUpdate = "update"
+	SyncOpDelete = "delete" // a tombstone, the credential is gone
+)
+
+type SyncCredentialsReq struct {
+	Signature string
+	Params    string
+}
+
+// SyncCredentialsRsp is the index answer to CMDIndexSyncCredentials, json in params.
+type SyncCredentialsRsp struct {
+	Code int32               `json:"code,omitempty" bson:"code"`
+	Msg  string              `json:"msg,omitempty" bson:"msg"`
+	Data SyncCredentialsData `json:"data" bson:"data"`
+}
+
+// SyncCredentialsData is a page of changes ordered by seq. Cursor is the seq of
+// the last change of the page, the cursor of the next call.
+type SyncCredentialsData struct {
+	Changes []*CredentialChange `json:"changes" bson:"changes"`
+	Cursor  int64               `json:"cursor" bson:"cursor"`
+	HasMore bool                `json:"has_more" bson:"has_more"`
+}
+
+type CredentialChange struct {
+	Id          string `json:"id" bson:"id"`
+	Op          string `json:"op" bson:"op"`
+	Seq         int64  `json:"seq" bson:"seq"`
+	OpTimestamp int64  `json:"op_timestamp" bson:"op_timestamp"`
+	Credential  []byte `json:"credential,omitempty" bson:"credential,omitempty"`
+}
+
 type GetAllCredentialTimestampRspData struct {
 	List []*GetAllCredentialTimestampRsp `json:"list" bson:"list"`
 }
@@ -11,10 +11,6 @@ For more information, please refer to https://www.web3password.com/web3password_
 package model
 
 import (
-	"fmt"
-	"time"
-
-	"github.com/web3password/jewel/tools"
 	"github.com/web3password/satis/util"
 )
 
@@ -54,161 +50,57 @@ type FileAttachmentItem struct {
 }
 
 type FileUploadReqParams struct {
-	Addr      string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Sha256    string `json:"sha256"`
-	Rid       string `json:"rid"`
-	OrgId     string `json:"org_id"`
+	Addr      string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp=upload"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Sha256    string `json:"sha256" check:"max=nonce,sha256=required"`
+	Rid       string `json:"rid" check:"max=nonce"`
+	OrgId     string `json:"org_id" check:"required,max=nonce"`
 }
 
 type FileDownloadReqParams struct {
-	Addr      string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Cid       string `json:"cid"`
-	Rid       string `json:"rid"`
-	OrgId     string `json:"org_id"`
+	Addr      string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Cid       string `json:"cid" check:"max=nonce"`
+	Rid       string `json:"rid" check:"max=nonce"`
+	OrgId     string `json:"org_id" check:"required,max=nonce"`
 }
 
 type FileReportReqParams struct {
-	Addr      string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Rid       string `json:"rid"`
-	FlowId    int32  `json:"flow_id"`
-	Hash      string `json:"hash"`
-	OrgId     string `json:"org_id"`
+	Addr      string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"required,max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Rid       string `json:"rid" check:"required,max=nonce"`
+	FlowId    int32  `json:"flow_id" check:"required"`
+	Hash      string `json:"hash" check:"required,max=nonce"`
+	OrgId     string `json:"org_id" check:"required,max=nonce"`
 }
 
 type FileAttachmentReqParams struct {
-	Addr      string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
-	OrgId     string `json:"org_id"`
+	Addr      string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp=attachment"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce"`
+	OrgId     string `json:"org_id" check:"required,max=nonce"`
 }
 
 func (f FileUploadReqParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(f.Addr) {
-		return "invalid address " + f.Addr, false
-	}
-
-	if f.Token != FileUploadToken {
-		return "invalid token " + f.Token, false
-	}
-
-	if !util.CheckTimestamp(int64(f.Timestamp), 120) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), f.Timestamp)
-		return msg, false
-	}
-
-	if len(data) == 0 {
-		return "invalid empty data ", false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.Rid) > util.W3PMaxNonceLength || len(f.OrgId) > util.W3PMaxNonceLength || len(f.Sha256) > util.W3PMaxNonceLength {
-		return "invalid nonce or rid or orgid or hash", false
-	}
-
-	if len(data) > util.W3PMaxAttachmentLength {
-		return "invalid attachment size", false
-	}
-
-	if isEqual, serverHash := tools.CompareHash(data, f.Sha256); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash(sha256)=%s", serverHash, f.Sha256), false
-	}
-
-	if len(f.OrgId) == 0 {
-		return "invalid org_id", false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{FileUploadToken}, Data: data, MaxData: util.W3PMaxAttachmentLength})
 }
 
 func (f FileDownloadReqParams) Check() (string, bool) {
-	if !tools.IsValidAddress(f.Addr) {
-		return "invalid address " + f.Addr, false
-	}
-
-	if f.Token != FileDownloadToken {
-		return "invalid token " + f.Token, false
-	}
-
-	if !util.CheckTimestamp(int64(f.Timestamp)) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), f.Timestamp)
-		return msg, false
-	}
-
-	if len(f.OrgId) == 0 {
-		return "invalid org_id", false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.Rid) > util.W3PMaxNonceLength || len(f.OrgId) > util.W3PMaxNonceLength || len(f.Cid) > util.W3PMaxNonceLength {
-		return "invalid nonce or rid or orgid or cid", false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{FileDownloadToken}})
 }
 
 func (f FileReportReqParams) Check() (string, bool) {
-	if !tools.IsValidAddress(f.Addr) {
-		return "invalid addr " + f.Addr, false
-	}
-	if !util.CheckTimestamp(int64(f.Timestamp)) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), f.Timestamp)
-		return msg, false
-	}
-	if len(f.Nonce) == 0 {
-		return "invalid nonce " + f.Nonce, false
-	}
-	if f.Token != FileReportToken {
-		return "invalid token " + f.Token, false
-	}
-	if len(f.Rid) == 0 {
-		return "invalid rid " + f.Rid, false
-	}
-	if f.FlowId == 0 {
-		return "invalid flow_id " + string(f.FlowId), false
-	}
-	if len(f.Hash) == 0 {
-		return "invalid hash " + f.Hash, false
-	}
-	if len(f.OrgId) == 0 {
-		return "invalid org_id", false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.Rid) > util.W3PMaxNonceLength || len(f.OrgId) > util.W3PMaxNonceLength || len(f.Hash) > util.W3PMaxNonceLength {
-		return "invalid nonce or rid or orgid or hash", false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{FileReportToken}})
 }
 
 func (f FileAttachmentReqParams) Check() (string, bool) {
-	if !tools.IsValidAddress(f.Addr) {
-		return "invalid address " + f.Addr, false
-	}
-
-	if f.Token != FileAttachmentToken {
-		return "invalid token " + f.Token, false
-	}
-
-	if !util.CheckTimestamp(int64(f.Timestamp), 60) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), f.Timestamp)
-		return msg, false
-	}
-	if len(f.OrgId) == 0 {
-		return "invalid org_id", false
-	}
-
-	if len(f.Nonce) > util.W3PMaxNonceLength || len(f.OrgId) > util.W3PMaxNonceLength || len(f.Hash) > util.W3PMaxNonceLength {
-		return "invalid nonce or orgid or hash", false
-	}
-
-	return "", true
+	return checkParams(f, CheckInput{Tokens: []string{FileAttachmentToken}})
 }
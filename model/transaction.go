@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package model
+
+import (
+	"github.com/web3password/satis/util"
+)
+
+// TransactionParams sign the ops in data, every op is signed on its own too.
+type TransactionParams struct {
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	OrgId     string `json:"org_id" check:"max=nonce"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=required"`
+}
+
+func (t TransactionParams) Check(data []byte) (string, bool) {
+	return checkParams(t, CheckInput{Tokens: []string{TransactionToken}, Data: data, MaxData: util.W3PMaxBodyLength})
+}
+
+// TransactionData is the data of a transaction request, BSON.
+type TransactionData struct {
+	Ops []*TxOp `bson:"ops" check:"required,max=batch"`
+}
+
+func (t TransactionData) Check() (string, bool) {
+	return checkParams(t, CheckInput{})
+}
+
+// TxOp is a sub-operation, the request a client would send to Route alone.
+type TxOp struct {
+	Route     string `bson:"route" json:"route"`
+	Signature string `bson:"signature" json:"signature"`
+	Params    string `bson:"params" json:"params"`
+	Data      []byte `bson:"data,omitempty" json:"data,omitempty"`
+}
+
+// TxCommand is an op as the index gets it, the route resolved to its command.
+type TxCommand struct {
+	Cmd       string `bson:"cmd"`
+	Signature string `bson:"signature"`
+	Params    string `bson:"params"`
+	Data      []byte `bson:"data,omitempty"`
+}
+
+// TxCommands is the data of CMDIndexTransaction, BSON.
+type TxCommands struct {
+	Ops []*TxCommand `bson:"ops"`
+}
+
+type TransactionReq struct {
+	Signature string
+	Params    string
+	Data      []byte
+}
+
+// TransactionRsp is the index answer to CMDIndexTransaction, json in params.
+// The ops are applied all or none: Committed is false when any failed, then
+// FailedOp is its index in the ops and code and msg are its result. FailedOp
+// is -1 once committed.
+type TransactionRsp struct {
+	Code int32             `json:"code"`
+	Msg  string            `json:"msg"`
+	Data TransactionResult `json:"data"`
+}
+
+type TransactionResult struct {
+	Committed bool        `json:"committed" bson:"committed"`
+	FailedOp  int32       `json:"failed_op" bson:"failed_op"`
+	Results   []*TxResult `json:"results" bson:"results"` // per op, when committed
+}
+
+// TxResult is the result of an op, Data the json data of its command.
+type TxResult struct {
+	Code int32  `json:"code" bson:"code"`
+	Msg  string `json:"msg" bson:"msg"`
+	Data string `json:"data,omitempty" bson:"data,omitempty"`
+}
@@ -11,10 +11,6 @@ For more information, please refer to https://www.web3password.com/web3password_
 package model
 
 import (
-	"fmt"
-	"time"
-
-	"github.com/web3password/jewel/tools"
 	"github.com/web3password/satis/util"
 )
 
@@ -47,6 +43,12 @@ const (
 	CMDIndexBatchCheckTx              = "100"
 	CMDIndexBatchAddCredential        = "101"
 	CMDIndexBatchDeleteCredential     = "102"
+	CMDIndexSyncCredentials           = "103"
+	CMDIndexVaultExportCredentials    = "104"
+	CMDIndexVaultExportRecords        = "105"
+	CMDIndexTransaction               = "106"
+	CMDIndexVaultImportCredentials    = "107"
+	CMDIndexVaultImportRecords        = "108"
 
 	CMDShareFolderCreate       = "26"
 	CMDShareFolderUpdate       = "27"
@@ -68,6 +70,8 @@ const (
 	CMDFileDownload   = "43"
 	CMDFileAttachment = "44"
 
+	CMDStorageVaultExportAttachments = "45"
+
 	CMDStorageReport = "38"
 	CMDStorageStat   = "39"
 
@@ -76,6 +80,10 @@ const (
 
 	CMDShareFolderRecordListByRid = "54"
 
+	CMDCheckOrgMember = "55"
+	// CMDNotify is published by the nodes, unasked, with node.token, satis never sends it
+	CMDNotify = "56"
+
 	CMDVipSubscriptionList    = "100"
 	CMDVipCreateOrder         = "101"
 	CMDVipCheckOrder          = "102"
@@ -94,6 +102,7 @@ const (
 	GetPersonalSignAddressToken = "getPersonalSignAddress"
 
 	GetCredentialToken              = "getCredential"
+	CheckTxToken                    = "checkTx"
 	AddCredentialToken              = "addCredential"
 	DelCredentialToken              = "delCredential"
 	DeleteAllCredentialToken        = "deleteAllCredential"
@@ -102,6 +111,7 @@ const (
 	IndexBatchCheckTxToken          = "batchCheckTx"
 	IndexBatchAddCredentialToken    = "batchAddCredential"
 	IndexBatchDeleteCredentialToken = "batchDeleteCredential"
+	SyncCredentialsToken            = "syncCredentials"
 
 	InitializeToken              = "userInit"
 	VersionDescToken             = "versionDesc"
@@ -140,6 +150,12 @@ const (
 	FileAttachmentToken             = "fileAttachment"
 	FileReportToken                 = "fileReport"
 	GetVersionConfigToken           = "getVersionConfig"
+	SessionToken                    = "session"
+	VaultExportToken                = "vaultExport"
+	VaultImportToken                = "vaultImport"
+	VaultImportStatusToken          = "vaultImportStatus"
+	TransactionToken                = "transaction"
+	EventsToken                     = "events"
 
 	VipGetConfigToken          = "vip-getConfig"
 	VipSubscriptionListToken   = "vip-subscriptionList"
@@ -257,281 +273,315 @@ type PersonalSignParams struct {
 
 type RegisterParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 }
 
 type InitializeParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 }
 
 type GetPersonalSignAddressParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 	// official_addrs
-	OfficialAddrs []string `json:"official_addrs"`
+	OfficialAddrs []string `json:"official_addrs" check:"required,max=10,itemmax=nonce"`
 }
 
 type GetVIPInfoParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 }
 
 type GetUserInfoParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// primary address
 	TagAddr string `json:"org_id"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 }
 
 type CheckTxParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
+	// token
+	Token string `json:"token" check:"token"`
 	// tx_hash
-	TxHash string `json:"hash"`
-	OrgId  string `json:"org_id"`
+	TxHash string `json:"hash" check:"required,max=nonce"`
+	OrgId  string `json:"org_id" check:"max=nonce"`
 }
 
 type BatchCheckTxParams struct {
-	Addr      string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
-	OrgId     string `json:"org_id"`
+	Addr      string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256"`
+	OrgId     string `json:"org_id" check:"max=nonce"`
 }
 
 type AddCredentialParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// op_timestamp
-	OpTimestamp int64 `json:"op_timestamp"`
+	OpTimestamp int64 `json:"op_timestamp" check:"required"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
+	// token
+	Token string `json:"token" check:"token"`
 	// id
-	ID string `json:"id"`
+	ID string `json:"id" check:"required,max=nonce"`
 	// credential
-	Hash  string `json:"hash"`
-	OrgId string `json:"org_id"`
+	Hash  string `json:"hash" check:"max=nonce,sha256"`
+	OrgId string `json:"org_id" check:"max=nonce"`
 }
 
 type BatchAddCredentialParams struct {
-	Addr      string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
-	OrgId     string `json:"org_id"`
+	Addr      string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256"`
+	OrgId     string `json:"org_id" check:"max=nonce"`
 }
 
 type DeleteCredentialParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// op_timestamp
 	OpTimestamp int64 `json:"op_timestamp"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
+	// token
+	Token string `json:"token" check:"token"`
 	// id
-	ID string `json:"id"`
+	ID string `json:"id" check:"required,max=nonce"`
 	// credential
-	Hash  string `json:"hash"`
-	OrgId string `json:"org_id"`
+	Hash  string `json:"hash" check:"max=nonce,sha256"`
+	OrgId string `json:"org_id" check:"max=nonce"`
 }
 
 type BatchDeleteCredentialParams struct {
-	Addr      string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
-	OrgId     string `json:"org_id"`
+	Addr      string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256"`
+	OrgId     string `json:"org_id" check:"max=nonce"`
 }
 
 type GetCredentialParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// id
-	ID string `json:"id"`
+	ID string `json:"id" check:"required,max=nonce"`
 	// token
-	Token string `json:"token"`
-	OrgId string `json:"org_id"`
+	Token string `json:"token" check:"token"`
+	OrgId string `json:"org_id" check:"max=nonce"`
 }
 
 type DeleteAllCredentialParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
-	OrgId string `json:"org_id"`
+	Token string `json:"token" check:"token"`
+	OrgId string `json:"org_id" check:"max=nonce"`
 }
 
 type GetAllCredentialTimestampParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
-	OrgId string `json:"org_id"`
+	Token string `json:"token" check:"token"`
+	OrgId string `json:"org_id" check:"max=nonce"`
+}
+
+// SyncCredentialsParams asks the changes of the vault after a cursor, the seq
+// of the last change the client has seen.
+type SyncCredentialsParams struct {
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	OrgId     string `json:"org_id" check:"max=nonce"`
+	Cursor    int64  `json:"cursor" check:"min=0"`          // 0 for a full sync
+	Limit     int32  `json:"limit" check:"min=0,max=batch"` // changes per page, 0 for the index default
 }
 
 type GetCredentialListParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// ids
-	IDs []string `json:"ids"`
+	IDs []string `json:"ids" check:"required,max=batch"`
 	// token
-	Token string `json:"token"`
-	OrgId string `json:"org_id"`
+	Token string `json:"token" check:"token"`
+	OrgId string `json:"org_id" check:"max=nonce"`
+	Page
 }
 
 type GetVersionDescParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// version
-	Version string `json:"version"`
+	Version string `json:"version" check:"required"`
 	// language
 	Language string `json:"language"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"required"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 }
 
 type AdminRegisterParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 	//auth
-	Auth string `json:"personal_auth"`
+	Auth string `json:"personal_auth" check:"required,max=general"`
+}
+
+// SessionParams exchanges a personal_auth of addr for a session token.
+type SessionParams struct {
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Auth      string `json:"personal_auth" check:"required,max=general"`
+}
+
+type SessionRsp struct {
+	SessionToken string `bson:"session_token" json:"session_token"`
+	ExpireAt     int64  `bson:"expire_at" json:"expire_at"`
 }
 
 type AdminRemoveMemberParams struct {
-	Address       string `json:"addr"`
-	Timestamp     int64  `json:"timestamp"`
-	Nonce         string `json:"nonce"`
-	Token         string `json:"token"`
-	TagAddress    string `json:"tag_address"`
-	MemberAddress string `json:"member_address"`
+	Address       string `json:"addr" check:"address"`
+	Timestamp     int64  `json:"timestamp" check:"required"`
+	Nonce         string `json:"nonce" check:"max=nonce"`
+	Token         string `json:"token" check:"token"`
+	TagAddress    string `json:"tag_address" check:"required,max=nonce"`
+	MemberAddress string `json:"member_address" check:"required,max=nonce"`
 }
 
 type AdminAddOrUpdateMemberParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"required"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 	// tag_address
-	TagAddress string `json:"tag_address"`
+	TagAddress string `json:"tag_address" check:"required,max=nonce"`
 	// member_address
-	MemberAddress string `json:"member_address"`
+	MemberAddress string `json:"member_address" check:"required"`
 	// member_data
-	MemberData string `json:"member_data"`
+	MemberData string `json:"member_data" check:"required,max=general"`
 	// member_share_mnemonic
-	MemberShareMnemonic string `json:"member_share_mnemonic"`
+	MemberShareMnemonic string `json:"member_share_mnemonic" check:"max=general"`
 	// admin_share_mnemonic
-	AdminShareMnemonic string `json:"admin_share_mnemonic"`
+	AdminShareMnemonic string `json:"admin_share_mnemonic" check:"max=general"`
 }
 
 type AdminGetMemberListParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"required"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 	// tag_address
-	TagAddress string `json:"tag_address"`
+	TagAddress string `json:"tag_address" check:"required,max=nonce"`
+	Page
 }
 
 type AdminAuthorizationParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	//tag_address
-	TagAddress string `json:"tag_address"`
+	TagAddress string `json:"tag_address" check:"address"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 }
 
 type TransferSuperAdminParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 	// credential
-	Hash string `json:"hash"`
+	Hash string `json:"hash" check:"max=nonce"`
 	//// sign
 	//Sign string `json:"sign"`
 	//// member_addr
@@ -545,25 +595,26 @@ type TransferSuperAdminParams struct {
 
 type OperationHistoryParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 	// view_addr
-	ViewAddress string `json:"view_addr"`
+	ViewAddress string `json:"view_addr" check:"max=nonce"`
 	// credential
-	Hash string `json:"hash"`
+	Hash string `json:"hash" check:"max=nonce"`
+	Page
 }
 
 type StorageReportParams struct {
 	InitializeParams
 	// action
-	Action string `json:"action"`
+	Action string `json:"action" check:"oneof=incr|decr"`
 	// amount
-	Amount int64 `json:"amount"`
+	Amount int64 `json:"amount" check:"required"`
 }
 
 type StorageStatParams struct {
@@ -572,33 +623,33 @@ type StorageStatParams struct {
 
 type AdminGetOrgInfoParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"timestamp"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 }
 
 type GetAdminMnemonicParams struct {
 	// primary address
-	Address string `json:"addr"`
+	Address string `json:"addr" check:"address"`
 	// tag_address
-	TagAddress string `json:"tag_address"`
+	TagAddress string `json:"tag_address" check:"required,max=nonce"`
 	// timestamp
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" check:"required"`
 	// nonce
-	Nonce string `json:"nonce"`
+	Nonce string `json:"nonce" check:"max=nonce"`
 	// token
-	Token string `json:"token"`
+	Token string `json:"token" check:"token"`
 }
 
 type AdminBatchImportMemberParams struct {
-	Addr       string `json:"addr"`
-	Timestamp  int64  `json:"timestamp"`
-	Nonce      string `json:"nonce"`
-	Token      string `json:"token"`
+	Addr       string `json:"addr" check:"address"`
+	Timestamp  int64  `json:"timestamp" check:"required"`
+	Nonce      string `json:"nonce" check:"max=nonce"`
+	Token      string `json:"token" check:"token"`
 	MemberList []struct {
 		TagAddress          string `json:"tag_address"`
 		MemberAddress       string `json:"member_address"`
@@ -609,42 +660,15 @@ type AdminBatchImportMemberParams struct {
 }
 
 type GetVersionConfigParams struct {
-	Address   string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
-	Hash      string `json:"hash"`
-}
-
-func (s GetVersionConfigParams) Check(token string, timestamp int64, data []byte) (string, bool) {
-	nowTimestamp := time.Now().Unix()
-
-	if !tools.IsValidAddress(s.Address) {
-		return "invalid address " + s.Address, false
-	}
-	if s.Timestamp == 0 {
-		msg := fmt.Sprintf("invalid timestamp , req timestamp is zero : %d", s.Timestamp)
-		return msg, false
-	}
-	if timestamp > nowTimestamp {
-		msg := fmt.Sprintf("invalid timestamp(client time>system time) , server timestamp=%d, client timestamp=%d", nowTimestamp, s.Timestamp)
-		return msg, false
-	}
-	if nowTimestamp-timestamp > util.W3PTimeout {
-		msg := fmt.Sprintf("invalid timestamp(client timeout, above 5s) , server timestamp=%d, client timestamp=%d", nowTimestamp, s.Timestamp)
-		return msg, false
-	}
-	if s.Token != token {
-		return "invalid token " + s.Token, false
-	}
-
-	if len(data) > 0 {
-		if isEqual, serverHash := tools.CompareHash(data, s.Hash); !isEqual {
-			return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, s.Hash), false
-		}
-	}
-
-	return "", true
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=optional"`
+}
+
+func (s GetVersionConfigParams) Check(token string, data []byte) (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{token}, Data: data})
 }
 
 type GetVersionData struct {
@@ -670,596 +694,122 @@ type GetVersionConfigRsp struct {
 	OneRecordSizeLimit            int64  `json:"one_record_size_limit" bson:"one_record_size_limit"`
 }
 
-func (r AdminGetOrgInfoParams) Check() bool {
-	if !tools.IsValidAddress(r.Address) {
-		return false
-	}
-	if r.Timestamp == 0 {
-		return false
-	}
-
-	if len(r.Nonce) > util.W3PMaxNonceLength {
-		return false
-	}
-
-	return true
+func (r AdminGetOrgInfoParams) Check(token string) (string, bool) {
+	return checkParams(r, CheckInput{Tokens: []string{token}})
 }
 
 func (r RegisterParams) Check() (string, bool) {
-	if !tools.IsValidAddress(r.Address) {
-		return "invalid address " + r.Address, false
-	}
-	if !util.CheckTimestamp(r.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), r.Timestamp)
-		return msg, false
-	}
-	if r.Token != RegisterToken {
-		return "invalid token " + r.Token, false
-	}
-
-	if len(r.Nonce) > util.W3PMaxNonceLength {
-		return "invalid nonce", false
-	}
-
-	return "", true
-}
-
-func (r InitializeParams) Check() bool {
-	if !tools.IsValidAddress(r.Address) {
-		return false
-	}
-	if r.Timestamp == 0 {
-		return false
-	}
-	if r.Token != InitializeToken {
-		return false
-	}
-
-	if len(r.Nonce) > util.W3PMaxNonceLength {
-		return false
-	}
-	return true
+	return checkParams(r, CheckInput{Tokens: []string{RegisterToken}})
+}
+
+func (r InitializeParams) Check() (string, bool) {
+	return checkParams(r, CheckInput{Tokens: []string{InitializeToken}})
 }
 
 func (r GetPersonalSignAddressParams) Check() (string, bool) {
-	if !tools.IsValidAddress(r.Address) {
-		return "invalid address " + r.Address, false
-	}
-	if !util.CheckTimestamp(r.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), r.Timestamp)
-		return msg, false
-	}
-	if r.Token != GetPersonalSignAddressToken {
-		return "invalid token " + r.Token, false
-	}
-	if len(r.OfficialAddrs) == 0 || len(r.OfficialAddrs) > 10 {
-		return "invalid official_address list", false
-	}
-
-	if len(r.Nonce) > util.W3PMaxNonceLength {
-		return "invalid nonce or hash", false
-	}
-
-	for i := 0; i < len(r.OfficialAddrs); i++ {
-		if len(r.OfficialAddrs[i]) > util.W3PMaxNonceLength {
-			return "invalid official_addr " + r.OfficialAddrs[i], false
-		}
-	}
-
-	return "", true
+	return checkParams(r, CheckInput{Tokens: []string{GetPersonalSignAddressToken}})
 }
 
 func (r GetVIPInfoParams) Check() (string, bool) {
-	if !tools.IsValidAddress(r.Address) {
-		return "invalid address " + r.Address, false
-	}
-	if !util.CheckTimestamp(r.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), r.Timestamp)
-		return msg, false
-	}
-	if r.Token != GetVIPInfoToken {
-		return "invalid token " + r.Token, false
-	}
-
-	if len(r.Nonce) > util.W3PMaxNonceLength {
-		return "invalid nonce or hash", false
-	}
-
-	return "", true
+	return checkParams(r, CheckInput{Tokens: []string{GetVIPInfoToken}})
 }
 
 func (r GetUserInfoParams) Check() (string, bool) {
-	if !tools.IsValidAddress(r.Address) {
-		return "invalid address " + r.Address, false
-	}
-	if !util.CheckTimestamp(r.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), r.Timestamp)
-		return msg, false
-	}
-	if r.Token != GetUserInfoToken {
-		return "invalid token " + r.Token, false
-	}
-	if len(r.Nonce) > util.W3PMaxNonceLength {
-		return "invalid nonce", false
-	}
-	return "", true
+	return checkParams(r, CheckInput{Tokens: []string{GetUserInfoToken}})
 }
 
 func (c CheckTxParams) Check() (string, bool) {
-	if !tools.IsValidAddress(c.Address) {
-		return "invalid address " + c.Address, false
-	}
-
-	if !util.CheckTimestamp(c.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), c.Timestamp)
-		return msg, false
-	}
-	if c.TxHash == "" {
-		return "empty hash", false
-	}
-
-	if len(c.Nonce) > util.W3PMaxNonceLength || len(c.OrgId) > util.W3PMaxNonceLength || len(c.TxHash) > util.W3PMaxNonceLength {
-		return "invalid nonce or orgid or txhash", false
-	}
-	return "", true
+	return checkParams(c, CheckInput{Tokens: []string{CheckTxToken}})
 }
 
 func (c BatchCheckTxParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(c.Addr) {
-		return "invalid address " + c.Addr, false
-	}
-	if !util.CheckTimestamp(c.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), c.Timestamp)
-		return msg, false
-	}
-
-	if isEqual, serverHash := tools.CompareHash(data, c.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, c.Hash), false
-	}
-
-	if c.Token != IndexBatchCheckTxToken {
-		return "invalid token " + c.Token, false
-	}
-
-	if len(c.Nonce) > util.W3PMaxNonceLength || len(c.OrgId) > util.W3PMaxNonceLength || len(c.Hash) > util.W3PMaxNonceLength {
-		return "invalid nonce or orgid or hash", false
-	}
-
-	return "", true
+	return checkParams(c, CheckInput{Tokens: []string{IndexBatchCheckTxToken}, Data: data})
 }
 
 func (a AddCredentialParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(a.Address) {
-		return "invalid address " + a.Address, false
-	}
-	if a.OpTimestamp == 0 {
-		return "invalid op_timestamp " + a.Address, false
-	}
-	if !util.CheckTimestamp(a.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), a.Timestamp)
-		return msg, false
-	}
-	if isEqual, serverHash := tools.CompareHash(data, a.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, a.Hash), false
-	}
-	if a.ID == "" {
-		return "invalid id", false
-	}
-
-	if len(a.ID) > util.W3PMaxNonceLength || len(a.Nonce) > util.W3PMaxNonceLength || len(a.OrgId) > util.W3PMaxNonceLength || len(a.Hash) > util.W3PMaxNonceLength || len(data) > util.W3PMaxRecordLength {
-		return "invalid id or nonce or orgid or hash or record-length", false
-	}
-
-	return "", true
+	return checkParams(a, CheckInput{Tokens: []string{AddCredentialToken}, Data: data, MaxData: util.W3PMaxRecordLength})
 }
 
 func (c BatchAddCredentialParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(c.Addr) {
-		return "invalid address " + c.Addr, false
-	}
-	if !util.CheckTimestamp(c.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), c.Timestamp)
-		return msg, false
-	}
-	if isEqual, serverHash := tools.CompareHash(data, c.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, c.Hash), false
-	}
-	if c.Token != IndexBatchAddCredentialToken {
-		return "invalid token " + c.Hash, false
-	}
-
-	if len(c.Nonce) > util.W3PMaxNonceLength || len(c.OrgId) > util.W3PMaxNonceLength || len(c.Hash) > util.W3PMaxNonceLength || len(data) > util.W3PMaxBodyLength {
-		return "invalid nonce or orgid or hash or data-length", false
-	}
-
-	return "", true
+	return checkParams(c, CheckInput{Tokens: []string{IndexBatchAddCredentialToken}, Data: data, MaxData: util.W3PMaxBodyLength})
 }
 
 func (d DeleteCredentialParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(d.Address) {
-		return "invalid address " + d.Address, false
-	}
-	if !util.CheckTimestamp(d.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), d.Timestamp)
-		return msg, false
-	}
-	if isEqual, serverHash := tools.CompareHash(data, d.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, d.Hash), false
-	}
-	if d.ID == "" {
-		return "invalid id ", false
-	}
-
-	if len(d.ID) > util.W3PMaxNonceLength || len(d.Nonce) > util.W3PMaxNonceLength || len(d.OrgId) > util.W3PMaxNonceLength || len(d.Hash) > util.W3PMaxNonceLength || len(data) > util.W3PMaxNonceLength {
-		return "invalid id or nonce or orgid or hash or data-length", false
-	}
-
-	return "", true
+	return checkParams(d, CheckInput{Tokens: []string{DelCredentialToken}, Data: data, MaxData: util.W3PMaxNonceLength})
 }
 
 func (c BatchDeleteCredentialParams) Check(data []byte) (string, bool) {
-	if !tools.IsValidAddress(c.Addr) {
-		return "invalid address " + c.Addr, false
-	}
-	if !util.CheckTimestamp(c.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), c.Timestamp)
-		return msg, false
-	}
-	if isEqual, serverHash := tools.CompareHash(data, c.Hash); !isEqual {
-		return fmt.Sprintf("invalid hash, server hash=%s, client hash=%s", serverHash, c.Hash), false
-	}
-	if c.Token != IndexBatchDeleteCredentialToken {
-		return "invalid token " + c.Token, false
-	}
-
-	if len(c.Nonce) > util.W3PMaxNonceLength || len(c.OrgId) > util.W3PMaxNonceLength || len(c.Hash) > util.W3PMaxNonceLength || len(data) > util.W3PMaxBodyLength {
-		return "invalid nonce or orgid or hash", false
-	}
-
-	return "", true
+	return checkParams(c, CheckInput{Tokens: []string{IndexBatchDeleteCredentialToken}, Data: data, MaxData: util.W3PMaxBodyLength})
 }
 
 func (g GetCredentialParams) Check() (string, bool) {
-	if !tools.IsValidAddress(g.Address) {
-		return "invalid address " + g.Address, false
-	}
-	if !util.CheckTimestamp(g.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), g.Timestamp)
-		return msg, false
-	}
-	if g.ID == "" {
-		return "invalid id ", false
-	}
-	if g.Token != GetCredentialToken {
-		return "invalid token " + g.Token, false
-	}
-
-	if len(g.ID) > util.W3PMaxNonceLength || len(g.Nonce) > util.W3PMaxNonceLength || len(g.OrgId) > util.W3PMaxNonceLength {
-		return "invalid id or nonce or orgid", false
-	}
-
-	return "", true
+	return checkParams(g, CheckInput{Tokens: []string{GetCredentialToken}})
 }
 
 func (d DeleteAllCredentialParams) Check() (string, bool) {
-	if !tools.IsValidAddress(d.Address) {
-		return "invalid address " + d.Address, false
-	}
-	if !util.CheckTimestamp(d.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), d.Timestamp)
-		return msg, false
-	}
-	if d.Token != DeleteAllCredentialToken {
-		return "invalid token " + d.Token, false
-	}
-
-	if len(d.Nonce) > util.W3PMaxNonceLength || len(d.OrgId) > util.W3PMaxNonceLength {
-		return "invalid nonce or orgid", false
-	}
-
-	return "", true
+	return checkParams(d, CheckInput{Tokens: []string{DeleteAllCredentialToken}})
 }
 
 func (g GetAllCredentialTimestampParams) Check() (string, bool) {
-	if !tools.IsValidAddress(g.Address) {
-		return "invalid address " + g.Address, false
-	}
-	if !util.CheckTimestamp(g.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), g.Timestamp)
-		return msg, false
-	}
-	if g.Token != GetAllCredentialTimestampToken {
-		return "invalid token " + g.Token, false
-	}
+	return checkParams(g, CheckInput{Tokens: []string{GetAllCredentialTimestampToken}})
+}
 
-	if len(g.Nonce) > util.W3PMaxNonceLength || len(g.OrgId) > util.W3PMaxNonceLength {
-		return "invalid nonce or orgid", false
-	}
+func (g GetCredentialListParams) Check() (string, bool) {
+	return checkParams(g, CheckInput{Tokens: []string{GetCredentialListToken}})
+}
 
-	return "", true
+func (s SyncCredentialsParams) Check() (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{SyncCredentialsToken}})
 }
 
-func (g GetCredentialListParams) Check() (string, bool) {
-	if !tools.IsValidAddress(g.Address) {
-		return "invalid address " + g.Address, false
-	}
-	if !util.CheckTimestamp(g.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), g.Timestamp)
-		return msg, false
-	}
-	if len(g.IDs) == 0 {
-		return "invalid ids", false
-	}
-	if len(g.IDs) > util.W3PMaxBatchRecordNumber {
-		msg := fmt.Sprintf("max length ids is %d", util.W3PMaxBatchRecordNumber)
-		return msg, false
-	}
-	if g.Token != GetCredentialListToken {
-		return "invalid token " + g.Token, false
-	}
-
-	if len(g.Nonce) > util.W3PMaxNonceLength || len(g.OrgId) > util.W3PMaxNonceLength {
-		return "invalid nonce or orgid", false
-	}
-
-	return "", true
-}
-
-func (g GetVersionDescParams) Check() bool {
-	if !tools.IsValidAddress(g.Address) {
-		return false
-	}
-	if g.Timestamp == 0 {
-		return false
-	}
-	if g.Version == "" {
-		return false
-	}
-	if g.Token != VersionDescToken {
-		return false
-	}
-
-	if len(g.Nonce) > util.W3PMaxNonceLength {
-		return false
-	}
-
-	return true
-}
-
-func (a AdminAddOrUpdateMemberParams) Check() bool {
-	if !tools.IsValidAddress(a.Address) {
-		return false
-	}
-	if a.Timestamp == 0 {
-		return false
-	}
-	if a.MemberAddress == "" {
-		return false
-	}
-	if a.TagAddress == "" {
-		return false
-	}
-	if a.MemberData == "" {
-		return false
-	}
-	//if a.Token != AdminAddMemberToken {
-	//	return false
-	//}
-
-	if len(a.Nonce) > util.W3PMaxNonceLength || len(a.TagAddress) > util.W3PMaxNonceLength || len(a.AdminShareMnemonic) > util.W3PMaxGeneralLenth || len(a.MemberShareMnemonic) > util.W3PMaxGeneralLenth || len(a.MemberData) > util.W3PMaxGeneralLenth {
-		return false
-	}
-
-	return true
+func (g GetVersionDescParams) Check() (string, bool) {
+	return checkParams(g, CheckInput{Tokens: []string{VersionDescToken}})
+}
+
+func (a AdminAddOrUpdateMemberParams) Check(token string) (string, bool) {
+	return checkParams(a, CheckInput{Tokens: []string{token}})
 }
 
 func (r AdminRegisterParams) Check() (string, bool) {
-	if !tools.IsValidAddress(r.Address) {
-		return "invalid address " + r.Address, false
-	}
-	if r.Token != AdminRegisterToken {
-		return "invalid token " + r.Token, false
-	}
-	if !util.CheckTimestamp(r.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), r.Timestamp)
-		return msg, false
-	}
-	if r.Auth == "" {
-		return "invalid auth " + r.Auth, false
-	}
-
-	if len(r.Nonce) > util.W3PMaxNonceLength || len(r.Auth) > util.W3PMaxGeneralLenth {
-		return "invalid nonce or auth", false
-	}
-
-	return "", true
-}
-
-func (t TransferSuperAdminParams) Check() bool {
-	if !tools.IsValidAddress(t.Address) {
-		return false
-	}
-	if !util.CheckTimestamp(t.Timestamp) {
-		return false
-	}
-	if t.Token != AdminTransferSuperAdminToken {
-		return false
-	}
-	if len(t.Nonce) > util.W3PMaxNonceLength || len(t.Hash) > util.W3PMaxNonceLength {
-		return false
-	}
-	return true
-}
-
-func (t OperationHistoryParams) Check() bool {
-	if !tools.IsValidAddress(t.Address) {
-		return false
-	}
-	if t.Timestamp == 0 {
-		return false
-	}
-	if t.Token != AdminOperationHistoryToken {
-		return false
-	}
-
-	if len(t.Nonce) > util.W3PMaxNonceLength || len(t.Hash) > util.W3PMaxNonceLength || len(t.ViewAddress) > util.W3PMaxNonceLength {
-		return false
-	}
-	return true
-}
-
-func (t AdminAuthorizationParams) Check() bool {
-	if !tools.IsValidAddress(t.Address) || !tools.IsValidAddress(t.TagAddress) {
-		return false
-	}
-
-	if len(t.Nonce) > util.W3PMaxNonceLength {
-		return false
-	}
-
-	if t.Timestamp == 0 {
-		return false
-	}
-	if t.Token != AadminAuthorizationToken {
-		return false
-	}
-	if len(t.Nonce) > util.W3PMaxNonceLength {
-		return false
-	}
-	return true
-}
-
-func (a AdminGetMemberListParams) Check() bool {
-	if !tools.IsValidAddress(a.Address) {
-		return false
-	}
-	if a.Timestamp == 0 {
-		return false
-	}
-	if a.TagAddress == "" {
-		return false
-	}
-	if a.Token != AdminGetMemberListToken {
-		return false
-	}
-
-	if len(a.Nonce) > util.W3PMaxNonceLength || len(a.TagAddress) > util.W3PMaxNonceLength {
-		return false
-	}
-
-	return true
-}
-
-func (g GetAdminMnemonicParams) Check() bool {
-	if !tools.IsValidAddress(g.Address) {
-		return false
-	}
-	if g.Timestamp == 0 {
-		return false
-	}
-	if g.TagAddress == "" {
-		return false
-	}
-	if g.Token != AdminGetAdminMnemonicToken {
-		return false
-	}
-
-	if len(g.Nonce) > util.W3PMaxNonceLength || len(g.TagAddress) > util.W3PMaxNonceLength {
-		return false
-	}
-
-	return true
-}
-
-func (a AdminBatchImportMemberParams) Check() bool {
-	if !tools.IsValidAddress(a.Addr) {
-		return false
-	}
-	if a.Timestamp == 0 {
-		return false
-	}
-	if a.Token != AdminBatchImportMemberToken {
-		return false
-	}
-	if len(a.MemberList) < 0 {
-		return false
-	}
-
-	if len(a.Nonce) > util.W3PMaxNonceLength {
-		return false
-	}
-
-	return true
-}
-
-func (a AdminRemoveMemberParams) Check() bool {
-	if !tools.IsValidAddress(a.Address) {
-		return false
-	}
-	if a.Timestamp == 0 {
-		return false
-	}
-	if a.Token != AdminRemoveMemberToken {
-		return false
-	}
-	if a.TagAddress == "" {
-		return false
-	}
-	if a.MemberAddress == "" {
-		return false
-	}
-
-	if len(a.Nonce) > util.W3PMaxNonceLength || len(a.TagAddress) > util.W3PMaxNonceLength || len(a.MemberAddress) > util.W3PMaxNonceLength {
-		return false
-	}
-
-	return true
+	return checkParams(r, CheckInput{Tokens: []string{AdminRegisterToken}})
 }
 
-func (s StorageReportParams) Check() (string, bool) {
-	if !tools.IsValidAddress(s.Address) {
-		return "invalid address " + s.Address, false
-	}
-	if s.Action != "incr" && s.Action != "decr" {
-		return "invalid action " + s.Action, false
-	}
+func (t TransferSuperAdminParams) Check() (string, bool) {
+	return checkParams(t, CheckInput{Tokens: []string{AdminTransferSuperAdminToken}})
+}
 
-	if s.Amount == 0 {
-		return "invalid amount", false
-	}
-	if s.Token != StorageReportToken {
-		return "invalid token " + s.Token, false
-	}
+func (t OperationHistoryParams) Check() (string, bool) {
+	return checkParams(t, CheckInput{Tokens: []string{AdminOperationHistoryToken}})
+}
 
-	if !util.CheckTimestamp(s.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), s.Timestamp)
-		return msg, false
-	}
+func (t AdminAuthorizationParams) Check() (string, bool) {
+	return checkParams(t, CheckInput{Tokens: []string{AadminAuthorizationToken}})
+}
 
-	if len(s.Nonce) > util.W3PMaxNonceLength {
-		return "invalid nonce", false
-	}
+func (a AdminGetMemberListParams) Check() (string, bool) {
+	return checkParams(a, CheckInput{Tokens: []string{AdminGetMemberListToken}})
+}
 
-	return "", true
+func (g GetAdminMnemonicParams) Check() (string, bool) {
+	return checkParams(g, CheckInput{Tokens: []string{AdminGetAdminMnemonicToken}})
 }
 
-func (s StorageStatParams) Check() (string, bool) {
-	if !tools.IsValidAddress(s.Address) {
-		return "invalid address " + s.Address, false
-	}
+func (a AdminBatchImportMemberParams) Check() (string, bool) {
+	return checkParams(a, CheckInput{Tokens: []string{AdminBatchImportMemberToken}})
+}
 
-	if s.Token != StorageStatToken {
-		return "invalid token " + s.Token, false
-	}
+func (a AdminRemoveMemberParams) Check() (string, bool) {
+	return checkParams(a, CheckInput{Tokens: []string{AdminRemoveMemberToken}})
+}
 
-	if !util.CheckTimestamp(s.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), s.Timestamp)
-		return msg, false
-	}
+func (s StorageReportParams) Check() (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{StorageReportToken}})
+}
 
-	if len(s.Nonce) > util.W3PMaxNonceLength {
-		return "invalid nonce", false
-	}
+func (s SessionParams) Check() (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{SessionToken}})
+}
 
-	return "", true
+func (s StorageStatParams) Check() (string, bool) {
+	return checkParams(s, CheckInput{Tokens: []string{StorageStatToken}})
 }
@@ -0,0 +1,142 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package model
+
+// VaultExportParams are sent with every page asked to the nodes, so an export
+// ends before the timestamp leaves the window of the nodes. ResumeKind and
+// ResumeCursor continue a previous export from the resume of its manifest.
+type VaultExportParams struct {
+	Address      string `json:"addr" check:"address"`
+	Timestamp    int64  `json:"timestamp" check:"timestamp"`
+	Nonce        string `json:"nonce" check:"max=nonce"`
+	Token        string `json:"token" check:"token"`
+	OrgId        string `json:"org_id" check:"max=nonce"`
+	ResumeKind   string `json:"resume_kind" check:"oneof=|credential|record|attachment"`
+	ResumeCursor string `json:"resume_cursor" check:"max=general"`
+}
+
+// VaultImportParams carry the sha256 of the archive in data. They are sent
+// with every vault import command, the nodes check the hash against the
+// archive_hash of the batch.
+type VaultImportParams struct {
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp=upload"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	OrgId     string `json:"org_id" check:"max=nonce"`
+	Hash      string `json:"hash" check:"max=nonce,sha256=required"`
+}
+
+type VaultImportStatusParams struct {
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
+	JobId     string `json:"job_id" check:"required,max=nonce"`
+}
+
+func (v VaultExportParams) Check() (string, bool) {
+	if errMsg, ok := checkParams(v, CheckInput{Tokens: []string{VaultExportToken}}); !ok {
+		return errMsg, false
+	}
+	if v.ResumeCursor != "" && v.ResumeKind == "" {
+		return "invalid resume_cursor: resume_kind is required", false
+	}
+	return "", true
+}
+
+func (v VaultImportParams) Check(data []byte) (string, bool) {
+	return checkParams(v, CheckInput{Tokens: []string{VaultImportToken}, Data: data})
+}
+
+func (v VaultImportStatusParams) Check() (string, bool) {
+	return checkParams(v, CheckInput{Tokens: []string{VaultImportStatusToken}})
+}
+
+type VaultReq struct {
+	Signature string
+	Params    string
+	Data      []byte
+}
+
+// VaultExportRsp is a page of the answer of a node to a vault export command,
+// json in params. The cursor of the page asked is sent in the data of the command.
+type VaultExportRsp struct {
+	Code int32           `json:"code"`
+	Msg  string          `json:"msg"`
+	Data VaultExportPage `json:"data"`
+}
+
+type VaultExportPage struct {
+	Items      []*VaultItem `json:"items"`
+	NextCursor string       `json:"next_cursor"` // empty on the last page
+}
+
+// VaultItem is a stored blob, the file of an attachment is referenced by Size
+// and SHA256 only.
+type VaultItem struct {
+	Id          string `json:"id"`
+	FolderId    string `json:"folder_id,omitempty"`
+	OpTimestamp int64  `json:"op_timestamp,omitempty"`
+	Data        []byte `json:"data,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+}
+
+// VaultImportBatch is the data of a vault import command, BSON. The command
+// carries the signed import params, the node applies the items only when
+// their hash is ArchiveHash and every item matches its SHA256.
+type VaultImportBatch struct {
+	JobId       string             `bson:"job_id"`
+	ArchiveHash string             `bson:"archive_hash"`
+	Items       []*VaultImportItem `bson:"items"`
+}
+
+// VaultImportItem is a credential or a record of the archive, FolderId is
+// set on the records only.
+type VaultImportItem struct {
+	Id          string `bson:"id"`
+	FolderId    string `bson:"folder_id,omitempty"`
+	OpTimestamp int64  `bson:"op_timestamp,omitempty"`
+	Data        []byte `bson:"data"`
+	SHA256      string `bson:"sha256"`
+}
+
+// VaultImportBatchRsp is the answer of a node to a vault import command, json
+// in params. Failed counts the items of the batch the node refused.
+type VaultImportBatchRsp struct {
+	Code int32                `json:"code"`
+	Msg  string               `json:"msg"`
+	Data VaultImportBatchDone `json:"data"`
+}
+
+type VaultImportBatchDone struct {
+	Failed int64 `json:"failed"`
+}
+
+// VaultImportProgress is the state of an import, Total and Done count the
+// credentials and records replayed, the attachments are not.
+type VaultImportProgress struct {
+	JobId     string `json:"job_id" bson:"job_id"`
+	Addr      string `json:"addr" bson:"addr"`
+	State     string `json:"state" bson:"state"`
+	Total     int64  `json:"total" bson:"total"`
+	Done      int64  `json:"done" bson:"done"`
+	Failed    int64  `json:"failed" bson:"failed"`
+	Error     string `json:"error,omitempty" bson:"error,omitempty"`
+	StartedAt int64  `json:"started_at" bson:"started_at"`
+	UpdatedAt int64  `json:"updated_at" bson:"updated_at"`
+}
+
+type VaultImportRsp struct {
+	Code int32
+	Msg  string
+	Data VaultImportProgress
+}
@@ -11,75 +11,32 @@ For more information, please refer to https://www.web3password.com/web3password_
 package model
 
 import (
-	"fmt"
-	"github.com/web3password/jewel/tools"
 	"github.com/web3password/satis/consts"
-	"github.com/web3password/satis/util"
-	"time"
 )
 
 type VipGetConfigParams struct {
-	Address   string `json:"addr"`
-	Timestamp int64  `json:"timestamp"`
-	Nonce     string `json:"nonce"`
-	Token     string `json:"token"`
+	Address   string `json:"addr" check:"address"`
+	Timestamp int64  `json:"timestamp" check:"timestamp"`
+	Nonce     string `json:"nonce" check:"max=nonce"`
+	Token     string `json:"token" check:"token"`
 }
 
 func (v VipGetConfigParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return fmt.Sprintf("invalid params address(%s)", v.Address), false
-	}
-
-	if v.Token != VipGetConfigToken {
-		return fmt.Sprintf("invalid params token(%s)", v.Token), false
-	}
-
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength {
-		return "invalid nonce", false
-	}
-
-	return "", true
+	return checkParams(v, CheckInput{Tokens: []string{VipGetConfigToken}})
 }
 
 type VipSubscriptionListParams struct {
-	Address   string         `json:"addr"`
-	Timestamp int64          `json:"timestamp"`
-	Nonce     string         `json:"nonce"`
-	Token     string         `json:"token"`
-	OrgId     string         `json:"org_id"`
-	App       consts.AppType `json:"app"`
-	Auth      string         `json:"personal_auth"`
+	Address   string         `json:"addr" check:"address"`
+	Timestamp int64          `json:"timestamp" check:"timestamp"`
+	Nonce     string         `json:"nonce" check:"max=nonce"`
+	Token     string         `json:"token" check:"token"`
+	OrgId     string         `json:"org_id" check:"max=nonce"`
+	App       consts.AppType `json:"app" check:"valid"`
+	Auth      string         `json:"personal_auth" check:"max=general"`
 }
 
 func (v VipSubscriptionListParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return "invalid address " + v.Address, false
-	}
-
-	if v.Token != VipSubscriptionListToken {
-		return "invalid token" + v.Token, false
-	}
-
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.OrgId) > util.W3PMaxNonceLength || len(v.Auth) > util.W3PMaxGeneralLenth {
-		return "invalid nonce or orgid or auth", false
-	}
-
-	if !v.App.Check() {
-		msg := fmt.Sprintf("invalid request params app=%s", v.App)
-		return msg, false
-	}
-
-	return "", true
+	return checkParams(v, CheckInput{Tokens: []string{VipSubscriptionListToken}})
 }
 
 type VipSubscriptionListRspDataItem struct {
@@ -108,39 +65,17 @@ type VipGetConfigRspData struct {
 }
 
 type VipPaymentListParams struct {
-	Address   string         `json:"addr"`
-	Timestamp int64          `json:"timestamp"`
-	Nonce     string         `json:"nonce"`
-	Token     string         `json:"token"`
-	App       consts.AppType `json:"app"`
+	Address   string         `json:"addr" check:"address"`
+	Timestamp int64          `json:"timestamp" check:"timestamp"`
+	Nonce     string         `json:"nonce" check:"max=nonce"`
+	Token     string         `json:"token" check:"token"`
+	App       consts.AppType `json:"app" check:"valid"`
 	Version   string         `json:"version"`
-	Auth      string         `json:"personal_auth"`
+	Auth      string         `json:"personal_auth" check:"max=general"`
 }
 
 func (v VipPaymentListParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return "invalid address " + v.Address, false
-	}
-
-	if v.Token != VipPaymentListToken {
-		return "invalid token" + v.Token, false
-	}
-
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.Auth) > util.W3PMaxGeneralLenth {
-		return "invalid nonce or auth", false
-	}
-
-	if !v.App.Check() {
-		msg := fmt.Sprintf("invalid request params app=%s", v.App)
-		return msg, false
-	}
-
-	return "", true
+	return checkParams(v, CheckInput{Tokens: []string{VipPaymentListToken}})
 }
 
 type VipCreteOrderRspData struct {
@@ -151,38 +86,16 @@ type VipCreteOrderRspData struct {
 }
 
 type VipCreateOrderParams struct {
-	Address   string         `json:"addr"`
-	Timestamp int64          `json:"timestamp"`
-	Nonce     string         `json:"nonce"`
-	Token     string         `json:"token"`
-	App       consts.AppType `json:"app"`
-	Auth      string         `json:"personal_auth"`
+	Address   string         `json:"addr" check:"address"`
+	Timestamp int64          `json:"timestamp" check:"timestamp"`
+	Nonce     string         `json:"nonce" check:"max=nonce"`
+	Token     string         `json:"token" check:"token"`
+	App       consts.AppType `json:"app" check:"valid"`
+	Auth      string         `json:"personal_auth" check:"max=general"`
 }
 
 func (v VipCreateOrderParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return "invalid address " + v.Address, false
-	}
-
-	if v.Token != VipCreateOrderToken && v.Token != VipPrice {
-		return "invalid token " + v.Token, false
-	}
-
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.Auth) > util.W3PMaxGeneralLenth {
-		return "invalid nonce or auth", false
-	}
-
-	if !v.App.Check() {
-		msg := fmt.Sprintf("invalid request params app=%s", v.App)
-		return msg, false
-	}
-
-	return "", true
+	return checkParams(v, CheckInput{Tokens: []string{VipCreateOrderToken, VipPrice}})
 }
 
 type VipPaymentListRspData struct {
@@ -216,49 +129,23 @@ type SkuItem struct {
 }
 
 type VipCheckOrderParams struct {
-	Address      string `json:"addr"`
-	Timestamp    int64  `json:"timestamp"`
-	Nonce        string `json:"nonce"`
-	Token        string `json:"token"`
-	OrgId        string `json:"org_id"`
-	Platform     string `json:"platform"`
-	OriginData   string `json:"origin_data"`
-	PersonalAuth string `json:"personal_auth"`
+	Address      string `json:"addr" check:"address"`
+	Timestamp    int64  `json:"timestamp" check:"timestamp"`
+	Nonce        string `json:"nonce" check:"max=nonce"`
+	Token        string `json:"token" check:"token"`
+	OrgId        string `json:"org_id" check:"required,max=nonce"`
+	Platform     string `json:"platform" check:"required"`
+	OriginData   string `json:"origin_data" check:"max=record"`
+	PersonalAuth string `json:"personal_auth" check:"required,max=general"`
 }
 
 func (v VipCheckOrderParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return fmt.Sprintf("invalid params address(%v)", v.Address), false
-	}
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-
-	if v.Token != VipCheckOrderToken {
-		return fmt.Sprintf("invalid params token(%v)", v.Token), false
-	}
-	if v.OrgId == "" {
-		return fmt.Sprintf("invalid params org_id(%v)", v.OrgId), false
-	}
-	if v.PersonalAuth == "" {
-		return fmt.Sprintf("invalid params personal_auth(%v)", v.PersonalAuth), false
+	if errMsg, ok := checkParams(v, CheckInput{Tokens: []string{VipCheckOrderToken}}); !ok {
+		return errMsg, false
 	}
-	if v.Platform == "" {
-		return fmt.Sprintf("invalid params platform(%v)", v.Platform), false
+	if v.Platform == consts.PLATFORM_GOOGLE && v.OriginData == "" {
+		return "invalid origin_data: empty", false
 	}
-
-	if v.Platform == consts.PLATFORM_GOOGLE {
-		if v.OriginData == "" {
-			msg := fmt.Sprintf("invalid params origin_data value=%s", v.OriginData)
-			return msg, false
-		}
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.OrgId) > util.W3PMaxNonceLength || len(v.PersonalAuth) > util.W3PMaxGeneralLenth || len(v.OriginData) > util.W3PMaxRecordLength {
-		return "invalid nonce or orgid or auth or org-data", false
-	}
-
 	return "", true
 }
 
@@ -267,46 +154,25 @@ type VipCheckOrderRspData struct {
 }
 
 type VipAppleVerifyReceiptParams struct {
-	OrgId         string `json:"org_id"`
-	Address       string `json:"addr"`
-	Timestamp     int64  `json:"timestamp"`
-	Nonce         string `json:"nonce"`
-	Token         string `json:"token"`
-	PersonalAuth  string `json:"personal_auth"`
+	OrgId         string `json:"org_id" check:"max=nonce"`
+	Address       string `json:"addr" check:"address"`
+	Timestamp     int64  `json:"timestamp" check:"timestamp"`
+	Nonce         string `json:"nonce" check:"max=nonce"`
+	Token         string `json:"token" check:"token"`
+	PersonalAuth  string `json:"personal_auth" check:"required,max=general"`
 	OrderId       string `json:"order_id"`
-	Receipt       string `json:"receipt"`
+	Receipt       string `json:"receipt" check:"required,max=body"`
 	TransactionId string `json:"transaction_id"`
 	Restore       int32  `json:"restore"`
 }
 
 func (v VipAppleVerifyReceiptParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return "invalid address " + v.Address, false
-	}
-
-	if v.PersonalAuth == "" {
-		return "invalid personal auth" + v.PersonalAuth, false
-	}
-	if v.Receipt == "" {
-		return "invalid params receipt" + v.Receipt, false
+	if errMsg, ok := checkParams(v, CheckInput{Tokens: []string{VipAppleVerifyReceiptToken}}); !ok {
+		return errMsg, false
 	}
 	if v.Restore == 0 && v.OrderId == "" {
-		return fmt.Sprintf("invalid params restore = %d or order_id = %s", v.Restore, v.OrderId), false
-	}
-
-	if v.Token != VipAppleVerifyReceiptToken {
-		return "invalid token" + v.Token, false
-	}
-
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp = %d, client timestamp = %d", time.Now().Unix(), v.Timestamp)
-		return msg, false
+		return "invalid order_id: empty", false
 	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.OrgId) > util.W3PMaxNonceLength || len(v.PersonalAuth) > util.W3PMaxGeneralLenth || len(v.Receipt) > util.W3PMaxBodyLength {
-		return "invalid nonce or orgid or auth or receipt", false
-	}
-
 	return "", true
 }
 
@@ -315,76 +181,31 @@ type VipAppleVerifyReceiptRspData struct {
 }
 
 type GetDiscountCodeInfoParams struct {
-	Address      string         `json:"addr"`
-	Timestamp    int64          `json:"timestamp"`
-	Nonce        string         `json:"nonce"`
-	Token        string         `json:"token"`
-	App          consts.AppType `json:"app"`
-	DiscountCode string         `json:"discount_code"`
+	Address      string         `json:"addr" check:"address"`
+	Timestamp    int64          `json:"timestamp" check:"timestamp"`
+	Nonce        string         `json:"nonce" check:"max=nonce"`
+	Token        string         `json:"token" check:"token"`
+	App          consts.AppType `json:"app" check:"valid"`
+	DiscountCode string         `json:"discount_code" check:"required,max=nonce"`
 	Version      string         `json:"version"`
 }
 
 func (v GetDiscountCodeInfoParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return "invalid address " + v.Address, false
-	}
-
-	if v.Token != VipDiscountToken {
-		return fmt.Sprintf("invalid token %q", v.Token) + v.Token, false
-	}
-
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-	if v.DiscountCode == "" {
-		return "invalid params discount_code " + v.DiscountCode, false
-	}
-
-	if !v.App.Check() {
-		msg := fmt.Sprintf("invalid request params app=%s", v.App)
-		return msg, false
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.DiscountCode) > util.W3PMaxNonceLength {
-		return "invalid nonce or promo-code", false
-	}
-
-	return "", true
+	return checkParams(v, CheckInput{Tokens: []string{VipDiscountToken}})
 }
 
 type GetOrderListParams struct {
-	Address      string `json:"addr"`
-	Timestamp    int64  `json:"timestamp"`
-	Nonce        string `json:"nonce"`
-	Token        string `json:"token"`
-	PersonalAuth string `json:"personal_auth"`
+	Address      string `json:"addr" check:"address"`
+	Timestamp    int64  `json:"timestamp" check:"timestamp"`
+	Nonce        string `json:"nonce" check:"max=nonce"`
+	Token        string `json:"token" check:"token"`
+	PersonalAuth string `json:"personal_auth" check:"required,max=general"`
 	Page         int32  `json:"page"`
 	Pagesize     int32  `json:"pagesize"`
 }
 
 func (v GetOrderListParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return "invalid address " + v.Address, false
-	}
-
-	if v.Token != VipGetOrderList {
-		return fmt.Sprintf("invalid token %q", v.Token) + v.Token, false
-	}
-
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-	if v.PersonalAuth == "" {
-		return "invalid params personal_auth " + v.PersonalAuth, false
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.PersonalAuth) > util.W3PMaxGeneralLenth {
-		return "invalid nonce or auth", false
-	}
-
-	return "", true
+	return checkParams(v, CheckInput{Tokens: []string{VipGetOrderList}})
 }
 
 type GetOrderListRspData struct {
@@ -404,35 +225,15 @@ type OrderList struct {
 }
 
 type VipIOSPromotionSignParams struct {
-	Address      string `json:"addr"`
-	Timestamp    int64  `json:"timestamp"`
-	Nonce        string `json:"nonce"`
-	Token        string `json:"token"`
-	PersonalAuth string `json:"personal_auth"`
+	Address      string `json:"addr" check:"address"`
+	Timestamp    int64  `json:"timestamp" check:"timestamp"`
+	Nonce        string `json:"nonce" check:"max=nonce"`
+	Token        string `json:"token" check:"token"`
+	PersonalAuth string `json:"personal_auth" check:"required,max=general"`
 }
 
 func (v VipIOSPromotionSignParams) Check() (string, bool) {
-	if !tools.IsValidAddress(v.Address) {
-		return fmt.Sprintf("invalid params address(%v)", v.Address), false
-	}
-	if !util.CheckTimestamp(v.Timestamp) {
-		msg := fmt.Sprintf("invalid timestamp server timestamp=%d, client timestamp=%d", time.Now().Unix(), v.Timestamp)
-		return msg, false
-	}
-
-	if v.Token != VipIOSPromotionSign {
-		return fmt.Sprintf("invalid params token(%v)", v.Token), false
-	}
-
-	if v.PersonalAuth == "" {
-		return fmt.Sprintf("invalid params personal_auth(%v)", v.PersonalAuth), false
-	}
-
-	if len(v.Nonce) > util.W3PMaxNonceLength || len(v.PersonalAuth) > util.W3PMaxGeneralLenth {
-		return "invalid nonce or auth", false
-	}
-
-	return "", true
+	return checkParams(v, CheckInput{Tokens: []string{VipIOSPromotionSign}})
 }
 
 type VipPriceRspData struct {
@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package notify fans the change notifications published by the nodes out to
+// the clients subscribed to their address or org. Delivery is best effort, a
+// subscriber that falls behind is told to resync instead of blocking the nodes.
+package notify
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/web3password/satis/model"
+)
+
+// bufferSize is the number of notifications a subscriber may fall behind.
+const bufferSize = 64
+
+var ErrTooManySubscriptions = errors.New("too many subscriptions for the address")
+
+// Subscription receives the notifications of an address and, when set, an org.
+type Subscription struct {
+	Addr  string
+	OrgId string
+
+	c      chan *model.Notification
+	resync chan struct{}
+	hub    *Hub
+	once   sync.Once
+}
+
+// C delivers the notifications, Addrs cleared.
+func (s *Subscription) C() <-chan *model.Notification {
+	return s.c
+}
+
+// Resync is signalled when notifications were dropped, the client must then
+// fetch the current state as if it had just connected.
+func (s *Subscription) Resync() <-chan struct{} {
+	return s.resync
+}
+
+// Close unsubscribes, it may be called more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() { s.hub.remove(s) })
+}
+
+func (s *Subscription) deliver(n *model.Notification) {
+	select {
+	case s.c <- n:
+	default:
+		select {
+		case s.resync <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Hub indexes the subscriptions by address and org.
+type Hub struct {
+	lock   sync.RWMutex
+	byAddr map[string]map[*Subscription]struct{}
+	byOrg  map[string]map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		byAddr: make(map[string]map[*Subscription]struct{}),
+		byOrg:  make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers a subscription, at most maxPerAddr per address, zero for no limit.
+func (h *Hub) Subscribe(addr, orgId string, maxPerAddr int) (*Subscription, error) {
+	s := &Subscription{
+		Addr:   addr,
+		OrgId:  orgId,
+		c:      make(chan *model.Notification, bufferSize),
+		resync: make(chan struct{}, 1),
+		hub:    h,
+	}
+	key := strings.ToLower(addr)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if maxPerAddr > 0 && len(h.byAddr[key]) >= maxPerAddr {
+		return nil, ErrTooManySubscriptions
+	}
+	add(h.byAddr, key, s)
+	if orgId != "" {
+		add(h.byOrg, orgId, s)
+	}
+	return s, nil
+}
+
+// Publish delivers n to its subscribers and returns how many there were. A
+// subscriber of both an address and the org of n gets it once.
+func (h *Hub) Publish(n *model.Notification) int {
+	sent := *n
+	sent.Addrs = nil
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	seen := make(map[*Subscription]struct{})
+	for _, addr := range n.Addrs {
+		for s := range h.byAddr[strings.ToLower(addr)] {
+			seen[s] = struct{}{}
+		}
+	}
+	if n.OrgId != "" {
+		for s := range h.byOrg[n.OrgId] {
+			seen[s] = struct{}{}
+		}
+	}
+	for s := range seen {
+		s.deliver(&sent)
+	}
+	return len(seen)
+}
+
+// Count is the number of open subscriptions.
+func (h *Hub) Count() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	n := 0
+	for _, subs := range h.byAddr {
+		n += len(subs)
+	}
+	return n
+}
+
+func (h *Hub) remove(s *Subscription) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	del(h.byAddr, strings.ToLower(s.Addr), s)
+	if s.OrgId != "" {
+		del(h.byOrg, s.OrgId, s)
+	}
+}
+
+func add(index map[string]map[*Subscription]struct{}, key string, s *Subscription) {
+	subs, ok := index[key]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		index[key] = subs
+	}
+	subs[s] = struct{}{}
+}
+
+func del(index map[string]map[*Subscription]struct{}, key string, s *Subscription) {
+	subs := index[key]
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(index, key)
+	}
+}
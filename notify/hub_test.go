@@ -0,0 +1,166 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package notify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/web3password/satis/model"
+)
+
+func subscribe(t *testing.T, h *Hub, addr, orgId string) *Subscription {
+	t.Helper()
+	s, err := h.Subscribe(addr, orgId, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// received drains the notifications delivered to s.
+func received(s *Subscription) []*model.Notification {
+	var got []*model.Notification
+	for {
+		select {
+		case n := <-s.C():
+			got = append(got, n)
+		default:
+			return got
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	h := NewHub()
+	alice := subscribe(t, h, "0xAlice", "org-1")
+	bob := subscribe(t, h, "0xbob", "")
+	carol := subscribe(t, h, "0xcarol", "org-1")
+	dave := subscribe(t, h, "0xdave", "org-2")
+
+	tests := []struct {
+		name  string
+		n     *model.Notification
+		count int
+		want  map[*Subscription]int
+	}{
+		{"address, case insensitive", &model.Notification{Type: model.NotifyCredentialChanged, Addrs: []string{"0xalice"}},
+			1, map[*Subscription]int{alice: 1}},
+		{"address listed twice", &model.Notification{Type: model.NotifyCredentialChanged, Addrs: []string{"0xbob", "0xBOB"}},
+			1, map[*Subscription]int{bob: 1}},
+		{"org", &model.Notification{Type: model.NotifyCredentialChanged, OrgId: "org-1"},
+			2, map[*Subscription]int{alice: 1, carol: 1}},
+		{"address and its org", &model.Notification{Type: model.NotifyCredentialChanged, Addrs: []string{"0xalice", "0xbob"}, OrgId: "org-1"},
+			3, map[*Subscription]int{alice: 1, bob: 1, carol: 1}},
+		{"nobody", &model.Notification{Type: model.NotifyCredentialChanged, Addrs: []string{"0xerin"}, OrgId: "org-3"},
+			0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addrs := len(tt.n.Addrs)
+			if got := h.Publish(tt.n); got != tt.count {
+				t.Errorf("published to %d, want %d", got, tt.count)
+			}
+			for _, s := range []*Subscription{alice, bob, carol, dave} {
+				got := received(s)
+				if len(got) != tt.want[s] {
+					t.Errorf("%s got %d notifications, want %d", s.Addr, len(got), tt.want[s])
+				}
+				for _, n := range got {
+					if n.Addrs != nil || n.Type != tt.n.Type {
+						t.Errorf("%s got %+v, want the type without the addresses", s.Addr, n)
+					}
+				}
+			}
+			if len(tt.n.Addrs) != addrs {
+				t.Error("Publish cleared the addresses of the published notification")
+			}
+		})
+	}
+}
+
+func TestPublishResyncsFullBuffer(t *testing.T) {
+	h := NewHub()
+	s := subscribe(t, h, "0xalice", "")
+	n := &model.Notification{Type: model.NotifyCredentialChanged, Addrs: []string{"0xalice"}}
+	for i := 0; i < bufferSize; i++ {
+		h.Publish(n)
+	}
+	select {
+	case <-s.Resync():
+		t.Fatal("resync before the buffer is full")
+	default:
+	}
+	// the dropped notifications are signalled once
+	h.Publish(n)
+	h.Publish(n)
+	select {
+	case <-s.Resync():
+	default:
+		t.Fatal("no resync after a dropped notification")
+	}
+	select {
+	case <-s.Resync():
+		t.Fatal("resync signalled twice")
+	default:
+	}
+	if got := len(received(s)); got != bufferSize {
+		t.Fatalf("%d notifications buffered, want %d", got, bufferSize)
+	}
+	if h.Publish(n); len(received(s)) != 1 {
+		t.Fatal("no delivery after the buffer was drained")
+	}
+}
+
+func TestSubscribeMaxPerAddr(t *testing.T) {
+	h := NewHub()
+	var subs []*Subscription
+	for i := 0; i < 2; i++ {
+		s, err := h.Subscribe("0xAlice", "", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, s)
+	}
+	if _, err := h.Subscribe("0xalice", "", 2); !errors.Is(err, ErrTooManySubscriptions) {
+		t.Fatalf("third subscription: %v, want %v", err, ErrTooManySubscriptions)
+	}
+	if _, err := h.Subscribe("0xbob", "", 2); err != nil {
+		t.Fatalf("the limit is per address: %v", err)
+	}
+	subs[0].Close()
+	if _, err := h.Subscribe("0xalice", "", 2); err != nil {
+		t.Fatalf("subscription after a close: %v", err)
+	}
+	if _, err := h.Subscribe("0xalice", "", 0); err != nil {
+		t.Fatalf("zero is no limit: %v", err)
+	}
+}
+
+func TestClosePrunes(t *testing.T) {
+	h := NewHub()
+	a := subscribe(t, h, "0xAlice", "org-1")
+	b := subscribe(t, h, "0xalice", "org-1")
+	if h.Count() != 2 {
+		t.Fatalf("count %d", h.Count())
+	}
+	a.Close()
+	a.Close()
+	if h.Count() != 1 || len(h.byAddr) != 1 || len(h.byOrg) != 1 {
+		t.Fatalf("after one close: count %d, %d addrs, %d orgs", h.Count(), len(h.byAddr), len(h.byOrg))
+	}
+	if got := h.Publish(&model.Notification{Addrs: []string{"0xalice"}, OrgId: "org-1"}); got != 1 {
+		t.Fatalf("published to %d after a close", got)
+	}
+	b.Close()
+	if h.Count() != 0 || len(h.byAddr) != 0 || len(h.byOrg) != 0 {
+		t.Fatalf("after closing all: count %d, %d addrs, %d orgs", h.Count(), len(h.byAddr), len(h.byOrg))
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package satistest runs satis in process with fake backend nodes, so request
+// flows from the BSON http api down to the node stream can be driven from
+// go test. The config, logger and grpc client of satis are process wide,
+// run one Harness at a time.
+//
+//	h, err := satistest.Start(satistest.Options{})
+//	defer h.Close()
+//	ares, err := h.Node(model.ARES_PROXY, "ares-1")
+//	ares.HandleReply(model.CMDRegister, model.StatusOK, model.MsgOK, nil)
+//	ares.Inject(model.CMDRegister, satistest.Fault{Delay: time.Second, Times: 1})
+//	rsp, err := h.Post("/web3password/userRegister", signature, params, nil)
+package satistest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/consts"
+	"github.com/web3password/satis/idempotency"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/service"
+	"github.com/web3password/satis/service/handlers"
+	pb "github.com/web3password/w3p-protobuf/user"
+	"google.golang.org/grpc"
+)
+
+const (
+	DefaultNodeToken = "satistest"
+
+	waitTimeout = 5 * time.Second
+	maxMsgSize  = 64 << 20
+)
+
+var (
+	// the logger is set up once, its directory outlives the harnesses
+	loggerOnce sync.Once
+	logDir     string
+)
+
+// Options configures a Harness, the zero value runs an official node.
+type Options struct {
+	RunningMode string // official, audit or local, default official
+	NodeToken   string // default DefaultNodeToken
+	LogLevel    string // default warn
+	// Config is appended to the generated config.yaml, for the sections a
+	// test needs, e.g. access_control or route_policy.
+	Config string
+}
+
+// Harness is a satis instance serving http and grpc on ephemeral local ports.
+type Harness struct {
+	Dir string // config
+
+	svc        *service.Service
+	grpcServer *grpc.Server
+	grpcAddr   string
+	httpServer *http.Server
+	httpAddr   string
+
+	nodeToken string
+
+	lock  sync.Mutex
+	nodes []*Node
+}
+
+// Start writes a config for the options and boots satis like cmd does.
+func Start(opts Options) (*Harness, error) {
+	if opts.RunningMode == "" {
+		opts.RunningMode = consts.RunningModeOfficial
+	}
+	if opts.NodeToken == "" {
+		opts.NodeToken = DefaultNodeToken
+	}
+	if opts.LogLevel == "" {
+		opts.LogLevel = "warn"
+	}
+	if logDir == "" {
+		dir, err := os.MkdirTemp("", "satistest-logs")
+		if err != nil {
+			return nil, err
+		}
+		logDir = dir
+	}
+	dir, err := os.MkdirTemp("", "satistest")
+	if err != nil {
+		return nil, err
+	}
+	h := &Harness{Dir: dir, nodeToken: opts.NodeToken}
+	grpcListen, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	httpListen, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = grpcListen.Close()
+		return nil, err
+	}
+	h.grpcAddr, h.httpAddr = grpcListen.Addr().String(), httpListen.Addr().String()
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(h.configYAML(opts)), 0600); err != nil {
+		_ = grpcListen.Close()
+		_ = httpListen.Close()
+		return nil, err
+	}
+	if err := config.ParseConfig(path); err != nil {
+		_ = grpcListen.Close()
+		_ = httpListen.Close()
+		return nil, err
+	}
+	conf := config.GetConfig()
+	loggerOnce.Do(log.SetLogger)
+
+	h.svc = service.NewService(conf)
+	h.grpcServer = grpc.NewServer(grpc.MaxRecvMsgSize(maxMsgSize), grpc.MaxSendMsgSize(maxMsgSize))
+	pb.RegisterUserServer(h.grpcServer, h.svc)
+	go func() {
+		_ = h.grpcServer.Serve(grpcListen)
+	}()
+	handlers.Init(conf)
+	handlers.SetService(h.svc)
+	idempotency.Init(conf)
+
+	gin.SetMode(gin.ReleaseMode)
+	gin.DefaultWriter = io.Discard
+	h.httpServer = &http.Server{Handler: service.Routers()}
+	go func() {
+		_ = h.httpServer.Serve(httpListen)
+	}()
+	return h, nil
+}
+
+func (h *Harness) configYAML(opts Options) string {
+	_, grpcPort, _ := net.SplitHostPort(h.grpcAddr)
+	_, httpPort, _ := net.SplitHostPort(h.httpAddr)
+	return fmt.Sprintf(`running_mode: %s
+official_domains:
+  - http://127.0.0.1:%s
+node:
+  token: %s
+msg:
+  file: %d
+  api: 1048576
+log_dir: %s
+log:
+  level: %s
+http_server:
+  ip: 127.0.0.1
+  port: %s
+  with_trace_id: true
+server:
+  enable_tls: false
+  ip: 127.0.0.1
+  port: %s
+  proto: tcp
+%s
+`, opts.RunningMode, httpPort, opts.NodeToken, maxMsgSize, logDir, opts.LogLevel, httpPort, grpcPort, opts.Config)
+}
+
+// GRPCAddr is the address nodes connect to.
+func (h *Harness) GRPCAddr() string {
+	return h.grpcAddr
+}
+
+// URL is the base url of the http api.
+func (h *Harness) URL() string {
+	return "http://" + h.httpAddr
+}
+
+// Node connects a fake node to the harness and waits until satis routes to it.
+func (h *Harness) Node(group, id string) (*Node, error) {
+	n, err := ConnectNode(h.grpcAddr, group, id)
+	if err != nil {
+		return nil, err
+	}
+	n.Token = h.nodeToken
+	if err := h.WaitNode(group, id, true); err != nil {
+		_ = n.Close()
+		return nil, err
+	}
+	h.lock.Lock()
+	h.nodes = append(h.nodes, n)
+	h.lock.Unlock()
+	return n, nil
+}
+
+// WaitNode waits until the node is, or is no longer, in the routing table of its group.
+func (h *Harness) WaitNode(group, id string, present bool) error {
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		nodes := h.svc.Nodes(group)
+		i := sort.SearchStrings(nodes, id)
+		if (i < len(nodes) && nodes[i] == id) == present {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			state := "missing"
+			if !present {
+				state = "routed"
+			}
+			return fmt.Errorf("satistest: node %s of group %s still %s after %s", id, group, state, waitTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// QueueDepth is the number of requests satis has queued for a node.
+func (h *Harness) QueueDepth(id string) int {
+	return h.svc.QueueDepth(id)
+}
+
+// Post sends a BSON request to an api path and decodes the BSON response,
+// catalog errors come with the HTTP status of their category when
+// http_server.error_status is set.
+func (h *Harness) Post(path, signature, params string, data []byte) (*encode.Web3PasswordResponseBsonStruct, error) {
+	body, err := encode.Web3PasswordRequestBsonEncode(signature, params, data)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := http.Post(h.URL()+path, "application/octet-stream", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 6 {
+		return nil, fmt.Errorf("satistest: %s returned %s: %q", path, rsp.Status, b)
+	}
+	ret, err := encode.Web3PasswordResponseBsonDecode(b)
+	if err != nil && rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("satistest: %s returned %s: %s", path, rsp.Status, b)
+	}
+	return ret, err
+}
+
+// Close stops the servers, disconnects the nodes and removes Dir.
+func (h *Harness) Close() error {
+	h.lock.Lock()
+	nodes := h.nodes
+	h.nodes = nil
+	h.lock.Unlock()
+	for _, n := range nodes {
+		_ = n.Close()
+	}
+	_ = h.httpServer.Close()
+	h.grpcServer.Stop()
+	return os.RemoveAll(h.Dir)
+}
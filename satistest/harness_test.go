@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package satistest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/web3password/satis/client"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/satistest"
+	pb "github.com/web3password/w3p-protobuf/user"
+)
+
+func start(t *testing.T) (*satistest.Harness, *client.Client) {
+	t.Helper()
+	h, err := satistest.Start(satistest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+	signer, err := client.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h, client.New(h.URL(), signer)
+}
+
+func TestAddCredential(t *testing.T) {
+	h, c := start(t)
+	index, err := h.Node(model.INDEX_PROXY, "index-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Handle(model.CMDIndexAddOrDelCredential, func(req *pb.StreamRsp) *pb.StreamReq {
+		return &pb.StreamReq{Params: fmt.Sprintf(`{"code":%d,"msg":"ok","data":{"txhash":"0xabc"}}`, model.StatusOK)}
+	})
+
+	now := time.Now().Unix()
+	rsp, err := c.Call(context.Background(), "/web3password/addCredential", map[string]any{"id": "cred-1", "op_timestamp": now}, []byte("credential"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Code != model.StatusOK {
+		t.Fatalf("code %d %s", rsp.Code, rsp.Msg)
+	}
+	requests := index.Requests(model.CMDIndexAddOrDelCredential)
+	if len(requests) != 1 {
+		t.Fatalf("%d requests reached the index", len(requests))
+	}
+	req := requests[0]
+	if req.GetToken() != satistest.DefaultNodeToken || string(req.GetData()) != "credential" {
+		t.Fatalf("index got token %q data %q", req.GetToken(), req.GetData())
+	}
+	var params map[string]any
+	if err := json.Unmarshal([]byte(req.GetParams()), &params); err != nil || params["id"] != "cred-1" || params["addr"] != c.Signer.Address() {
+		t.Fatalf("index got params %s", req.GetParams())
+	}
+
+	// a refusal of the node is returned as is
+	index.Handle(model.CMDIndexAddOrDelCredential, func(req *pb.StreamRsp) *pb.StreamReq {
+		return &pb.StreamReq{Params: fmt.Sprintf(`{"code":%d,"msg":"refused"}`, model.StatusParamsErr)}
+	})
+	if rsp, err = c.Call(context.Background(), "/web3password/addCredential", map[string]any{"id": "cred-2", "op_timestamp": now}, []byte("credential")); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Code != model.StatusParamsErr || rsp.Msg != "refused" {
+		t.Fatalf("refused credential answered %d %s", rsp.Code, rsp.Msg)
+	}
+}
+
+func TestNotificationsNeedNodeToken(t *testing.T) {
+	h, c := start(t)
+	index, err := h.Node(model.INDEX_PROXY, "index-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events := make(chan *client.Event, 8)
+	go func() {
+		_, _ = c.Events(ctx, nil, func(e *client.Event) error {
+			events <- e
+			return nil
+		})
+		close(events)
+	}()
+	next := func() *client.Event {
+		t.Helper()
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("event stream ended")
+			}
+			return e
+		case <-ctx.Done():
+			t.Fatal("no event")
+		}
+		return nil
+	}
+	if e := next(); e.Name != "ready" {
+		t.Fatalf("first event %s", e.Name)
+	}
+
+	notification := func(id string) *model.Notification {
+		return &model.Notification{Type: model.NotifyCredentialChanged, Addrs: []string{c.Signer.Address()}, Id: id}
+	}
+	index.Token = "forged"
+	if err := index.Publish(notification("forged")); err != nil {
+		t.Fatal(err)
+	}
+	index.Token = satistest.DefaultNodeToken
+	if err := index.Publish(notification("genuine")); err != nil {
+		t.Fatal(err)
+	}
+	e := next()
+	var got model.Notification
+	if err := json.Unmarshal(e.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if e.Name != model.NotifyCredentialChanged || got.Id != "genuine" {
+		t.Fatalf("got %s %s, want the genuine notification only", e.Name, e.Data)
+	}
+}
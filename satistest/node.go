@@ -0,0 +1,288 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package satistest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/model"
+	pb "github.com/web3password/w3p-protobuf/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Handler answers one request of a node, see Reply.
+type Handler func(req *pb.StreamRsp) *pb.StreamReq
+
+// Fault changes how a node answers the requests of a cmd.
+type Fault struct {
+	Delay      time.Duration // wait before answering
+	Code       int32         // answer with this code and Msg instead of calling the handler
+	Msg        string
+	Drop       bool // never answer, satis times out
+	Disconnect bool // close the stream when the request arrives
+	Times      int  // apply to the next Times requests only, 0 for all of them
+}
+
+// Node is a fake ares, index or storage node connected to satis over the
+// Stream rpc. Requests without a handler, and no HandleAll one, are answered
+// with StatusSystemError.
+type Node struct {
+	Group string
+	ID    string
+	Token string // node.token sent with the notifications, default DefaultNodeToken
+
+	addr string
+	conn *grpc.ClientConn
+
+	lock     sync.Mutex
+	sendLock sync.Mutex
+	stream   pb.User_StreamClient
+	cancel   context.CancelFunc
+	handlers map[string]Handler
+	fallback Handler
+	faults   map[string]*Fault
+	requests []*pb.StreamRsp
+}
+
+// ConnectNode connects a node of group to the satis grpc server at addr.
+func ConnectNode(addr, group, id string) (*Node, error) {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(64<<20), grpc.MaxCallSendMsgSize(64<<20)))
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{
+		Group:    group,
+		ID:       id,
+		Token:    DefaultNodeToken,
+		addr:     addr,
+		conn:     conn,
+		handlers: make(map[string]Handler),
+		faults:   make(map[string]*Fault),
+	}
+	if err := n.Reconnect(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// Reconnect opens a new stream, closing the current one.
+func (n *Node) Reconnect() error {
+	n.Disconnect()
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = metadata.AppendToOutgoingContext(ctx, "group", n.Group, "client_id", n.ID, "conn", uuid.NewString())
+	stream, err := pb.NewUserClient(n.conn).Stream(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	n.lock.Lock()
+	n.stream, n.cancel = stream, cancel
+	n.lock.Unlock()
+	go n.recv(stream)
+	return nil
+}
+
+// Disconnect closes the stream, satis removes the node from its group.
+func (n *Node) Disconnect() {
+	n.lock.Lock()
+	cancel := n.cancel
+	n.stream, n.cancel = nil, nil
+	n.lock.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// Close disconnects the node and closes its connection.
+func (n *Node) Close() error {
+	n.Disconnect()
+	return n.conn.Close()
+}
+
+// Handle sets the handler of a cmd. Cmd values are only unique within a group.
+func (n *Node) Handle(cmd string, h Handler) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.handlers[cmd] = h
+}
+
+// HandleAll sets the handler of the cmds without one of their own.
+func (n *Node) HandleAll(h Handler) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.fallback = h
+}
+
+// HandleReply answers every request of a cmd with the same reply.
+func (n *Node) HandleReply(cmd string, code int32, msg string, data any) {
+	n.Handle(cmd, func(*pb.StreamRsp) *pb.StreamReq {
+		return Reply(code, msg, data)
+	})
+}
+
+// Inject sets the fault of a cmd, replacing the previous one.
+func (n *Node) Inject(cmd string, f Fault) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.faults[cmd] = &f
+}
+
+// ClearFaults removes every injected fault.
+func (n *Node) ClearFaults() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.faults = make(map[string]*Fault)
+}
+
+// GracefulRestart sends the restart signal, satis stops routing to the node
+// but keeps the stream open.
+func (n *Node) GracefulRestart() error {
+	return n.send(&pb.StreamReq{Cmd: model.CMDGracefulRestartSignal, TraceId: uuid.NewString()})
+}
+
+// Publish sends a change notification, unasked, like the nodes do.
+func (n *Node) Publish(notification *model.Notification) error {
+	params, err := jsoniter.MarshalToString(notification)
+	if err != nil {
+		return err
+	}
+	return n.send(&pb.StreamReq{Cmd: model.CMDNotify, Token: n.Token, Params: params, TraceId: uuid.NewString()})
+}
+
+// Requests returns the requests received for a cmd, every request but the
+// heartbeats when cmd is empty.
+func (n *Node) Requests(cmd string) []*pb.StreamRsp {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	var requests []*pb.StreamRsp
+	for _, req := range n.requests {
+		if cmd == "" || req.GetCmd() == cmd {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+func (n *Node) recv(stream pb.User_StreamClient) {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return
+		}
+		if req.GetCmd() == model.CMDPong {
+			continue
+		}
+		n.lock.Lock()
+		n.requests = append(n.requests, req)
+		n.lock.Unlock()
+		go n.answer(req)
+	}
+}
+
+func (n *Node) answer(req *pb.StreamRsp) {
+	h, fault := n.route(req.GetCmd())
+	if fault.Delay > 0 {
+		time.Sleep(fault.Delay)
+	}
+	switch {
+	case fault.Disconnect:
+		n.Disconnect()
+		return
+	case fault.Drop:
+		return
+	}
+
+	var rsp *pb.StreamReq
+	switch {
+	case fault.Code != 0:
+		rsp = Reply(fault.Code, fault.Msg, nil)
+	case h == nil:
+		rsp = Reply(model.StatusSystemError, fmt.Sprintf("satistest: node %s has no handler for cmd %s", n.ID, req.GetCmd()), nil)
+	default:
+		rsp = h(req)
+	}
+	if rsp == nil {
+		return
+	}
+	rsp.Cmd = req.GetCmd()
+	rsp.RequestId = req.GetRequestId()
+	rsp.TraceId = req.GetTraceId()
+	rsp.Token = req.GetToken()
+	_ = n.send(rsp)
+}
+
+// route returns the handler and the fault to apply to one request of cmd.
+func (n *Node) route(cmd string) (Handler, Fault) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	var fault Fault
+	if f, ok := n.faults[cmd]; ok {
+		fault = *f
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				delete(n.faults, cmd)
+			}
+		}
+	}
+	if h, ok := n.handlers[cmd]; ok {
+		return h, fault
+	}
+	return n.fallback, fault
+}
+
+func (n *Node) send(req *pb.StreamReq) error {
+	n.lock.Lock()
+	stream := n.stream
+	n.lock.Unlock()
+	if stream == nil {
+		return errors.New("satistest: node is disconnected")
+	}
+	n.sendLock.Lock()
+	defer n.sendLock.Unlock()
+	if err := stream.Send(req); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// Reply builds a node answer. data is sent as is when it is a []byte or a
+// string, any other value is bson encoded, like the nodes encode documents.
+func Reply(code int32, msg string, data any) *pb.StreamReq {
+	doc := bson.M{"code": code, "msg": msg}
+	switch d := data.(type) {
+	case nil:
+		doc["data"] = []byte{}
+	case []byte, string:
+		doc["data"] = d
+	default:
+		b, err := bson.Marshal(d)
+		if err != nil {
+			panic(fmt.Sprintf("satistest: bson encode reply data: %v", err))
+		}
+		doc["data"] = b
+	}
+	b, err := bson.Marshal(doc)
+	if err != nil {
+		panic(fmt.Sprintf("satistest: bson encode reply: %v", err))
+	}
+	return &pb.StreamReq{Data: b}
+}
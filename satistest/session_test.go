@@ -0,0 +1,183 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package satistest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/web3password/satis/client"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/satistest"
+	"github.com/web3password/satis/session"
+	pb "github.com/web3password/w3p-protobuf/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// startSession starts satis with session tokens and idempotency keys, nodes
+// answering success in every group, and returns a client signing with a key
+// and one signing with a session token of the same address.
+func startSession(t *testing.T) (*satistest.Harness, []*satistest.Node, *client.Client, *client.Client) {
+	t.Helper()
+	h, err := satistest.Start(satistest.Options{Config: "session:\n  enable: true\nidempotency:\n  enable: true\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+
+	reply := satistest.Reply(model.StatusOK, model.MsgOK, bson.M{})
+	reply.Params = fmt.Sprintf(`{"code":%d,"msg":%q}`, model.StatusOK, model.MsgOK)
+	var nodes []*satistest.Node
+	for _, group := range []string{model.ARES_PROXY, model.INDEX_PROXY, model.STORAGE_PROXY} {
+		n, err := h.Node(group, group+"-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.HandleAll(func(*pb.StreamRsp) *pb.StreamReq {
+			return &pb.StreamReq{Params: reply.Params, Data: reply.Data}
+		})
+		nodes = append(nodes, n)
+	}
+
+	signer, err := client.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := session.Issue(signer.Address(), time.Now().Unix()+3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h, nodes, client.New(h.URL(), signer), client.New(h.URL(), client.SessionSigner{Addr: signer.Address(), Token: token})
+}
+
+func nodeRequests(nodes []*satistest.Node) int {
+	n := 0
+	for _, node := range nodes {
+		n += len(node.Requests(""))
+	}
+	return n
+}
+
+func TestSessionRefusedOnMutatingRoutes(t *testing.T) {
+	_, nodes, _, sc := startSession(t)
+	ctx := context.Background()
+	for _, route := range client.Routes {
+		if session.Accepts(route.Path) {
+			continue
+		}
+		// the idempotency key has the idempotent routes verify the signature first
+		params := map[string]any{"idempotency_key": "key-" + route.Path}
+		rsp, err := sc.Call(ctx, route.Path, params, []byte("data"))
+		if err != nil {
+			t.Errorf("%s: %v", route.Path, err)
+			continue
+		}
+		if rsp.Code != model.StatusSignatureErr {
+			t.Errorf("%s: session token answered %d %s", route.Path, rsp.Code, rsp.Msg)
+		}
+		// a read-only route token does not open the route either
+		params = map[string]any{"token": model.GetCredentialListToken}
+		if rsp, err = sc.Call(ctx, route.Path, params, nil); err == nil && rsp.Code != model.StatusSignatureErr {
+			t.Errorf("%s: session token with a read-only route token answered %d %s", route.Path, rsp.Code, rsp.Msg)
+		}
+	}
+	if n := nodeRequests(nodes); n != 0 {
+		t.Fatalf("%d requests signed with a session token reached the nodes", n)
+	}
+}
+
+func TestSessionAcceptedOnReadOnlyRoutes(t *testing.T) {
+	_, nodes, _, sc := startSession(t)
+	for route := range session.ReadOnlyRoutes {
+		rsp, err := sc.Call(context.Background(), route, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rsp.Code == model.StatusSignatureErr {
+			t.Errorf("%s: session token refused: %s", route, rsp.Msg)
+		}
+	}
+	if nodeRequests(nodes) == 0 {
+		t.Fatal("no read-only request reached the nodes")
+	}
+}
+
+func TestSessionRefusedInTransaction(t *testing.T) {
+	_, nodes, kc, sc := startSession(t)
+	data := []byte("credential")
+	for _, token := range []string{model.AddCredentialToken, model.GetCredentialListToken} {
+		op, err := sc.Op("/web3password/addCredential", map[string]any{"id": "1", "op_timestamp": time.Now().Unix(), "token": token}, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rsp, err := kc.Transaction(context.Background(), op)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rsp.Code == model.StatusOK {
+			t.Fatalf("op signed with a session token and token %s was applied", token)
+		}
+	}
+	if n := nodeRequests(nodes); n != 0 {
+		t.Fatalf("%d transactions with session ops reached the nodes", n)
+	}
+}
+
+// TestSessionRefusedOverGRPC calls the service without the http middlewares,
+// the params of each route check their own token.
+func TestSessionRefusedOverGRPC(t *testing.T) {
+	h, nodes, _, sc := startSession(t)
+	conn, err := grpc.Dial(h.GRPCAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	user := pb.NewUserClient(conn)
+	ctx := context.Background()
+
+	signed := func(route string) (string, string) {
+		r, _ := client.LookupRoute(route)
+		params := sc.Params(r, map[string]any{"id": "1", "op_timestamp": time.Now().Unix(), "token": model.GetCredentialListToken}, nil)
+		b, err := json.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sign, _ := sc.Signer.Sign(b)
+		return sign, string(b)
+	}
+	sign, params := signed("/web3password/addCredential")
+	add, err := user.AddCredential(ctx, &pb.AddCredentialReq{Signature: sign, Params: params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign, params = signed("/web3password/deleteCredential")
+	del, err := user.DeleteCredential(ctx, &pb.DeleteCredentialReq{Signature: sign, Params: params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign, params = signed("/web3password/admin/updateOrgInfo")
+	org, err := user.AdminUpdateOrgInfo(ctx, &pb.AdminUpdateOrgInfoReq{Signature: sign, Params: params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, code := range map[string]int32{"addCredential": add.GetCode(), "deleteCredential": del.GetCode(), "updateOrgInfo": org.GetCode()} {
+		if code != model.StatusParamsErr {
+			t.Errorf("%s answered %d to a session token", name, code)
+		}
+	}
+	if n := nodeRequests(nodes); n != 0 {
+		t.Fatalf("%d requests signed with a session token reached the nodes", n)
+	}
+}
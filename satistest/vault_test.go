@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package satistest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/vault"
+	pb "github.com/web3password/w3p-protobuf/user"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestVaultImportChecksArchiveHash(t *testing.T) {
+	h, c := start(t)
+	index, err := h.Node(model.INDEX_PROXY, "index-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the index refuses the second credential
+	index.Handle(model.CMDIndexVaultImportCredentials, func(*pb.StreamRsp) *pb.StreamReq {
+		return &pb.StreamReq{Params: fmt.Sprintf(`{"code":%d,"msg":"ok","data":{"failed":1}}`, model.StatusOK)}
+	})
+	index.Handle(model.CMDIndexVaultImportRecords, func(*pb.StreamRsp) *pb.StreamReq {
+		return &pb.StreamReq{Params: fmt.Sprintf(`{"code":%d,"msg":"ok","data":{}}`, model.StatusOK)}
+	})
+
+	var buf bytes.Buffer
+	w := vault.NewWriter(&buf, c.Signer.Address(), "")
+	entries := []*vault.Entry{
+		{Kind: vault.KindCredential, Id: "cred-1", OpTimestamp: 1},
+		{Kind: vault.KindCredential, Id: "cred-2", OpTimestamp: 2},
+		{Kind: vault.KindRecord, Id: "record-1", FolderId: "folder-1"},
+	}
+	for _, e := range entries {
+		if err := w.Add(e, []byte("blob of "+e.Id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	hash := hex.EncodeToString(sum[:])
+
+	rsp, err := c.Call(context.Background(), "/web3password/vault/import", nil, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Code != model.StatusOK {
+		t.Fatalf("import code %d %s", rsp.Code, rsp.Msg)
+	}
+	var progress model.VaultImportProgress
+	if err := bson.Unmarshal(rsp.Data, &progress); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); progress.State == vault.StateRunning; {
+		if time.Now().After(deadline) {
+			t.Fatal("import still running")
+		}
+		time.Sleep(50 * time.Millisecond)
+		rsp, err := c.Call(context.Background(), "/web3password/vault/importStatus", map[string]any{"job_id": progress.JobId}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rsp.Code != model.StatusOK {
+			t.Fatalf("status code %d %s", rsp.Code, rsp.Msg)
+		}
+		if err := bson.Unmarshal(rsp.Data, &progress); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if progress.State != vault.StateDone || progress.Total != 3 || progress.Done != 2 || progress.Failed != 1 {
+		t.Fatalf("progress %+v", progress)
+	}
+
+	for cmd, ids := range map[string][]string{
+		model.CMDIndexVaultImportCredentials: {"cred-1", "cred-2"},
+		model.CMDIndexVaultImportRecords:     {"record-1"},
+	} {
+		requests := index.Requests(cmd)
+		if len(requests) != 1 {
+			t.Fatalf("%d requests of cmd %s", len(requests), cmd)
+		}
+		var batch model.VaultImportBatch
+		if err := bson.Unmarshal(requests[0].GetData(), &batch); err != nil {
+			t.Fatal(err)
+		}
+		if batch.ArchiveHash != hash || batch.JobId != progress.JobId || len(batch.Items) != len(ids) {
+			t.Fatalf("cmd %s got batch %+v", cmd, batch)
+		}
+		for i, item := range batch.Items {
+			sum := sha256.Sum256(item.Data)
+			if item.Id != ids[i] || item.SHA256 != hex.EncodeToString(sum[:]) {
+				t.Fatalf("cmd %s item %d %+v", cmd, i, item)
+			}
+		}
+	}
+	for _, cmd := range []string{model.CMDIndexBatchAddCredential, model.CMDShareFolderAddRecord} {
+		if n := len(index.Requests(cmd)); n != 0 {
+			t.Fatalf("the import sent %d requests of cmd %s", n, cmd)
+		}
+	}
+}
+
+// exportPage answers a vault export command with a page of items.
+func exportPage(items []*model.VaultItem, next string) *pb.StreamReq {
+	rsp := model.VaultExportRsp{Code: model.StatusOK, Msg: "ok"}
+	rsp.Data.Items, rsp.Data.NextCursor = items, next
+	b, err := json.Marshal(rsp)
+	if err != nil {
+		panic(err)
+	}
+	return &pb.StreamReq{Params: string(b)}
+}
+
+func TestVaultExportResumesAfterExpiredSignature(t *testing.T) {
+	h, c := start(t)
+	index, err := h.Node(model.INDEX_PROXY, "index-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := h.Node(model.STORAGE_PROXY, "storage-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := func(id string) []byte { return []byte("blob of " + id) }
+	// the signature of the first export expires on the second credential page
+	index.Handle(model.CMDIndexVaultExportCredentials, func(req *pb.StreamRsp) *pb.StreamReq {
+		var params model.VaultExportParams
+		if err := json.Unmarshal([]byte(req.GetParams()), &params); err != nil {
+			panic(err)
+		}
+		switch {
+		case len(req.GetData()) == 0:
+			return exportPage([]*model.VaultItem{{Id: "cred-1", Data: blob("cred-1")}}, "c1")
+		case params.ResumeCursor != "c1":
+			return &pb.StreamReq{Params: fmt.Sprintf(`{"code":%d,"msg":"timestamp"}`, model.StatusTimestampErr)}
+		default:
+			return exportPage([]*model.VaultItem{{Id: "cred-2", Data: blob("cred-2")}}, "")
+		}
+	})
+	index.Handle(model.CMDIndexVaultExportRecords, func(*pb.StreamRsp) *pb.StreamReq {
+		return exportPage([]*model.VaultItem{{Id: "record-1", FolderId: "folder-1", Data: blob("record-1")}}, "")
+	})
+	storage.Handle(model.CMDStorageVaultExportAttachments, func(*pb.StreamRsp) *pb.StreamReq {
+		return exportPage([]*model.VaultItem{{Id: "file-1", Size: 3, SHA256: hex.EncodeToString(make([]byte, 32))}}, "")
+	})
+	export := func(params map[string]any) *vault.Archive {
+		t.Helper()
+		var buf bytes.Buffer
+		rsp, err := c.Export(context.Background(), params, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rsp != nil {
+			t.Fatalf("export code %d %s", rsp.Code, rsp.Msg)
+		}
+		a, err := vault.Read(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	ids := func(a *vault.Archive) []string {
+		var ids []string
+		for _, e := range a.Manifest.Entries {
+			ids = append(ids, e.Id)
+		}
+		return ids
+	}
+
+	first := export(nil)
+	if got := ids(first); len(got) != 1 || got[0] != "cred-1" {
+		t.Fatalf("first part entries %v", got)
+	}
+	if r := first.Manifest.Resume; r == nil || r.Kind != vault.KindCredential || r.Cursor != "c1" {
+		t.Fatalf("first part resume %+v", r)
+	}
+	if n := len(index.Requests(model.CMDIndexVaultExportRecords)); n != 0 {
+		t.Fatalf("the stopped export asked %d record pages", n)
+	}
+
+	second := export(map[string]any{"resume_kind": first.Manifest.Resume.Kind, "resume_cursor": first.Manifest.Resume.Cursor})
+	if got := ids(second); fmt.Sprint(got) != "[cred-2 record-1 file-1]" {
+		t.Fatalf("second part entries %v", got)
+	}
+	if second.Manifest.Resume != nil {
+		t.Fatalf("second part resume %+v", second.Manifest.Resume)
+	}
+	requests := index.Requests(model.CMDIndexVaultExportCredentials)
+	if len(requests) != 3 || string(requests[2].GetData()) != "c1" {
+		t.Fatalf("%d credential pages asked", len(requests))
+	}
+}
+
+func TestVaultExportRefusesCursorWithoutKind(t *testing.T) {
+	_, c := start(t)
+	var buf bytes.Buffer
+	rsp, err := c.Export(context.Background(), map[string]any{"resume_cursor": "c1"}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp == nil || rsp.Code == model.StatusOK || buf.Len() != 0 {
+		t.Fatalf("export answered %+v with %d bytes", rsp, buf.Len())
+	}
+}
@@ -24,7 +24,7 @@ import (
 func (s *Service) AdminAddMember(ctx context.Context, req *pb.AdminAddMemberReq) (*pb.AdminAddMemberRsp, error) {
 	rsp := new(pb.AdminAddMemberRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.AdminCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -42,7 +42,7 @@ func (s *Service) AdminAddMember(ctx context.Context, req *pb.AdminAddMemberReq)
 		return rsp, nil
 	}
 
-	if errMsg, ok := params.Check(model.AdminAddMemberToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.AdminAddMemberToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("admin add member params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
@@ -76,7 +76,7 @@ func (s *Service) AdminAddMember(ctx context.Context, req *pb.AdminAddMemberReq)
 func (s *Service) AdminAuthorization(ctx context.Context, req *pb.AdminAuthorizationReq) (*pb.AdminAuthorizationRsp, error) {
 	rsp := new(pb.AdminAuthorizationRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	params := model.AdminAuthorizationParams{}
 	trace_id := util.GetTraceid(ctx)
 	log.Logger.Info("AdminAuthorization body", log.String("trace_id", trace_id), log.String("params", req.GetParams()))
@@ -86,16 +86,10 @@ func (s *Service) AdminAuthorization(ctx context.Context, req *pb.AdminAuthoriza
 		log.Logger.Warn("AdminAuthorizationReq params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
 		return rsp, nil
 	}
-	if !params.Check() {
+	if errMsg, ok := params.Check(); !ok {
 		rsp.Code = model.StatusParamsErr
-		rsp.Msg = "params error"
-		log.Logger.Warn("AdminAuthorizationReq params fail", log.String("trace_id", trace_id))
-		return rsp, nil
-	}
-	if !util.CheckTimestamp(params.Timestamp) {
-		rsp.Code = model.StatusTimestampErr
-		rsp.Msg = model.MsgTimestamp
-		log.Logger.Warn("AdminAuthorizationReq timestamp fail", log.String("trace_id", trace_id))
+		rsp.Msg = errMsg
+		log.Logger.Warn("AdminAuthorizationReq params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
 		return rsp, nil
 	}
 	if !util.CheckSignature(params.Address, req.GetSignature(), req.GetParams()) {
@@ -132,7 +126,7 @@ func (s *Service) AdminAuthorization(ctx context.Context, req *pb.AdminAuthoriza
 func (s *Service) AdminUpdateMember(ctx context.Context, req *pb.AdminUpdateMemberReq) (*pb.AdminUpdateMemberRsp, error) {
 	rsp := new(pb.AdminUpdateMemberRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.AdminCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -150,7 +144,7 @@ func (s *Service) AdminUpdateMember(ctx context.Context, req *pb.AdminUpdateMemb
 		return rsp, nil
 	}
 
-	if errMsg, ok := params.Check(model.AdminUpdateMemberToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.AdminUpdateMemberToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("AdminUpdateMember params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
@@ -183,7 +177,7 @@ func (s *Service) AdminUpdateMember(ctx context.Context, req *pb.AdminUpdateMemb
 func (s *Service) AdminRemoveMember(ctx context.Context, req *pb.AdminRemoveMemberReq) (*pb.AdminRemoveMemberRsp, error) {
 	rsp := new(pb.AdminRemoveMemberRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.AdminCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -204,7 +198,7 @@ func (s *Service) AdminRemoveMember(ctx context.Context, req *pb.AdminRemoveMemb
 
 	log.Logger.Debug("AdminRemoveMember data", log.String("trace_id", trace_id))
 
-	if errMsg, ok := params.Check(model.AdminRemoveMemberToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.AdminRemoveMemberToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("AdminRemoveMember params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
@@ -237,7 +231,7 @@ func (s *Service) AdminRemoveMember(ctx context.Context, req *pb.AdminRemoveMemb
 func (s *Service) AdminGetMemberList(ctx context.Context, req *pb.AdminGetMemberListReq) (*pb.AdminGetMemberListRsp, error) {
 	rsp := new(pb.AdminGetMemberListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	params := model.AdminCommonParams{}
 	trace_id := util.GetTraceid(ctx)
 	log.Logger.Info("AdminGetMemberList body", log.String("trace_id", trace_id), log.Any("params", req.GetParams()))
@@ -253,7 +247,7 @@ func (s *Service) AdminGetMemberList(ctx context.Context, req *pb.AdminGetMember
 		log.Logger.Warn("AdminGetMemberList signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.AdminGetMemberListToken, params.Timestamp, []byte{}); !ok {
+	if errMsg, ok := params.Check(model.AdminGetMemberListToken, []byte{}); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("AdminGetMemberList params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
@@ -287,7 +281,7 @@ func (s *Service) AdminGetMemberList(ctx context.Context, req *pb.AdminGetMember
 func (s *Service) GetAdminMnemonic(ctx context.Context, req *pb.GetAdminMnemonicReq) (*pb.GetAdminMnemonicRsp, error) {
 	rsp := new(pb.GetAdminMnemonicRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.GetAdminMnemonicRsp_Data{}
 	params := model.AdminCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -304,7 +298,7 @@ func (s *Service) GetAdminMnemonic(ctx context.Context, req *pb.GetAdminMnemonic
 		log.Logger.Warn("GetAdminMnemonic signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.AdminGetAdminMnemonicToken, params.Timestamp, []byte{}); !ok {
+	if errMsg, ok := params.Check(model.AdminGetAdminMnemonicToken, []byte{}); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("GetAdminMnemonic params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
@@ -338,7 +332,7 @@ func (s *Service) GetAdminMnemonic(ctx context.Context, req *pb.GetAdminMnemonic
 func (s *Service) AdminBatchImportMember(ctx context.Context, req *pb.AdminBatchImportMemberReq) (*pb.AdminBatchImportMemberRsp, error) {
 	rsp := new(pb.AdminBatchImportMemberRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	params := model.AdminCommonParams{}
 	trace_id := util.GetTraceid(ctx)
 
@@ -356,7 +350,7 @@ func (s *Service) AdminBatchImportMember(ctx context.Context, req *pb.AdminBatch
 		log.Logger.Warn("AdminBatchImportMember signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.AdminBatchImportMemberToken, params.Timestamp, []byte{}); !ok {
+	if errMsg, ok := params.Check(model.AdminBatchImportMemberToken, []byte{}); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("AdminBatchImportMember params fail", log.String("trace_id", trace_id))
@@ -388,7 +382,7 @@ func (s *Service) AdminBatchImportMember(ctx context.Context, req *pb.AdminBatch
 func (s *Service) AdminUpdateOrgInfo(ctx context.Context, req *pb.AdminUpdateOrgInfoReq) (*pb.AdminUpdateOrgInfoRsp, error) {
 	rsp := new(pb.AdminUpdateOrgInfoRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	params := model.AdminGetOrgInfoParams{}
 	trace_id := util.GetTraceid(ctx)
 	log.Logger.Info("AdminUpdateOrgInfo body", log.String("trace_id", trace_id), log.String("params", req.GetParams()))
@@ -398,17 +392,10 @@ func (s *Service) AdminUpdateOrgInfo(ctx context.Context, req *pb.AdminUpdateOrg
 		log.Logger.Warn("AdminUpdateOrgInfo params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
 		return rsp, nil
 	}
-	if !params.Check() {
+	if errMsg, ok := params.Check(model.AdminUpdateOrgInfoToken); !ok {
 		rsp.Code = model.StatusParamsErr
-		rsp.Msg = model.MsgParamsErr
-		log.Logger.Warn("AdminUpdateOrgInfo params fail", log.String("trace_id", trace_id))
-
-		return rsp, nil
-	}
-	if !util.CheckTimestamp(params.Timestamp) {
-		rsp.Code = model.StatusTimestampErr
-		rsp.Msg = model.MsgTimestamp
-		log.Logger.Warn("AdminUpdateOrgInfo timestamp fail", log.String("trace_id", trace_id))
+		rsp.Msg = errMsg
+		log.Logger.Warn("AdminUpdateOrgInfo params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
 
 		return rsp, nil
 	}
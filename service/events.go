@@ -0,0 +1,80 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package service
+
+import (
+	"context"
+	"errors"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/notify"
+	"github.com/web3password/satis/util"
+)
+
+// SubscribeEvents subscribes the signer to the notifications of their address
+// and, once the ares node confirms the membership, of their org_id. The
+// subscription is nil unless the code is StatusOK, the caller closes it.
+func (s *Service) SubscribeEvents(ctx context.Context, req *model.EventsReq) (*notify.Subscription, *model.EventsRsp, error) {
+	rsp := new(model.EventsRsp)
+	rsp.Code = model.StatusServiceCheckErr
+	rsp.Msg = model.MsgServiceCheckErr
+	params := model.EventsParams{}
+	trace_id := util.GetTraceid(ctx)
+	if err := jsoniter.UnmarshalFromString(req.Params, &params); err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = model.MsgParamsErr
+		log.Logger.Warn("SubscribeEvents params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return nil, rsp, nil
+	}
+	if errMsg, ok := params.Check(); !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = errMsg
+		log.Logger.Warn("SubscribeEvents check params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
+		return nil, rsp, nil
+	}
+	if !util.CheckSignature(params.Address, req.Signature, req.Params) {
+		rsp.Code = model.StatusSignatureErr
+		rsp.Msg = model.MsgSignatureErr
+		log.Logger.Warn("SubscribeEvents signature fail", log.String("trace_id", trace_id))
+		return nil, rsp, nil
+	}
+	if params.OrgId != "" {
+		ret, err := s.dao.CheckOrgMember(ctx, req.Signature, req.Params)
+		if err != nil {
+			log.Logger.Error("SubscribeEvents check org member error", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+			return nil, rsp, nil
+		}
+		if ret.Code != model.StatusOK {
+			rsp.Code = ret.Code
+			rsp.Msg = ret.Msg
+			log.Logger.Warn("SubscribeEvents not an org member", log.String("trace_id", trace_id), log.String("org_id", params.OrgId), log.Any("ret.code", ret.Code))
+			return nil, rsp, nil
+		}
+	}
+
+	sub, err := s.dao.Subscribe(params.Address, params.OrgId, config.GetConfig().Events.MaxPerAddr)
+	if errors.Is(err, notify.ErrTooManySubscriptions) {
+		rsp.Code = model.StatusLimitCheckErr
+		rsp.Msg = err.Error()
+		log.Logger.Warn("SubscribeEvents too many subscriptions", log.String("trace_id", trace_id))
+		return nil, rsp, nil
+	}
+	if err != nil {
+		log.Logger.Error("SubscribeEvents subscribe error", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return nil, rsp, nil
+	}
+	rsp.Code = model.StatusOK
+	rsp.Msg = model.MsgOK
+	log.Logger.Info("SubscribeEvents subscribed", log.String("trace_id", trace_id), log.String("org_id", params.OrgId))
+	return sub, rsp, nil
+}
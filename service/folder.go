@@ -28,7 +28,7 @@ import (
 func (s *Service) ShareFolderCreate(ctx context.Context, req *pb.ShareFolderCreateReq) (*pb.ShareFolderCreateRsp, error) {
 	rsp := new(pb.ShareFolderCreateRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -78,7 +78,7 @@ func (s *Service) ShareFolderCreate(ctx context.Context, req *pb.ShareFolderCrea
 func (s *Service) ShareFolderUpdate(ctx context.Context, req *pb.ShareFolderUpdateReq) (*pb.ShareFolderUpdateRsp, error) {
 	rsp := new(pb.ShareFolderUpdateRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderUpdateParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -128,7 +128,7 @@ func (s *Service) ShareFolderUpdate(ctx context.Context, req *pb.ShareFolderUpda
 func (s *Service) ShareFolderDestroy(ctx context.Context, req *pb.ShareFolderDestroyReq) (*pb.ShareFolderDestroyRsp, error) {
 	rsp := new(pb.ShareFolderDestroyRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -146,7 +146,7 @@ func (s *Service) ShareFolderDestroy(ctx context.Context, req *pb.ShareFolderDes
 		log.Logger.Warn("ShareFolderDestroy signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.ShareFolderDestroyToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderDestroyToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderDestroy timestamp fail", log.String("trace_id", trace_id))
@@ -177,7 +177,7 @@ func (s *Service) ShareFolderDestroy(ctx context.Context, req *pb.ShareFolderDes
 func (s *Service) ShareFolderAddMember(ctx context.Context, req *pb.ShareFolderAddMemberReq) (*pb.ShareFolderAddMemberRsp, error) {
 	rsp := new(pb.ShareFolderAddMemberRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -196,7 +196,7 @@ func (s *Service) ShareFolderAddMember(ctx context.Context, req *pb.ShareFolderA
 		return rsp, nil
 	}
 
-	if errMsg, ok := params.Check(model.ShareFolderAddMemberToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderAddMemberToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderDestroy timestamp fail", log.String("trace_id", trace_id))
@@ -229,7 +229,7 @@ func (s *Service) ShareFolderAddMember(ctx context.Context, req *pb.ShareFolderA
 func (s *Service) ShareFolderUpdateMember(ctx context.Context, req *pb.ShareFolderUpdateMemberReq) (*pb.ShareFolderUpdateMemberRsp, error) {
 	rsp := new(pb.ShareFolderUpdateMemberRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -249,7 +249,7 @@ func (s *Service) ShareFolderUpdateMember(ctx context.Context, req *pb.ShareFold
 		log.Logger.Warn("ShareFolderUpdateMember signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.ShareFolderUpdateMemberToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderUpdateMemberToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderUpdateMember timestamp fail,", log.String("errMsg", errMsg), log.String("trace_id", trace_id))
@@ -281,7 +281,7 @@ func (s *Service) ShareFolderUpdateMember(ctx context.Context, req *pb.ShareFold
 func (s *Service) ShareFolderAddRecord(ctx context.Context, req *pb.ShareFolderAddRecordReq) (*pb.ShareFolderAddRecordRsp, error) {
 	rsp := new(pb.ShareFolderAddRecordRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderAddRecordParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -331,7 +331,7 @@ func (s *Service) ShareFolderAddRecord(ctx context.Context, req *pb.ShareFolderA
 func (s *Service) ShareFolderDeleteRecord(ctx context.Context, req *pb.ShareFolderDeleteRecordReq) (*pb.ShareFolderDeleteRecordRsp, error) {
 	rsp := new(pb.ShareFolderDeleteRecordRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderDeleteRecordParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -383,7 +383,7 @@ func (s *Service) ShareFolderDeleteRecord(ctx context.Context, req *pb.ShareFold
 func (s *Service) ShareFolderFolderList(ctx context.Context, req *pb.ShareFolderFolderListReq) (*pb.ShareFolderFolderListRsp, error) {
 	rsp := new(pb.ShareFolderFolderListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	trace_id := util.GetTraceid(ctx)
 	params := model.ShareFolderCommonParams{}
 
@@ -401,7 +401,7 @@ func (s *Service) ShareFolderFolderList(ctx context.Context, req *pb.ShareFolder
 		log.Logger.Warn("sharefolder folder list signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.ShareFolderFolderListToken, params.Timestamp, []byte{}); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderFolderListToken, []byte{}); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("sharefolder folder list params fail", log.String("trace_id", trace_id))
@@ -436,8 +436,8 @@ func (s *Service) ShareFolderFolderList(ctx context.Context, req *pb.ShareFolder
 func (s *Service) ShareFolderRecordList(ctx context.Context, req *pb.ShareFolderRecordListReq) (*pb.ShareFolderRecordListRsp, error) {
 	rsp := new(pb.ShareFolderRecordListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
-	params := model.ShareFolderCommonParams{}
+	rsp.Msg = model.MsgServiceCheckErr
+	params := model.ShareFolderListParams{}
 	trace_id := util.GetTraceid(ctx)
 
 	log.Logger.Info("sharefolder record list start", log.String("trace_id", trace_id), log.Any("params", req.GetParams()))
@@ -454,7 +454,7 @@ func (s *Service) ShareFolderRecordList(ctx context.Context, req *pb.ShareFolder
 		log.Logger.Warn("sharefolder record list signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.ShareFolderRecordListToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderRecordListToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("sharefolder record list params fail", log.String("trace_id", trace_id))
@@ -488,7 +488,7 @@ func (s *Service) ShareFolderRecordList(ctx context.Context, req *pb.ShareFolder
 func (s *Service) ShareFolderRecordListByRid(ctx context.Context, req *pb.ShareFolderRecordListByRidReq) (*pb.ShareFolderRecordListByRidRsp, error) {
 	rsp := new(pb.ShareFolderRecordListByRidRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	params := model.ShareFolderCommonParams{}
 	trace_id := util.GetTraceid(ctx)
 
@@ -506,7 +506,7 @@ func (s *Service) ShareFolderRecordListByRid(ctx context.Context, req *pb.ShareF
 		log.Logger.Warn("ShareFolderRecordListByRid params fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.ShareFolderRecordListTokenByRid, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderRecordListTokenByRid, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderRecordListByRid params fail", log.String("trace_id", trace_id))
@@ -541,8 +541,8 @@ func (s *Service) ShareFolderRecordListByRid(ctx context.Context, req *pb.ShareF
 func (s *Service) ShareFolderMemberList(ctx context.Context, req *pb.ShareFolderMemberListReq) (*pb.ShareFolderMemberListRsp, error) {
 	rsp := new(pb.ShareFolderMemberListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
-	params := model.ShareFolderCommonParams{}
+	rsp.Msg = model.MsgServiceCheckErr
+	params := model.ShareFolderListParams{}
 	trace_id := util.GetTraceid(ctx)
 
 	log.Logger.Info("ShareFolderMemberList start", log.String("trace_id", trace_id), log.Any("params", req.GetParams()))
@@ -559,7 +559,7 @@ func (s *Service) ShareFolderMemberList(ctx context.Context, req *pb.ShareFolder
 		log.Logger.Warn("ShareFolderMemberList signature fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.ShareFolderMemberListToken, params.Timestamp, []byte{}); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderMemberListToken, []byte{}); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderMemberList params fail", log.String("trace_id", trace_id))
@@ -594,7 +594,7 @@ func (s *Service) ShareFolderMemberList(ctx context.Context, req *pb.ShareFolder
 func (s *Service) ShareFolderDeleteMember(ctx context.Context, req *pb.ShareFolderDeleteMemberReq) (*pb.ShareFolderDeleteMemberRsp, error) {
 	rsp := new(pb.ShareFolderDeleteMemberRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -615,7 +615,7 @@ func (s *Service) ShareFolderDeleteMember(ctx context.Context, req *pb.ShareFold
 		return rsp, nil
 	}
 
-	if errMsg, ok := params.Check(model.ShareFolderDeleteMemberToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderDeleteMemberToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderDeleteMember params fail", log.String("trace_id", trace_id))
@@ -647,7 +647,7 @@ func (s *Service) ShareFolderDeleteMember(ctx context.Context, req *pb.ShareFold
 func (s *Service) ShareFolderMemberExit(ctx context.Context, req *pb.ShareFolderMemberExitReq) (*pb.ShareFolderMemberExitRsp, error) {
 	rsp := new(pb.ShareFolderMemberExitRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -667,7 +667,7 @@ func (s *Service) ShareFolderMemberExit(ctx context.Context, req *pb.ShareFolder
 		return rsp, nil
 	}
 
-	if errMsg, ok := params.Check(model.ShareFolderMemberExitToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderMemberExitToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderMemberExit params fail", log.String("trace_id", trace_id))
@@ -700,7 +700,7 @@ func (s *Service) ShareFolderMemberExit(ctx context.Context, req *pb.ShareFolder
 func (s *Service) ShareFolderBatchUpdate(ctx context.Context, req *pb.ShareFolderBatchUpdateReq) (*pb.ShareFolderBatchUpdateRsp, error) {
 	rsp := new(pb.ShareFolderBatchUpdateRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.ShareFolderCommonParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -721,7 +721,7 @@ func (s *Service) ShareFolderBatchUpdate(ctx context.Context, req *pb.ShareFolde
 		return rsp, nil
 	}
 
-	if errMsg, ok := params.Check(model.ShareFolderBatchUpdateToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.ShareFolderBatchUpdateToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("ShareFolderBatchUpdate params fail", log.String("trace_id", trace_id))
@@ -206,7 +206,8 @@ func AdminGetMemberList(ctx *gin.Context) {
 	gtx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(
 		"trace_id", ctx.GetString("trace_id"),
 	))
-	rsp, err := userClient.AdminGetMemberList(gtx, req)
+	var header metadata.MD
+	rsp, err := userClient.AdminGetMemberList(gtx, req, grpc.Header(&header))
 	if err != nil {
 		log.Logger.Error("AdminGetMemberList rsp error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
 		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
@@ -232,7 +233,8 @@ func AdminGetMemberList(ctx *gin.Context) {
 	}
 
 	wrapData := model.AdminMemberListRsp{
-		List: rspData,
+		List:       rspData,
+		NextCursor: nextCursor(header),
 	}
 
 	log.Logger.Debug("AdminGetMemberList end, datadata", log.String("trace_id", ctx.GetString("trace_id")), log.Any("data", wrapData))
@@ -300,7 +302,8 @@ func AdminOperationHistory(ctx *gin.Context) {
 	))
 	//var metadata runtime.ServerMetadata
 	//rsp, _ := userClient.AdminOperationHistory(context.Background(), req, grpc.Header(&metadata.HeaderMD), grpc.Trailer(&metadata.TrailerMD))
-	rsp, err := userClient.AdminOperationHistory(gtx, req)
+	var header metadata.MD
+	rsp, err := userClient.AdminOperationHistory(gtx, req, grpc.Header(&header))
 	if err != nil {
 		log.Logger.Error("AdminOperationHistory error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
 		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
@@ -324,6 +327,9 @@ func AdminOperationHistory(ctx *gin.Context) {
 	wrapData := map[string]interface{}{
 		"list": rspData,
 	}
+	if cursor := nextCursor(header); cursor != "" {
+		wrapData[model.NextCursorKey] = cursor
+	}
 	log.Logger.Debug("AdminOperationHistory end, datadata", log.String("trace_id", ctx.GetString("trace_id")), log.Any("len", len(wrapData)))
 	bytes, _ := bson.Marshal(wrapData)
 	Response(ctx, int(rsp.GetCode()), rsp.GetMsg(), bytes)
@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+)
+
+// SSE events of the stream besides the notification types.
+const (
+	EventReady     = "ready"     // first event, the stream is subscribed
+	EventResync    = "resync"    // notifications were dropped, fetch the current state
+	EventReconnect = "reconnect" // last event, max_lifetime reached
+)
+
+// Events streams the change notifications of the signer as text/event-stream,
+// one event per notification named after its type. A refused subscription is
+// a BSON response as usual. The stream ends after events.max_lifetime, the
+// client then subscribes again with a fresh signature.
+func Events(ctx *gin.Context) {
+	value, ok := ctx.Get("request")
+	if !ok {
+		Response(ctx, model.StatusParamsErr, model.MsgParamsErr, emptyByte)
+		ctx.Abort()
+		return
+	}
+
+	obj := value.(*encode.Web3PasswordRequestBsonStruct)
+	traceID := ctx.GetString("trace_id")
+
+	req := &model.EventsReq{
+		Signature: obj.SignatureStr,
+		Params:    obj.ParamsStr,
+	}
+	log.Logger.Debug("Events start", log.String("trace_id", traceID), log.Any("req", req.Params))
+	sub, rsp, err := service.SubscribeEvents(serviceContext(ctx), req)
+	if err != nil {
+		log.Logger.Error("Events error", log.String("trace_id", traceID), log.Error(err))
+		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
+		return
+	}
+	if rsp.Code != model.StatusOK {
+		log.Logger.Warn("Events rsp warning", log.String("trace_id", traceID), log.Any("rsp", rsp))
+		Response(ctx, int(rsp.Code), rsp.Msg, emptyByte)
+		return
+	}
+	defer sub.Close()
+
+	conf := config.GetConfig().Events
+	heartbeat := time.NewTicker(time.Duration(conf.Heartbeat) * time.Second)
+	defer heartbeat.Stop()
+	lifetime := time.NewTimer(time.Duration(conf.MaxLifetime) * time.Second)
+	defer lifetime.Stop()
+
+	if IsHttpWithTraceID() {
+		ctx.Header("X-Trace-id", traceID)
+	}
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	w := &sseWriter{ctx: ctx}
+	w.event(EventReady, map[string]int{"heartbeat": conf.Heartbeat, "max_lifetime": conf.MaxLifetime})
+	for w.err == nil {
+		select {
+		case <-ctx.Request.Context().Done():
+			log.Logger.Debug("Events client gone", log.String("trace_id", traceID), log.Any("sent", w.seq))
+			return
+		case n := <-sub.C():
+			w.event(n.Type, n)
+		case <-sub.Resync():
+			w.event(EventResync, struct{}{})
+		case <-heartbeat.C:
+			w.comment("ping")
+		case <-lifetime.C:
+			w.event(EventReconnect, struct{}{})
+			log.Logger.Debug("Events max lifetime", log.String("trace_id", traceID), log.Any("sent", w.seq))
+			return
+		}
+	}
+	log.Logger.Debug("Events write error", log.String("trace_id", traceID), log.Error(w.err))
+}
+
+// sseWriter writes and flushes the events of a stream, keeping the first error.
+type sseWriter struct {
+	ctx *gin.Context
+	seq int64
+	err error
+}
+
+func (w *sseWriter) event(name string, data any) {
+	b, err := jsoniter.Marshal(data)
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.seq++
+	w.write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", w.seq, name, b))
+}
+
+func (w *sseWriter) comment(text string) {
+	w.write(": " + text + "\n\n")
+}
+
+func (w *sseWriter) write(s string) {
+	if w.err != nil {
+		return
+	}
+	if _, w.err = w.ctx.Writer.WriteString(s); w.err == nil {
+		w.ctx.Writer.Flush()
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/web3password/satis/log"
 	"github.com/web3password/satis/model"
 	pb "github.com/web3password/w3p-protobuf/user"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -423,7 +424,8 @@ func ShareFolderRecordList(ctx *gin.Context) {
 	gtx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(
 		"trace_id", ctx.GetString("trace_id"),
 	))
-	rsp, err := userClient.ShareFolderRecordList(gtx, req)
+	var header metadata.MD
+	rsp, err := userClient.ShareFolderRecordList(gtx, req, grpc.Header(&header))
 	if err != nil {
 		log.Logger.Error("ShareFolderRecordList rsp error", log.Error(err), log.String("trace_id", ctx.GetString("trace_id")))
 		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
@@ -450,7 +452,8 @@ func ShareFolderRecordList(ctx *gin.Context) {
 	}
 
 	wrapData := model.ShareFolderRecordRsp{
-		List: rspData,
+		List:       rspData,
+		NextCursor: nextCursor(header),
 	}
 	bytes, _ := bson.Marshal(wrapData)
 	Response(ctx, int(rsp.GetCode()), rsp.GetMsg(), bytes)
@@ -611,7 +614,8 @@ func ShareFolderMemberList(ctx *gin.Context) {
 	gtx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(
 		"trace_id", ctx.GetString("trace_id"),
 	))
-	rsp, err := userClient.ShareFolderMemberList(gtx, req)
+	var header metadata.MD
+	rsp, err := userClient.ShareFolderMemberList(gtx, req, grpc.Header(&header))
 	if err != nil {
 		log.Logger.Error("ShareFolderMemberList error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
 		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
@@ -637,7 +641,8 @@ func ShareFolderMemberList(ctx *gin.Context) {
 	}
 
 	wrapData := model.ShareFolderMemberListRsp{
-		List: rspData,
+		List:       rspData,
+		NextCursor: nextCursor(header),
 	}
 
 	log.Logger.Info("ShareFolderMemberList end", log.String("trace_id", ctx.GetString("trace_id")))
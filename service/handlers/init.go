@@ -12,22 +12,58 @@ package handlers
 import (
 	"context"
 	"gopkg.in/mgo.v2/bson"
+	"io"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
-	"github.com/web3password/jewel/tools"
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/satis/certs"
 	"github.com/web3password/satis/config"
 	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/notify"
 	pb "github.com/web3password/w3p-protobuf/user"
 )
 
 var (
 	userClient pb.UserClient
+	service    Service
 	emptyByte  []byte
 )
 
+// Service is the in-process satis service, it serves the routes the grpc
+// api has no method for.
+type Service interface {
+	SyncCredentials(ctx context.Context, req *model.SyncCredentialsReq) (*model.SyncCredentialsRsp, error)
+	VaultExport(ctx context.Context, req *model.VaultReq, w io.Writer) (*model.ResponseBytes, error)
+	VaultImport(ctx context.Context, req *model.VaultReq) (*model.VaultImportRsp, error)
+	VaultImportStatus(ctx context.Context, req *model.VaultReq) (*model.VaultImportRsp, error)
+	Transaction(ctx context.Context, req *model.TransactionReq) (*model.TransactionRsp, error)
+	SubscribeEvents(ctx context.Context, req *model.EventsReq) (*notify.Subscription, *model.EventsRsp, error)
+}
+
+// SetService sets the service of the routes without a grpc method.
+func SetService(s Service) {
+	service = s
+}
+
+// serviceContext carries the trace id of a request to the in-process service,
+// as the grpc metadata would.
+func serviceContext(ctx *gin.Context) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("trace_id", ctx.GetString("trace_id")))
+}
+
+// nextCursor is the next_cursor header of a list response, empty on the last page.
+func nextCursor(header metadata.MD) string {
+	if values := header.Get(model.NextCursorKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
 func Init(conf *config.Config) {
 	type empty struct {
 	}
@@ -42,12 +78,11 @@ func NewUserClient(conf *config.Config) pb.UserClient {
 		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(conf.MsgSize.File)),
 	}
 	if conf.Server.EnableTLS {
-		tlsConfig, err := tools.TLSClientConfig(conf.Tls.Ca, conf.Tls.ClientTls.Crt, conf.Tls.ClientTls.Key)
+		reloader, err := certs.NewReloader(conf.Tls.Ca, conf.Tls.ClientTls.Crt, conf.Tls.ClientTls.Key)
 		if err != nil {
 			panic(err)
 		}
-		tlsConfig.ServerName = conf.Server.TLSDomain
-		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(reloader.ClientConfig(conf.Server.TLSDomain))))
 	} else {
 		options = append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
@@ -83,3 +118,7 @@ func GetRunningMode() string {
 func IsHttpWithTraceID() bool {
 	return config.GetConfig().HttpServer.WithTraceID
 }
+
+func IsHttpErrorStatus() bool {
+	return config.GetConfig().HttpServer.ErrorStatus
+}
@@ -12,6 +12,7 @@ package handlers
 import (
 	"context"
 	"github.com/web3password/satis/log"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"time"
 
@@ -427,7 +428,8 @@ func GetCredentialList(ctx *gin.Context) {
 	gtx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(
 		"trace_id", ctx.GetString("trace_id"),
 	))
-	rsp, err := userClient.GetCredentialList(gtx, req)
+	var header metadata.MD
+	rsp, err := userClient.GetCredentialList(gtx, req, grpc.Header(&header))
 	if err != nil {
 		log.Logger.Error("GetCredentialList error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err), log.Any("req", req.GetParams()))
 		Response(ctx, int(rsp.GetCode()), rsp.GetMsg(), empty)
@@ -450,9 +452,43 @@ func GetCredentialList(ctx *gin.Context) {
 	}
 	log.Logger.Debug("GetCredentialList end", log.String("trace_id", ctx.GetString("trace_id")), log.Any("count", len(rsp.GetData())))
 	wrapData := model.GetCredentialRspData{
-		List: rspData,
+		List:       rspData,
+		NextCursor: nextCursor(header),
 	}
 	bytes, _ := bson.Marshal(wrapData)
 	Response(ctx, int(rsp.GetCode()), rsp.GetMsg(), bytes)
 	return
 }
+
+func SyncCredentials(ctx *gin.Context) {
+	value, ok := ctx.Get("request")
+	if !ok {
+		Response(ctx, model.StatusParamsErr, model.MsgParamsErr, emptyByte)
+		ctx.Abort()
+		return
+	}
+
+	obj := value.(*encode.Web3PasswordRequestBsonStruct)
+
+	req := &model.SyncCredentialsReq{
+		Signature: obj.SignatureStr,
+		Params:    obj.ParamsStr,
+	}
+	log.Logger.Debug("SyncCredentials start", log.String("trace_id", ctx.GetString("trace_id")), log.Any("req", req.Params))
+	rsp, err := service.SyncCredentials(serviceContext(ctx), req)
+	if err != nil {
+		log.Logger.Error("SyncCredentials error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
+		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
+		return
+	}
+
+	if rsp.Code != model.StatusOK {
+		log.Logger.Warn("SyncCredentials rsp warning", log.String("trace_id", ctx.GetString("trace_id")), log.Any("code", rsp.Code), log.String("msg", rsp.Msg))
+		Response(ctx, int(rsp.Code), rsp.Msg, emptyByte)
+		return
+	}
+
+	bytes, _ := bson.Marshal(rsp.Data)
+	Response(ctx, int(rsp.Code), rsp.Msg, bytes)
+	log.Logger.Debug("SyncCredentials end", log.String("trace_id", ctx.GetString("trace_id")), log.Any("count", len(rsp.Data.Changes)))
+}
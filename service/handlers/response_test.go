@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/model"
+)
+
+func TestResponseErrorStatus(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	for _, errorStatus := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		yaml := "running_mode: local\nofficial_domains: [http://127.0.0.1:1]\nnode:\n  token: test\nlog_dir: " + t.TempDir() +
+			"\nhttp_server:\n  port: \"8080\"\n  error_status: " + strconv.FormatBool(errorStatus) + "\nserver:\n  port: \"8081\"\n"
+		if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.ParseConfig(path); err != nil {
+			t.Fatal(err)
+		}
+		for _, code := range []*model.ErrorCode{model.CodeParams, model.CodeForbidden, model.CodeIdempotencyConflict, model.CodeSystem} {
+			rec := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(rec)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/web3password/addCredential", nil)
+			ResponseError(ctx, model.NewError(code, ""))
+			status := http.StatusOK
+			if errorStatus {
+				status = code.Category.HTTPStatus()
+			}
+			if rec.Code != status {
+				t.Errorf("error_status %v %s: status %d, want %d", errorStatus, code.Key, rec.Code, status)
+			}
+			if got := rec.Header().Get("X-Error-Key"); got != code.Key {
+				t.Errorf("%s: X-Error-Key %q", code.Key, got)
+			}
+			ret, err := encode.Web3PasswordResponseBsonDecode(rec.Body.Bytes())
+			if err != nil || ret.Code != int(code.Code) || ret.Msg != code.Msg {
+				t.Errorf("%s: body %+v err %v", code.Key, ret, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/session"
+	"github.com/web3password/satis/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreateSession exchanges a personal_auth for a session token, served by satis itself.
+func CreateSession(ctx *gin.Context) {
+	traceID := ctx.GetString("trace_id")
+	value, ok := ctx.Get("request")
+	if !ok {
+		ResponseError(ctx, model.NewError(model.CodeParams, ""))
+		ctx.Abort()
+		return
+	}
+	obj := value.(*encode.Web3PasswordRequestBsonStruct)
+
+	params := model.SessionParams{}
+	if err := jsoniter.UnmarshalFromString(obj.ParamsStr, &params); err != nil {
+		log.Logger.Warn("CreateSession params parse fail", log.String("trace_id", traceID), log.String("errmsg", err.Error()))
+		ResponseError(ctx, model.NewError(model.CodeParams, ""))
+		return
+	}
+	if errMsg, ok := params.Check(); !ok {
+		log.Logger.Warn("CreateSession params fail", log.String("trace_id", traceID), log.String("errmsg", errMsg))
+		ResponseError(ctx, model.NewError(model.CodeParams, errMsg))
+		return
+	}
+	if !util.CheckSignature(params.Address, obj.SignatureStr, obj.ParamsStr) {
+		log.Logger.Warn("CreateSession signature fail", log.String("trace_id", traceID))
+		ResponseError(ctx, model.NewError(model.CodeSignature, ""))
+		return
+	}
+	auth, err := util.ParseAuthParams(params.Auth)
+	if err != nil {
+		log.Logger.Warn("CreateSession ParseAuthParams personal auth fail", log.String("trace_id", traceID), log.String("errmsg", err.Error()))
+		ResponseError(ctx, model.NewError(model.CodeAuth, err.Error()))
+		return
+	}
+	if !strings.EqualFold(auth.PrimaryAddress, params.Address) {
+		log.Logger.Warn("CreateSession personal auth of another address", log.String("trace_id", traceID))
+		ResponseError(ctx, model.NewError(model.CodeAuth, "personal_auth is not of addr"))
+		return
+	}
+
+	token, claims, err := session.Issue(params.Address, auth.Timestamp)
+	if err != nil {
+		log.Logger.Warn("CreateSession issue fail", log.String("trace_id", traceID), log.String("errmsg", err.Error()))
+		if errors.Is(err, session.ErrDisabled) {
+			ResponseError(ctx, model.NewError(model.CodeForbidden, err.Error()))
+			return
+		}
+		ResponseError(ctx, model.NewError(model.CodeAuth, err.Error()))
+		return
+	}
+	log.Logger.Info("CreateSession end", log.String("trace_id", traceID), log.String("jti", claims.ID), log.Int64("expire_at", claims.Expire))
+	bytes, _ := bson.Marshal(model.SessionRsp{SessionToken: token, ExpireAt: claims.Expire})
+	Response(ctx, model.StatusOK, model.MsgOK, bytes)
+}
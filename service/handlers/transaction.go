@@ -0,0 +1,50 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Transaction applies the ops in data all or none. The data of the response
+// says whether they were committed and, when not, which op failed.
+func Transaction(ctx *gin.Context) {
+	value, ok := ctx.Get("request")
+	if !ok {
+		Response(ctx, model.StatusParamsErr, model.MsgParamsErr, emptyByte)
+		ctx.Abort()
+		return
+	}
+
+	obj := value.(*encode.Web3PasswordRequestBsonStruct)
+
+	req := &model.TransactionReq{
+		Signature: obj.SignatureStr,
+		Params:    obj.ParamsStr,
+		Data:      obj.AppendData,
+	}
+	log.Logger.Debug("Transaction start", log.String("trace_id", ctx.GetString("trace_id")), log.Any("req", req.Params))
+	rsp, err := service.Transaction(serviceContext(ctx), req)
+	if err != nil {
+		log.Logger.Error("Transaction error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
+		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
+		return
+	}
+
+	if rsp.Code != model.StatusOK {
+		log.Logger.Warn("Transaction rsp warning", log.String("trace_id", ctx.GetString("trace_id")), log.Any("rsp", rsp))
+	}
+	bytes, _ := bson.Marshal(rsp.Data)
+	Response(ctx, int(rsp.Code), rsp.Msg, bytes)
+}
@@ -227,6 +227,18 @@ func Response(ctx *gin.Context, code int, msg string, data []byte) {
 	_, _ = ctx.Writer.Write(response)
 }
 
+// ResponseError writes err as a BSON response with its catalog code and message,
+// the stable key goes to the X-Error-Key header. The response is sent with 200
+// unless http_server.error_status asks for the HTTP status of its category.
+func ResponseError(ctx *gin.Context, err error) {
+	e := model.AsError(err)
+	ctx.Header("X-Error-Key", e.Code.Key)
+	if IsHttpErrorStatus() {
+		ctx.Status(e.Code.Category.HTTPStatus())
+	}
+	Response(ctx, int(e.Code.Code), e.Message(), emptyByte)
+}
+
 func StorageReport(ctx *gin.Context) {
 	value, ok := ctx.Get("request")
 	if !ok {
@@ -0,0 +1,117 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/web3password/jewel/encode"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// VaultExport streams the vault archive as application/x-tar. Errors found
+// before the first byte are BSON responses as usual, later ones cut the
+// archive short, without its manifest.
+func VaultExport(ctx *gin.Context) {
+	value, ok := ctx.Get("request")
+	if !ok {
+		Response(ctx, model.StatusParamsErr, model.MsgParamsErr, emptyByte)
+		ctx.Abort()
+		return
+	}
+
+	obj := value.(*encode.Web3PasswordRequestBsonStruct)
+
+	req := &model.VaultReq{
+		Signature: obj.SignatureStr,
+		Params:    obj.ParamsStr,
+	}
+	log.Logger.Debug("VaultExport start", log.String("trace_id", ctx.GetString("trace_id")), log.Any("req", req.Params))
+	if IsHttpWithTraceID() {
+		ctx.Header("X-Trace-id", ctx.GetString("trace_id"))
+	}
+	ctx.Header("Content-Type", "application/x-tar")
+	ctx.Header("Content-Disposition", `attachment; filename="vault.tar"`)
+	rsp, err := service.VaultExport(serviceContext(ctx), req, ctx.Writer)
+	if ctx.Writer.Written() {
+		if err != nil || rsp.Code != model.StatusOK {
+			log.Logger.Error("VaultExport archive cut short", log.String("trace_id", ctx.GetString("trace_id")), log.Any("rsp", rsp), log.Any("err", err))
+			ctx.Abort()
+		}
+		return
+	}
+
+	ctx.Writer.Header().Del("Content-Type")
+	ctx.Writer.Header().Del("Content-Disposition")
+	if err != nil {
+		log.Logger.Error("VaultExport error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
+		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
+		return
+	}
+	log.Logger.Warn("VaultExport rsp warning", log.String("trace_id", ctx.GetString("trace_id")), log.Any("rsp", rsp))
+	Response(ctx, int(rsp.Code), rsp.Msg, emptyByte)
+}
+
+func VaultImport(ctx *gin.Context) {
+	value, ok := ctx.Get("request")
+	if !ok {
+		Response(ctx, model.StatusParamsErr, model.MsgParamsErr, emptyByte)
+		ctx.Abort()
+		return
+	}
+
+	obj := value.(*encode.Web3PasswordRequestBsonStruct)
+
+	req := &model.VaultReq{
+		Signature: obj.SignatureStr,
+		Params:    obj.ParamsStr,
+		Data:      obj.AppendData,
+	}
+	log.Logger.Debug("VaultImport start", log.String("trace_id", ctx.GetString("trace_id")), log.Any("req", req.Params))
+	rsp, err := service.VaultImport(serviceContext(ctx), req)
+	vaultImportResponse(ctx, "VaultImport", rsp, err)
+}
+
+func VaultImportStatus(ctx *gin.Context) {
+	value, ok := ctx.Get("request")
+	if !ok {
+		Response(ctx, model.StatusParamsErr, model.MsgParamsErr, emptyByte)
+		ctx.Abort()
+		return
+	}
+
+	obj := value.(*encode.Web3PasswordRequestBsonStruct)
+
+	req := &model.VaultReq{
+		Signature: obj.SignatureStr,
+		Params:    obj.ParamsStr,
+	}
+	rsp, err := service.VaultImportStatus(serviceContext(ctx), req)
+	vaultImportResponse(ctx, "VaultImportStatus", rsp, err)
+}
+
+func vaultImportResponse(ctx *gin.Context, name string, rsp *model.VaultImportRsp, err error) {
+	if err != nil {
+		log.Logger.Error(name+" error", log.String("trace_id", ctx.GetString("trace_id")), log.Error(err))
+		Response(ctx, model.StatusServiceCheckErr, model.MsgSystemErr, emptyByte)
+		return
+	}
+
+	if rsp.Code != model.StatusOK {
+		log.Logger.Warn(name+" rsp warning", log.String("trace_id", ctx.GetString("trace_id")), log.Any("rsp", rsp))
+		Response(ctx, int(rsp.Code), rsp.Msg, emptyByte)
+		return
+	}
+
+	log.Logger.Debug(name+" end", log.String("trace_id", ctx.GetString("trace_id")), log.Any("progress", rsp.Data))
+	bytes, _ := bson.Marshal(rsp.Data)
+	Response(ctx, int(rsp.Code), rsp.Msg, bytes)
+}
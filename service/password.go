@@ -12,6 +12,7 @@ package service
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/web3password/satis/log"
 	"github.com/web3password/satis/model"
@@ -22,7 +23,7 @@ import (
 func (s *Service) CheckTx(ctx context.Context, req *pb.CheckTxReq) (*pb.CheckTxRsp, error) {
 	rsp := new(pb.CheckTxRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.CheckTxRsp_Data{}
 	params := model.CheckTxParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -67,7 +68,7 @@ func (s *Service) CheckTx(ctx context.Context, req *pb.CheckTxReq) (*pb.CheckTxR
 	if ret.Height > 0 {
 		rsp.Data.IsSuccess = model.StatusOK
 	} else {
-		rsp.Data.IsSuccess = model.StatusFAILED
+		rsp.Data.IsSuccess = model.TxStatusFailed
 	}
 
 	log.Logger.Info("checktx success", log.String("trace_id", trace_id), log.Any("rsp", rsp))
@@ -78,7 +79,7 @@ func (s *Service) CheckTx(ctx context.Context, req *pb.CheckTxReq) (*pb.CheckTxR
 func (s *Service) BatchCheckTx(ctx context.Context, req *pb.BatchCheckTxReq) (*pb.BatchCheckTxRsp, error) {
 	rsp := new(pb.BatchCheckTxRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.BatchCheckTxRsp_Data{}
 	params := model.BatchCheckTxParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -130,7 +131,7 @@ func (s *Service) BatchCheckTx(ctx context.Context, req *pb.BatchCheckTxReq) (*p
 func (s *Service) AddCredential(ctx context.Context, req *pb.AddCredentialReq) (*pb.AddCredentialRsp, error) {
 	rsp := new(pb.AddCredentialRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.AddCredentialRsp_Data{}
 	params := model.AddCredentialParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -182,7 +183,7 @@ func (s *Service) AddCredential(ctx context.Context, req *pb.AddCredentialReq) (
 func (s *Service) BatchAddCredential(ctx context.Context, req *pb.BatchAddCredentialReq) (*pb.BatchAddCredentialRsp, error) {
 	rsp := new(pb.BatchAddCredentialRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.BatchAddCredentialRsp_Data{}
 	params := model.BatchAddCredentialParams{}
 
@@ -235,7 +236,7 @@ func (s *Service) BatchAddCredential(ctx context.Context, req *pb.BatchAddCreden
 func (s *Service) DeleteCredential(ctx context.Context, req *pb.DeleteCredentialReq) (*pb.DeleteCredentialRsp, error) {
 	rsp := new(pb.DeleteCredentialRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.DeleteCredentialRsp_Data{}
 	params := model.DeleteCredentialParams{}
 
@@ -289,7 +290,7 @@ func (s *Service) DeleteCredential(ctx context.Context, req *pb.DeleteCredential
 func (s *Service) BatchDeleteCredential(ctx context.Context, req *pb.BatchDeleteCredentialReq) (*pb.BatchDeleteCredentialRsp, error) {
 	rsp := new(pb.BatchDeleteCredentialRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.BatchDeleteCredentialRsp_Data{}
 	params := model.BatchDeleteCredentialParams{}
 
@@ -343,7 +344,7 @@ func (s *Service) BatchDeleteCredential(ctx context.Context, req *pb.BatchDelete
 func (s *Service) GetCredential(ctx context.Context, req *pb.GetCredentialReq) (*pb.GetCredentialRsp, error) {
 	rsp := new(pb.GetCredentialRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.GetCredentialRsp_Item{}
 	params := model.GetCredentialParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -398,7 +399,7 @@ func (s *Service) DeleteAllCredential(ctx context.Context, req *pb.DeleteAllCred
 	*pb.DeleteAllCredentialRsp, error) {
 	rsp := new(pb.DeleteAllCredentialRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.DeleteAllCredentialParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -450,7 +451,7 @@ func (s *Service) GetAllCredentialTimestamp(ctx context.Context, req *pb.GetAllC
 	*pb.GetAllCredentialTimestampRsp, error) {
 	rsp := new(pb.GetAllCredentialTimestampRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = make([]*pb.GetAllCredentialTimestampRsp_Item, 0)
 	params := model.GetAllCredentialTimestampParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -509,7 +510,7 @@ func (s *Service) GetCredentialList(ctx context.Context, req *pb.GetCredentialLi
 	*pb.GetCredentialListRsp, error) {
 	rsp := new(pb.GetCredentialListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = make([]*pb.GetCredentialListRsp_Item, 0)
 	params := model.GetCredentialListParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -563,3 +564,77 @@ func (s *Service) GetCredentialList(ctx context.Context, req *pb.GetCredentialLi
 	log.Logger.Debug("GetCredentialList success debug", log.String("trace_id", trace_id), log.Any("count", len(rsp.GetData())))
 	return rsp, nil
 }
+
+// SyncCredentials returns the credential changes after a cursor. It is served
+// in process, there is no grpc method for it.
+func (s *Service) SyncCredentials(ctx context.Context, req *model.SyncCredentialsReq) (*model.SyncCredentialsRsp, error) {
+	rsp := new(model.SyncCredentialsRsp)
+	rsp.Code = model.StatusServiceCheckErr
+	rsp.Msg = model.MsgServiceCheckErr
+	rsp.Data.Changes = make([]*model.CredentialChange, 0)
+	params := model.SyncCredentialsParams{}
+	trace_id := util.GetTraceid(ctx)
+	log.Logger.Info("SyncCredentials start", log.String("trace_id", trace_id), log.String("params", req.Params))
+	if err := jsoniter.UnmarshalFromString(req.Params, &params); err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = model.MsgParamsErr
+		log.Logger.Warn("SyncCredentials params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	if errMsg, ok := params.Check(); !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = errMsg
+		log.Logger.Warn("SyncCredentials check params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
+		return rsp, nil
+	}
+	if !util.CheckSignature(params.Address, req.Signature, req.Params) {
+		rsp.Code = model.StatusSignatureErr
+		rsp.Msg = model.MsgSignatureErr
+		log.Logger.Warn("SyncCredentials signature fail", log.String("trace_id", trace_id))
+		return rsp, nil
+	}
+	ret, err := s.dao.SyncCredentials(ctx, req.Signature, req.Params)
+	if err != nil {
+		log.Logger.Error("SyncCredentials request service error", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+
+	rsp.Code = ret.Code
+	rsp.Msg = ret.Msg
+	if ret.Code != model.StatusOK {
+		log.Logger.Warn("SyncCredentials request failed", log.String("trace_id", trace_id), log.Any("ret", ret))
+		return rsp, nil
+	}
+	if errMsg, ok := checkSyncPage(params.Cursor, ret.Data); !ok {
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgSystemErr
+		log.Logger.Error("SyncCredentials invalid index page", log.String("trace_id", trace_id), log.String("errmsg", errMsg))
+		return rsp, nil
+	}
+	rsp.Data = ret.Data
+
+	log.Logger.Info("SyncCredentials success", log.String("trace_id", trace_id), log.Any("count", len(rsp.Data.Changes)), log.Int64("cursor", rsp.Data.Cursor))
+
+	return rsp, nil
+}
+
+// checkSyncPage checks that a page of the index moves the cursor forward, so
+// a client never skips or loops over changes.
+func checkSyncPage(cursor int64, page model.SyncCredentialsData) (string, bool) {
+	last := cursor
+	for _, change := range page.Changes {
+		switch change.Op {
+		case model.SyncOpAdd, model.SyncOpUpdate, model.SyncOpDelete:
+		default:
+			return fmt.Sprintf("unknown op %q of %s", change.Op, change.Id), false
+		}
+		if change.Seq <= last {
+			return fmt.Sprintf("seq %d of %s not after %d", change.Seq, change.Id, last), false
+		}
+		last = change.Seq
+	}
+	if page.Cursor < last {
+		return fmt.Sprintf("cursor %d before the last seq %d", page.Cursor, last), false
+	}
+	return "", true
+}
@@ -13,6 +13,7 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"expvar"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -31,6 +32,14 @@ func Routers() (engine *gin.Engine) {
 	router := gin.Default()
 	router.NoRoute(Handle404)
 	runningMode := handlers.GetRunningMode()
+	// ops endpoints, behind ops.token and ahead of the middlewares, so they are neither proxied nor BSON decoded
+	ops := router.Group("/satis", middleware.OpsAuth())
+	ops.GET("/metrics", gin.WrapH(expvar.Handler()))
+	ops.GET("/outbox/status", middleware.GetOutboxStatus)
+	ops.POST("/outbox/requeue", middleware.RequeueOutbox)
+	router.Use(middleware.AccessControl(runningMode))
+	router.Use(middleware.SessionRoutes())
+	router.Use(middleware.Idempotency())
 	if consts.RunningModeOfficial != runningMode {
 		router.Use(middleware.Agent(runningMode))
 	}
@@ -55,8 +64,17 @@ func Routers() (engine *gin.Engine) {
 	user.POST("/deleteAllCredential", handlers.DeleteAllCredential)
 	user.POST("/getAllCredentialTimestamp", handlers.GetAllCredentialTimestamp)
 	user.POST("/getCredentialList", handlers.GetCredentialList)
+	user.POST("/syncCredentials", handlers.SyncCredentials)
 	user.POST("/storageStat", handlers.StorageStat)
 	user.POST("/getVersionConfig", handlers.GetVersionConfig)
+	user.POST("/session", handlers.CreateSession)
+	user.POST("/transaction", handlers.Transaction)
+	user.POST("/events", handlers.Events)
+
+	vault := router.Group("/web3password/vault")
+	vault.POST("/export", handlers.VaultExport)
+	vault.POST("/import", handlers.VaultImport)
+	vault.POST("/importStatus", handlers.VaultImportStatus)
 
 	admin := router.Group("/web3password/admin")
 	admin.POST("/authorization", handlers.Authorization)
@@ -121,13 +139,16 @@ func Handle404(c *gin.Context) {
 
 func ParamsCheck() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		nonce := uuid.NewString()
-		ctx.Set("trace_id", nonce)
+		nonce := ctx.GetString("trace_id")
+		if nonce == "" {
+			nonce = uuid.NewString()
+			ctx.Set("trace_id", nonce)
+		}
 
 		log.Logger.Info(ctx.Request.RequestURI,
 			log.String("method", ctx.Request.Method),
 			log.String("ip", ctx.ClientIP()),
-			log.Any("header", ctx.Request.Header),
+			log.Headers("header", ctx.Request.Header),
 			log.String("user-agent", ctx.Request.UserAgent()),
 			log.String("trace_id", nonce))
 
@@ -151,7 +172,7 @@ func ParamsCheck() gin.HandlerFunc {
 		}
 
 		limit := handlers.GetDefaultApiMaxSize()
-		if strings.Contains(ctx.Request.RequestURI, "file") {
+		if strings.Contains(ctx.Request.RequestURI, "file") || ctx.Request.RequestURI == "/web3password/vault/import" {
 			limit = handlers.GetFileMaxSize()
 		}
 
@@ -12,19 +12,22 @@ package service
 import (
 	"github.com/web3password/satis/config"
 	"github.com/web3password/satis/dao"
+	"github.com/web3password/satis/vault"
 	pb "github.com/web3password/w3p-protobuf/user"
 )
 
 // Service .
 type Service struct {
 	*pb.UnimplementedUserServer
-	dao dao.DAO
+	dao     dao.DAO
+	imports *vault.Imports
 }
 
 // NewService .
 func NewService(conf *config.Config) *Service {
 	service := &Service{
-		dao: dao.NewDAO(conf),
+		dao:     dao.NewDAO(conf),
+		imports: vault.NewImports(),
 	}
 
 	return service
@@ -23,7 +23,7 @@ import (
 func (s *Service) FileUpload(ctx context.Context, req *pb.FileUploadReq) (*pb.FileUploadRsp, error) {
 	rsp := new(pb.FileUploadRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.FileUploadRsp_Data{}
 	params := model.FileUploadReqParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -74,7 +74,7 @@ func (s *Service) FileUpload(ctx context.Context, req *pb.FileUploadReq) (*pb.Fi
 func (s *Service) FileDownload(ctx context.Context, req *pb.FileDownloadReq) (*pb.FileDownloadRsp, error) {
 	rsp := new(pb.FileDownloadRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.FileDownloadRsp_Data{}
 	params := model.FileDownloadReqParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -130,7 +130,7 @@ func (s *Service) FileDownload(ctx context.Context, req *pb.FileDownloadReq) (*p
 func (s *Service) FileAttachment(ctx context.Context, req *pb.FileAttachmentReq) (*pb.FileAttachmentRsp, error) {
 	rsp := new(pb.FileAttachmentRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.FileAttachmentRsp_Data{}
 	params := model.FileAttachmentReqParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -188,7 +188,7 @@ func (s *Service) FileAttachment(ctx context.Context, req *pb.FileAttachmentReq)
 func (s *Service) FileReport(ctx context.Context, req *pb.FileReportReq) (*pb.FileReportRsp, error) {
 	rsp := new(pb.FileReportRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.EmptyData{}
 	params := model.FileReportReqParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -17,3 +17,13 @@ import (
 func (s *Service) Stream(server pb.User_StreamServer) error {
 	return s.dao.Stream(server)
 }
+
+// Nodes returns the ids of the nodes connected in a group.
+func (s *Service) Nodes(group string) []string {
+	return s.dao.Nodes(group)
+}
+
+// QueueDepth returns the number of requests waiting to be sent to a node.
+func (s *Service) QueueDepth(nodeID string) int {
+	return s.dao.QueueDepth(nodeID)
+}
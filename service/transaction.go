@@ -0,0 +1,151 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/session"
+	"github.com/web3password/satis/signature"
+	"github.com/web3password/satis/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type txParams interface {
+	Check(data []byte) (string, bool)
+}
+
+// txRoutes are the routes a transaction op may be, with the index command
+// they run and the params they are checked as.
+var txRoutes = map[string]struct {
+	cmd    string
+	params func() txParams
+}{
+	"/web3password/addCredential":            {model.CMDIndexAddOrDelCredential, func() txParams { return &model.AddCredentialParams{} }},
+	"/web3password/deleteCredential":         {model.CMDIndexAddOrDelCredential, func() txParams { return &model.DeleteCredentialParams{} }},
+	"/web3password/batchAddCredential":       {model.CMDIndexBatchAddCredential, func() txParams { return &model.BatchAddCredentialParams{} }},
+	"/web3password/batchDeleteCredential":    {model.CMDIndexBatchDeleteCredential, func() txParams { return &model.BatchDeleteCredentialParams{} }},
+	"/web3password/sharefolder/addrecord":    {model.CMDShareFolderAddRecord, func() txParams { return &model.ShareFolderAddRecordParams{} }},
+	"/web3password/sharefolder/deleterecord": {model.CMDShareFolderDeleteRecord, func() txParams { return &model.ShareFolderDeleteRecordParams{} }},
+}
+
+// Transaction checks every op as its route would, then sends them to the
+// index as one command applied all or none. On a timeout the outcome is
+// unknown, the index may still commit.
+func (s *Service) Transaction(ctx context.Context, req *model.TransactionReq) (*model.TransactionRsp, error) {
+	rsp := new(model.TransactionRsp)
+	rsp.Code = model.StatusServiceCheckErr
+	rsp.Msg = model.MsgServiceCheckErr
+	rsp.Data.FailedOp = -1
+	rsp.Data.Results = make([]*model.TxResult, 0)
+	params := model.TransactionParams{}
+	trace_id := util.GetTraceid(ctx)
+	log.Logger.Debug("Transaction start", log.String("trace_id", trace_id), log.String("params", req.Params))
+	if err := jsoniter.UnmarshalFromString(req.Params, &params); err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = model.MsgParamsErr
+		log.Logger.Warn("Transaction params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	if errMsg, ok := params.Check(req.Data); !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = errMsg
+		log.Logger.Warn("Transaction check params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
+		return rsp, nil
+	}
+	if !util.CheckSignature(params.Address, req.Signature, req.Params) {
+		rsp.Code = model.StatusSignatureErr
+		rsp.Msg = model.MsgSignatureErr
+		log.Logger.Warn("Transaction signature fail", log.String("trace_id", trace_id))
+		return rsp, nil
+	}
+
+	data := model.TransactionData{}
+	if err := bson.Unmarshal(req.Data, &data); err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = "invalid data: " + err.Error()
+		log.Logger.Warn("Transaction data parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	if errMsg, ok := data.Check(); !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = errMsg
+		log.Logger.Warn("Transaction check data fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
+		return rsp, nil
+	}
+	commands := model.TxCommands{Ops: make([]*model.TxCommand, 0, len(data.Ops))}
+	for i, op := range data.Ops {
+		cmd, code, errMsg := checkTxOp(op, params.Address)
+		if code != model.StatusOK {
+			rsp.Code = int32(code)
+			rsp.Msg = fmt.Sprintf("op %d: %s", i, errMsg)
+			rsp.Data.FailedOp = int32(i)
+			log.Logger.Warn("Transaction check op fail", log.String("trace_id", trace_id), log.Any("op", i), log.String("route", op.Route), log.String("errmsg", errMsg))
+			return rsp, nil
+		}
+		commands.Ops = append(commands.Ops, &model.TxCommand{Cmd: cmd, Signature: op.Signature, Params: op.Params, Data: op.Data})
+	}
+	b, err := bson.Marshal(commands)
+	if err != nil {
+		log.Logger.Error("Transaction marshal commands error", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+
+	ret, err := s.dao.Transaction(ctx, req.Signature, req.Params, b)
+	if err != nil {
+		log.Logger.Error("Transaction request service error", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	rsp.Code = ret.Code
+	rsp.Msg = ret.Msg
+	rsp.Data = ret.Data
+	if ret.Code != model.StatusOK {
+		log.Logger.Warn("Transaction rolled back", log.String("trace_id", trace_id), log.Any("failed_op", ret.Data.FailedOp), log.Any("ret.code", ret.Code))
+		return rsp, nil
+	}
+	log.Logger.Info("Transaction committed", log.String("trace_id", trace_id), log.Any("ops", len(commands.Ops)))
+	return rsp, nil
+}
+
+// checkTxOp checks an op of the transaction of addr, it returns the command of
+// the op, or the status and message of why it is refused.
+func checkTxOp(op *model.TxOp, addr string) (string, int, string) {
+	route, ok := txRoutes[op.Route]
+	if !ok {
+		return "", model.StatusParamsErr, fmt.Sprintf("invalid route: %q", op.Route)
+	}
+	params := route.params()
+	if err := jsoniter.UnmarshalFromString(op.Params, params); err != nil {
+		return "", model.StatusParamsErr, model.MsgParamsErr
+	}
+	if errMsg, ok := params.Check(op.Data); !ok {
+		return "", model.StatusParamsErr, errMsg
+	}
+	var signer struct {
+		Addr string `json:"addr"`
+	}
+	_ = jsoniter.UnmarshalFromString(op.Params, &signer)
+	if !strings.EqualFold(signer.Addr, addr) {
+		return "", model.StatusParamsErr, "addr is not the addr of the transaction"
+	}
+	// the ops are not seen by the middlewares, refuse the session tokens here
+	if signature.TypeOf(op.Params) == session.Type && !session.Accepts(op.Route) {
+		return "", model.StatusSignatureErr, "session token not accepted for " + op.Route
+	}
+	if !util.CheckSignature(signer.Addr, op.Signature, op.Params) {
+		return "", model.StatusSignatureErr, model.MsgSignatureErr
+	}
+	return route.cmd, model.StatusOK, ""
+}
@@ -386,10 +386,10 @@ func (s *Service) GetVersionDesc(ctx context.Context, req *pb.GetVersionDescReq)
 		log.Logger.Warn("StorageStat parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
 		return rsp, nil
 	}
-	if !params.Check() {
+	if errMsg, ok := params.Check(); !ok {
 		rsp.Code = model.StatusParamsErr
-		rsp.Msg = model.MsgParamsErr
-		log.Logger.Warn("GetVersionDesc params fail", log.String("trace_id", trace_id))
+		rsp.Msg = errMsg
+		log.Logger.Warn("GetVersionDesc params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
 		return rsp, nil
 	}
 
@@ -494,10 +494,10 @@ func (s *Service) AdminTransferSuperAdmin(ctx context.Context, req *pb.AdminTran
 		log.Logger.Warn("AdminTransferSuperAdmin parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
 		return rsp, nil
 	}
-	if !params.Check() {
+	if errMsg, ok := params.Check(); !ok {
 		rsp.Code = model.StatusParamsErr
-		rsp.Msg = model.MsgParamsErr
-		log.Logger.Warn("AdminTransferSuperAdmin params fail", log.String("trace_id", trace_id))
+		rsp.Msg = errMsg
+		log.Logger.Warn("AdminTransferSuperAdmin params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
 		return rsp, nil
 	}
 	if !util.CheckSignature(params.Address, req.GetSignature(), req.GetParams()) {
@@ -544,16 +544,10 @@ func (s *Service) AdminOperationHistory(ctx context.Context, req *pb.AdminOperat
 		log.Logger.Warn("AdminOperationHistory parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
 		return rsp, nil
 	}
-	if !params.Check() {
+	if errMsg, ok := params.Check(); !ok {
 		rsp.Code = model.StatusParamsErr
-		rsp.Msg = model.MsgParamsErr
-		log.Logger.Warn("AdminOperationHistory params fail", log.String("trace_id", trace_id))
-		return rsp, nil
-	}
-	if !util.CheckTimestamp(params.Timestamp) {
-		rsp.Code = model.StatusTimestampErr
-		rsp.Msg = model.MsgTimestamp
-		log.Logger.Warn("AdminOperationHistory timestamp fail", log.String("trace_id", trace_id))
+		rsp.Msg = errMsg
+		log.Logger.Warn("AdminOperationHistory params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
 		return rsp, nil
 	}
 	if !util.CheckSignature(params.Address, req.GetSignature(), req.GetParams()) {
@@ -601,16 +595,10 @@ func (s *Service) AdminGetOrgInfo(ctx context.Context, req *pb.AdminGetOrgInfoRe
 		return rsp, nil
 	}
 
-	if !params.Check() {
+	if errMsg, ok := params.Check(model.AdminGetOrgInfoToken); !ok {
 		rsp.Code = model.StatusParamsErr
-		rsp.Msg = model.MsgParamsErr
-		log.Logger.Warn("AdminGetOrgInfo params fail", log.String("trace_id", trace_id))
-		return rsp, nil
-	}
-	if !util.CheckTimestamp(params.Timestamp) {
-		rsp.Code = model.StatusTimestampErr
-		rsp.Msg = model.MsgTimestamp
-		log.Logger.Warn("AdminGetOrgInfo timestamp fail", log.String("trace_id", trace_id))
+		rsp.Msg = errMsg
+		log.Logger.Warn("AdminGetOrgInfo params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
 		return rsp, nil
 	}
 	if !util.CheckSignature(params.Address, req.GetSignature(), req.GetParams()) {
@@ -647,7 +635,7 @@ func (s *Service) AdminGetOrgInfo(ctx context.Context, req *pb.AdminGetOrgInfoRe
 func (s *Service) GetVersionConfig(ctx context.Context, req *pb.GetVersionConfigReq) (*pb.GetVersionConfigRsp, error) {
 	rsp := new(pb.GetVersionConfigRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 	rsp.Data = &pb.GetVersionConfigRsp_Data{}
 	params := model.GetVersionConfigParams{}
 	trace_id := util.GetTraceid(ctx)
@@ -660,7 +648,7 @@ func (s *Service) GetVersionConfig(ctx context.Context, req *pb.GetVersionConfig
 		log.Logger.Warn("GetVersionConfig params parse fail", log.String("trace_id", trace_id))
 		return rsp, nil
 	}
-	if errMsg, ok := params.Check(model.GetVersionConfigToken, params.Timestamp, req.GetData()); !ok {
+	if errMsg, ok := params.Check(model.GetVersionConfigToken, req.GetData()); !ok {
 		rsp.Code = model.StatusParamsErr
 		rsp.Msg = errMsg
 		log.Logger.Warn("GetVersionConfig params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
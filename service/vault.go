@@ -0,0 +1,316 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/util"
+	"github.com/web3password/satis/vault"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// vaultExportMargin keeps the last page asked clear of the timestamp window of the nodes.
+const vaultExportMargin = 2
+
+// vaultExports are the export commands, in the order of the archive.
+var vaultExports = []struct {
+	kind  string
+	cmd   string
+	group string
+}{
+	{vault.KindCredential, model.CMDIndexVaultExportCredentials, model.INDEX_PROXY},
+	{vault.KindRecord, model.CMDIndexVaultExportRecords, model.INDEX_PROXY},
+	{vault.KindAttachment, model.CMDStorageVaultExportAttachments, model.STORAGE_PROXY},
+}
+
+// VaultExport writes the vault archive of the params address to w, page by
+// page. Nothing is written when it fails before the first item, after that
+// the archive is cut short and has no manifest. Every page carries the signed
+// params, so a vault too large to export within util.W3PTimeout of their
+// timestamp ends with a manifest resume, continued by another signed export.
+func (s *Service) VaultExport(ctx context.Context, req *model.VaultReq, w io.Writer) (*model.ResponseBytes, error) {
+	rsp := new(model.ResponseBytes)
+	rsp.Code = model.StatusServiceCheckErr
+	rsp.Msg = model.MsgServiceCheckErr
+	params := model.VaultExportParams{}
+	trace_id := util.GetTraceid(ctx)
+	log.Logger.Info("VaultExport start", log.String("trace_id", trace_id), log.String("params", req.Params))
+	if err := jsoniter.UnmarshalFromString(req.Params, &params); err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = model.MsgParamsErr
+		log.Logger.Warn("VaultExport params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	if errMsg, ok := params.Check(); !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = errMsg
+		log.Logger.Warn("VaultExport check params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
+		return rsp, nil
+	}
+	if !util.CheckSignature(params.Address, req.Signature, req.Params) {
+		rsp.Code = model.StatusSignatureErr
+		rsp.Msg = model.MsgSignatureErr
+		log.Logger.Warn("VaultExport signature fail", log.String("trace_id", trace_id))
+		return rsp, nil
+	}
+
+	// the nodes check the timestamp of every page, the archive stops before it
+	// leaves their window and its manifest tells where to resume
+	deadline := params.Timestamp + util.W3PTimeout - vaultExportMargin
+	resuming := params.ResumeKind != ""
+	written := false
+	archive := vault.NewWriter(w, params.Address, params.OrgId)
+exports:
+	for _, export := range vaultExports {
+		cursor := ""
+		if resuming {
+			if export.kind != params.ResumeKind {
+				continue
+			}
+			resuming, cursor = false, params.ResumeCursor
+		}
+		for {
+			if written && time.Now().Unix() >= deadline {
+				archive.Stop(export.kind, cursor)
+				log.Logger.Info("VaultExport stopped before the signature expires", log.String("trace_id", trace_id), log.String("kind", export.kind), log.String("cursor", cursor))
+				break exports
+			}
+			ret, err := s.dao.VaultExport(ctx, export.cmd, export.group, req.Signature, req.Params, cursor)
+			if err != nil {
+				rsp.Code = model.StatusSystemError
+				rsp.Msg = model.MsgSystemErr
+				log.Logger.Error("VaultExport request service error", log.String("trace_id", trace_id), log.String("kind", export.kind), log.String("errmsg", err.Error()))
+				return rsp, nil
+			}
+			if ret.Code == model.StatusTimestampErr && written {
+				archive.Stop(export.kind, cursor)
+				log.Logger.Info("VaultExport stopped on an expired signature", log.String("trace_id", trace_id), log.String("kind", export.kind), log.String("cursor", cursor))
+				break exports
+			}
+			if ret.Code != model.StatusOK {
+				rsp.Code = ret.Code
+				rsp.Msg = ret.Msg
+				log.Logger.Warn("VaultExport request failed", log.String("trace_id", trace_id), log.String("kind", export.kind), log.Any("ret.code", ret.Code))
+				return rsp, nil
+			}
+			for _, item := range ret.Data.Items {
+				entry := &vault.Entry{
+					Kind:        export.kind,
+					Id:          item.Id,
+					FolderId:    item.FolderId,
+					OpTimestamp: item.OpTimestamp,
+					Size:        item.Size,
+					SHA256:      item.SHA256,
+				}
+				if err := archive.Add(entry, item.Data); err != nil {
+					rsp.Code = model.StatusSystemError
+					rsp.Msg = model.MsgSystemErr
+					log.Logger.Error("VaultExport write archive fail", log.String("trace_id", trace_id), log.String("kind", export.kind), log.String("errmsg", err.Error()))
+					return rsp, nil
+				}
+				written = true
+			}
+			if ret.Data.NextCursor == "" {
+				break
+			}
+			if ret.Data.NextCursor == cursor {
+				rsp.Code = model.StatusSystemError
+				rsp.Msg = model.MsgSystemErr
+				log.Logger.Error("VaultExport cursor does not advance", log.String("trace_id", trace_id), log.String("kind", export.kind), log.String("cursor", cursor))
+				return rsp, nil
+			}
+			cursor = ret.Data.NextCursor
+		}
+	}
+	if err := archive.Close(); err != nil {
+		rsp.Code = model.StatusSystemError
+		rsp.Msg = model.MsgSystemErr
+		log.Logger.Error("VaultExport write manifest fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	rsp.Code = model.StatusOK
+	rsp.Msg = model.MsgOK
+	log.Logger.Info("VaultExport end", log.String("trace_id", trace_id))
+	return rsp, nil
+}
+
+// VaultImport checks an archive and replays it in the background, in batches
+// of the vault import commands of the index. The progress is read with
+// VaultImportStatus, from this instance only.
+func (s *Service) VaultImport(ctx context.Context, req *model.VaultReq) (*model.VaultImportRsp, error) {
+	rsp := new(model.VaultImportRsp)
+	rsp.Code = model.StatusServiceCheckErr
+	rsp.Msg = model.MsgServiceCheckErr
+	params := model.VaultImportParams{}
+	trace_id := util.GetTraceid(ctx)
+	log.Logger.Info("VaultImport start", log.String("trace_id", trace_id), log.String("params", req.Params), log.Any("size", len(req.Data)))
+	if err := jsoniter.UnmarshalFromString(req.Params, &params); err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = model.MsgParamsErr
+		log.Logger.Warn("VaultImport params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	if errMsg, ok := params.Check(req.Data); !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = errMsg
+		log.Logger.Warn("VaultImport check params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
+		return rsp, nil
+	}
+	if !util.CheckSignature(params.Address, req.Signature, req.Params) {
+		rsp.Code = model.StatusSignatureErr
+		rsp.Msg = model.MsgSignatureErr
+		log.Logger.Warn("VaultImport signature fail", log.String("trace_id", trace_id))
+		return rsp, nil
+	}
+	archive, err := vault.Read(req.Data)
+	if err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = err.Error()
+		log.Logger.Warn("VaultImport read archive fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	if !strings.EqualFold(archive.Manifest.Addr, params.Address) {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = "the archive is of another addr"
+		log.Logger.Warn("VaultImport archive of another addr", log.String("trace_id", trace_id), log.String("archive.addr", archive.Manifest.Addr))
+		return rsp, nil
+	}
+
+	total := archive.Manifest.Count(vault.KindCredential) + archive.Manifest.Count(vault.KindRecord)
+	progress := s.imports.Start(params.Address, int64(total))
+	gtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("trace_id", trace_id))
+	go s.replayVault(gtx, req, params.Hash, archive, progress.JobId)
+
+	rsp.Code = model.StatusOK
+	rsp.Msg = model.MsgOK
+	rsp.Data = progress
+	log.Logger.Info("VaultImport accepted", log.String("trace_id", trace_id), log.String("job_id", progress.JobId), log.Int64("total", progress.Total))
+	return rsp, nil
+}
+
+// vaultImports are the import commands, in the order of the replay.
+var vaultImports = []struct {
+	kind string
+	cmd  string
+}{
+	{vault.KindCredential, model.CMDIndexVaultImportCredentials},
+	{vault.KindRecord, model.CMDIndexVaultImportRecords},
+}
+
+// replayVault sends the credentials, then the records, in batches carrying the
+// signed import params and the archive hash. Items the nodes refuse are
+// counted as failed, a node error stops the import.
+func (s *Service) replayVault(ctx context.Context, req *model.VaultReq, hash string, archive *vault.Archive, jobId string) {
+	trace_id := util.GetTraceid(ctx)
+	for _, replay := range vaultImports {
+		batch := make([]*model.VaultImportItem, 0)
+		batchSize := 0
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			n := int64(len(batch))
+			data, err := bson.Marshal(model.VaultImportBatch{JobId: jobId, ArchiveHash: hash, Items: batch})
+			batch, batchSize = batch[:0], 0
+			if err != nil {
+				return err
+			}
+			ret, err := s.dao.VaultImport(ctx, replay.cmd, req.Signature, req.Params, data)
+			if err != nil {
+				s.imports.Advance(jobId, 0, n)
+				return err
+			}
+			if ret.Code != model.StatusOK {
+				log.Logger.Warn("VaultImport batch failed", log.String("trace_id", trace_id), log.String("job_id", jobId), log.String("kind", replay.kind), log.Any("ret.code", ret.Code), log.String("ret.msg", ret.Msg))
+				s.imports.Advance(jobId, 0, n)
+				return nil
+			}
+			failed := ret.Data.Failed
+			if failed < 0 || failed > n {
+				failed = n
+			}
+			s.imports.Advance(jobId, n-failed, failed)
+			return nil
+		}
+
+		for _, e := range archive.Manifest.Entries {
+			if e.Kind != replay.kind {
+				continue
+			}
+			blob := archive.Blob(e)
+			if len(batch) == util.W3PMaxBatchRecordNumber || batchSize+len(blob) > util.W3PMaxBodyLength {
+				if err := flush(); err != nil {
+					s.failVaultImport(trace_id, jobId, err)
+					return
+				}
+			}
+			batch = append(batch, &model.VaultImportItem{Id: e.Id, FolderId: e.FolderId, OpTimestamp: e.OpTimestamp, Data: blob, SHA256: e.SHA256})
+			batchSize += len(blob)
+		}
+		if err := flush(); err != nil {
+			s.failVaultImport(trace_id, jobId, err)
+			return
+		}
+	}
+	s.imports.Finish(jobId, "")
+	log.Logger.Info("VaultImport end", log.String("trace_id", trace_id), log.String("job_id", jobId))
+}
+
+func (s *Service) failVaultImport(trace_id, jobId string, err error) {
+	log.Logger.Error("VaultImport replay error", log.String("trace_id", trace_id), log.String("job_id", jobId), log.String("errmsg", err.Error()))
+	s.imports.Finish(jobId, err.Error())
+}
+
+// VaultImportStatus is the progress of an import of the params address. The
+// progress is kept by the instance that accepted the import, see vault.Imports.
+func (s *Service) VaultImportStatus(ctx context.Context, req *model.VaultReq) (*model.VaultImportRsp, error) {
+	rsp := new(model.VaultImportRsp)
+	rsp.Code = model.StatusServiceCheckErr
+	rsp.Msg = model.MsgServiceCheckErr
+	params := model.VaultImportStatusParams{}
+	trace_id := util.GetTraceid(ctx)
+	if err := jsoniter.UnmarshalFromString(req.Params, &params); err != nil {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = model.MsgParamsErr
+		log.Logger.Warn("VaultImportStatus params parse fail", log.String("trace_id", trace_id), log.String("errmsg", err.Error()))
+		return rsp, nil
+	}
+	if errMsg, ok := params.Check(); !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = errMsg
+		log.Logger.Warn("VaultImportStatus check params fail", log.String("trace_id", trace_id), log.Any("errMsg", errMsg))
+		return rsp, nil
+	}
+	if !util.CheckSignature(params.Address, req.Signature, req.Params) {
+		rsp.Code = model.StatusSignatureErr
+		rsp.Msg = model.MsgSignatureErr
+		log.Logger.Warn("VaultImportStatus signature fail", log.String("trace_id", trace_id))
+		return rsp, nil
+	}
+	progress, ok := s.imports.Get(params.Address, params.JobId)
+	if !ok {
+		rsp.Code = model.StatusParamsErr
+		rsp.Msg = "unknown job_id, the status is kept by the instance that accepted the import"
+		log.Logger.Warn("VaultImportStatus unknown job", log.String("trace_id", trace_id), log.String("job_id", params.JobId))
+		return rsp, nil
+	}
+	rsp.Code = model.StatusOK
+	rsp.Msg = model.MsgOK
+	rsp.Data = progress
+	return rsp, nil
+}
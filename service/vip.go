@@ -22,7 +22,7 @@ import (
 func (s *Service) VipGetConfig(ctx context.Context, req *pb.VipGetConfigReq) (*pb.VipGetConfigRsp, error) {
 	rsp := new(pb.VipGetConfigRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipGetConfigParams{}
 	traceId := util.GetTraceid(ctx)
@@ -69,7 +69,7 @@ func (s *Service) VipGetConfig(ctx context.Context, req *pb.VipGetConfigReq) (*p
 func (s *Service) VipSubscriptionList(ctx context.Context, req *pb.VipSubscriptionListReq) (*pb.VipSubscriptionListRsp, error) {
 	rsp := new(pb.VipSubscriptionListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipSubscriptionListParams{}
 	traceId := util.GetTraceid(ctx)
@@ -124,7 +124,7 @@ func (s *Service) VipSubscriptionList(ctx context.Context, req *pb.VipSubscripti
 func (s *Service) VipPaymentList(ctx context.Context, req *pb.VipPaymentListReq) (*pb.VipPaymentListRsp, error) {
 	rsp := new(pb.VipPaymentListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipPaymentListParams{}
 	traceId := util.GetTraceid(ctx)
@@ -179,7 +179,7 @@ func (s *Service) VipPaymentList(ctx context.Context, req *pb.VipPaymentListReq)
 func (s *Service) VipCreateOrder(ctx context.Context, req *pb.VipCreateOrderReq) (*pb.VipCreateOrderRsp, error) {
 	rsp := new(pb.VipCreateOrderRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipCreateOrderParams{}
 	traceId := util.GetTraceid(ctx)
@@ -233,7 +233,7 @@ func (s *Service) VipCreateOrder(ctx context.Context, req *pb.VipCreateOrderReq)
 func (s *Service) VipCheckOrder(ctx context.Context, req *pb.VipCheckOrderReq) (*pb.VipCheckOrderRsp, error) {
 	rsp := new(pb.VipCheckOrderRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipCheckOrderParams{}
 	traceId := util.GetTraceid(ctx)
@@ -287,7 +287,7 @@ func (s *Service) VipCheckOrder(ctx context.Context, req *pb.VipCheckOrderReq) (
 func (s *Service) VipAppleVerifyReceipt(ctx context.Context, req *pb.VipAppleVerifyReceiptReq) (*pb.VipAppleVerifyReceiptRsp, error) {
 	rsp := new(pb.VipAppleVerifyReceiptRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipAppleVerifyReceiptParams{}
 	traceId := util.GetTraceid(ctx)
@@ -341,7 +341,7 @@ func (s *Service) VipAppleVerifyReceipt(ctx context.Context, req *pb.VipAppleVer
 func (s *Service) GetDiscountCodeInfo(ctx context.Context, req *pb.GetDiscountCodeInfoReq) (*pb.GetDiscountCodeInfoRsp, error) {
 	rsp := new(pb.GetDiscountCodeInfoRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.GetDiscountCodeInfoParams{}
 	traceId := util.GetTraceid(ctx)
@@ -387,7 +387,7 @@ func (s *Service) GetDiscountCodeInfo(ctx context.Context, req *pb.GetDiscountCo
 func (s *Service) GetOrderList(ctx context.Context, req *pb.GetOrderListReq) (*pb.GetOrderListRsp, error) {
 	rsp := new(pb.GetOrderListRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.GetOrderListParams{}
 	traceId := util.GetTraceid(ctx)
@@ -433,7 +433,7 @@ func (s *Service) GetOrderList(ctx context.Context, req *pb.GetOrderListReq) (*p
 func (s *Service) VipIOSPromotionSign(ctx context.Context, req *pb.GetVipIOSPromotionSignReq) (*pb.GetVipIOSPromotionSignRsp, error) {
 	rsp := new(pb.GetVipIOSPromotionSignRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipIOSPromotionSignParams{}
 	traceId := util.GetTraceid(ctx)
@@ -481,7 +481,7 @@ func (s *Service) VipIOSPromotionSign(ctx context.Context, req *pb.GetVipIOSProm
 func (s *Service) VipPrice(ctx context.Context, req *pb.VipPriceReq) (*pb.VipPriceRsp, error) {
 	rsp := new(pb.VipPriceRsp)
 	rsp.Code = model.StatusServiceCheckErr
-	rsp.Msg = "system error"
+	rsp.Msg = model.MsgServiceCheckErr
 
 	params := model.VipCreateOrderParams{}
 	traceId := util.GetTraceid(ctx)
@@ -0,0 +1,193 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package session issues the short-lived tokens a personal_auth is exchanged
+// for. A token signs the read-only requests of its address with sig_type
+// session, instead of a signature per request.
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/config"
+	"github.com/web3password/satis/model"
+	"github.com/web3password/satis/signature"
+)
+
+// Type is the sig_type of the requests signed with a session token.
+const Type = "session"
+
+const tokenPrefix = "w3ps."
+
+// ReadOnlyRoutes are the routes a session token is accepted on, with the
+// route token their params carry.
+var ReadOnlyRoutes = map[string]string{
+	"/web3password/getCredentialList":      model.GetCredentialListToken,
+	"/web3password/sharefolder/folderlist": model.ShareFolderFolderListToken,
+	"/web3password/storageStat":            model.StorageStatToken,
+}
+
+var (
+	ErrDisabled = errors.New("session tokens are disabled")
+	ErrExpired  = errors.New("session token expired")
+	ErrRevoked  = errors.New("session token revoked")
+	ErrInvalid  = errors.New("invalid session token")
+)
+
+var (
+	randomSecretOnce sync.Once
+	randomSecret     []byte
+)
+
+// Claims are the content of a token.
+type Claims struct {
+	Address  string `json:"addr"`
+	IssuedAt int64  `json:"iat"`
+	Expire   int64  `json:"exp"`
+	ID       string `json:"jti"`
+}
+
+func init() {
+	signature.Register(Type, scheme{})
+}
+
+// Issue signs a token for addr, valid for session.ttl and never past authExpire.
+func Issue(addr string, authExpire int64) (string, Claims, error) {
+	conf := config.GetConfig().Session
+	if !conf.Enable {
+		return "", Claims{}, ErrDisabled
+	}
+	now := time.Now().Unix()
+	claims := Claims{Address: addr, IssuedAt: now, Expire: now + int64(conf.TTL), ID: uuid.NewString()}
+	if authExpire < claims.Expire {
+		claims.Expire = authExpire
+	}
+	if claims.Expire <= now {
+		return "", Claims{}, errors.New("personal_auth expired")
+	}
+	payload, err := jsoniter.Marshal(claims)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return tokenPrefix + encoded + "." + sign(conf, encoded), claims, nil
+}
+
+// Parse verifies a token and returns its claims.
+func Parse(token string) (Claims, error) {
+	var claims Claims
+	conf := config.GetConfig().Session
+	if !conf.Enable {
+		return claims, ErrDisabled
+	}
+	encoded, mac, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), ".")
+	if !ok || !strings.HasPrefix(token, tokenPrefix) {
+		return claims, ErrInvalid
+	}
+	if !hmac.Equal([]byte(mac), []byte(sign(conf, encoded))) {
+		return claims, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return claims, ErrInvalid
+	}
+	if err := jsoniter.Unmarshal(payload, &claims); err != nil {
+		return claims, ErrInvalid
+	}
+	if time.Now().Unix() >= claims.Expire {
+		return claims, ErrExpired
+	}
+	if claims.IssuedAt < conf.RevokeBefore {
+		return claims, ErrRevoked
+	}
+	for _, addr := range conf.RevokedAddrs {
+		if strings.EqualFold(addr, claims.Address) {
+			return claims, ErrRevoked
+		}
+	}
+	return claims, nil
+}
+
+func sign(conf config.Session, encoded string) string {
+	h := hmac.New(sha256.New, secret(conf))
+	h.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// secret is the configured one or a random one for the process, the tokens
+// of a process without a secret do not survive a restart.
+func secret(conf config.Session) []byte {
+	if conf.Secret != "" {
+		return []byte(conf.Secret)
+	}
+	randomSecretOnce.Do(func() {
+		randomSecret = make([]byte, 32)
+		if _, err := rand.Read(randomSecret); err != nil {
+			panic(fmt.Sprintf("session: random secret: %v", err))
+		}
+	})
+	return randomSecret
+}
+
+// Accepts reports whether a session token may sign a request to route.
+func Accepts(route string) bool {
+	_, ok := ReadOnlyRoutes[route]
+	return ok
+}
+
+// scheme verifies requests signed with a token. The address is the one of
+// another scheme, the token must have been issued for it and the route token
+// of the params must be read-only. The scheme does not know the route, the
+// callers check it with Accepts, and the params of each route check their
+// own route token.
+type scheme struct{}
+
+func (scheme) ValidAddress(addr string) bool {
+	for _, name := range signature.Names() {
+		if name == Type {
+			continue
+		}
+		if s, ok := signature.Lookup(name); ok && s.ValidAddress(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (scheme) Address([]byte) (string, error) {
+	return "", errors.New("session tokens have no public key")
+}
+
+func (scheme) Verify(addr, sign string, params []byte) error {
+	claims, err := Parse(sign)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(claims.Address, addr) {
+		return fmt.Errorf("session token of %s used by %s", claims.Address, addr)
+	}
+	token := jsoniter.Get(params, "token").ToString()
+	for _, t := range ReadOnlyRoutes {
+		if token == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("session token not accepted for %s", token)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package signature
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const ed25519Prefix = "ed25519:"
+
+// ed25519Scheme is for hardware keys, the address is ed25519:<hex public key>
+// and the signature the hex signature of the params.
+type ed25519Scheme struct{}
+
+func (ed25519Scheme) ValidAddress(addr string) bool {
+	_, err := ed25519PublicKey(addr)
+	return err == nil
+}
+
+func (ed25519Scheme) Address(publicKey []byte) (string, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("invalid ed25519 public key length: %d", len(publicKey))
+	}
+	return ed25519Prefix + hex.EncodeToString(publicKey), nil
+}
+
+func (ed25519Scheme) Verify(addr, sign string, params []byte) error {
+	pub, err := ed25519PublicKey(addr)
+	if err != nil {
+		return err
+	}
+	sig, err := decodeHex(sign)
+	if err != nil {
+		return err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	if !ed25519.Verify(pub, params, sig) {
+		return errors.New("ed25519 signature mismatch")
+	}
+	return nil
+}
+
+func ed25519PublicKey(addr string) (ed25519.PublicKey, error) {
+	if !strings.HasPrefix(addr, ed25519Prefix) {
+		return nil, errors.New("not an ed25519 address")
+	}
+	pub, err := hex.DecodeString(strings.TrimPrefix(addr, ed25519Prefix))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 address")
+	}
+	return pub, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package signature
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/web3password/jewel/tools"
+)
+
+// EIP-712 domain of the typed data signed by eip712 clients, the message is
+// Request(string params) with the json params.
+const (
+	eip712DomainName    = "Web3Password"
+	eip712DomainVersion = "1"
+)
+
+var (
+	eip712DomainType  = crypto.Keccak256([]byte("EIP712Domain(string name,string version)"))
+	eip712RequestType = crypto.Keccak256([]byte("Request(string params)"))
+)
+
+// secp256k1Scheme is the personal sign of the wallets, 0x addresses.
+type secp256k1Scheme struct{}
+
+func (secp256k1Scheme) ValidAddress(addr string) bool {
+	return tools.IsValidAddress(addr)
+}
+
+func (secp256k1Scheme) Address(publicKey []byte) (string, error) {
+	return ethAddress(publicKey)
+}
+
+func (secp256k1Scheme) Verify(addr, sign string, params []byte) error {
+	return tools.BizVerifySignature(sign, params, addr)
+}
+
+// eip712Scheme signs the params as typed data, so wallets show them to the user.
+type eip712Scheme struct{}
+
+func (eip712Scheme) ValidAddress(addr string) bool {
+	return tools.IsValidAddress(addr)
+}
+
+func (eip712Scheme) Address(publicKey []byte) (string, error) {
+	return ethAddress(publicKey)
+}
+
+func (eip712Scheme) Verify(addr, sign string, params []byte) error {
+	sig, err := decodeHex(sign)
+	if err != nil {
+		return err
+	}
+	if len(sig) != 65 {
+		return fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(eip712Hash(params), sig)
+	if err != nil {
+		return err
+	}
+	if signer := crypto.PubkeyToAddress(*pub).Hex(); !strings.EqualFold(signer, addr) {
+		return fmt.Errorf("signer %s is not %s", signer, addr)
+	}
+	return nil
+}
+
+// eip712Hash is the hash signed by eth_signTypedData_v4 for the params.
+func eip712Hash(params []byte) []byte {
+	domain := crypto.Keccak256(eip712DomainType,
+		crypto.Keccak256([]byte(eip712DomainName)),
+		crypto.Keccak256([]byte(eip712DomainVersion)))
+	message := crypto.Keccak256(eip712RequestType, crypto.Keccak256(params))
+	return crypto.Keccak256([]byte{0x19, 0x01}, domain, message)
+}
+
+// ethAddress derives the 0x address of a compressed or uncompressed public key.
+func ethAddress(publicKey []byte) (string, error) {
+	if len(publicKey) == 33 {
+		pub, err := crypto.DecompressPubkey(publicKey)
+		if err != nil {
+			return "", err
+		}
+		return crypto.PubkeyToAddress(*pub).Hex(), nil
+	}
+	pub, err := crypto.UnmarshalPubkey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(*pub).Hex(), nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, errors.New("signature is not hex")
+	}
+	return b, nil
+}
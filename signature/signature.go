@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package signature verifies request signatures with the scheme named by the
+// sig_type param. Every scheme has its own address format, so an address
+// tells which scheme can sign for it.
+package signature
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	TypeSecp256k1 = "secp256k1" // personal sign of the keccak256 of the params
+	TypeEIP712    = "eip712"
+	TypeEd25519   = "ed25519"
+	TypeWebAuthn  = "webauthn"
+
+	// DefaultType is used when the params have no sig_type.
+	DefaultType = TypeSecp256k1
+
+	typeParam = "sig_type"
+)
+
+// Scheme verifies the signatures of one sig_type.
+type Scheme interface {
+	// ValidAddress tells whether addr is an address of the scheme.
+	ValidAddress(addr string) bool
+	// Address derives the address of a public key.
+	Address(publicKey []byte) (string, error)
+	// Verify checks that sign is a signature of params by addr.
+	Verify(addr, sign string, params []byte) error
+}
+
+var (
+	lock    sync.RWMutex
+	schemes = make(map[string]Scheme)
+)
+
+func init() {
+	Register(TypeSecp256k1, secp256k1Scheme{})
+	Register(TypeEIP712, eip712Scheme{})
+	Register(TypeEd25519, ed25519Scheme{})
+	Register(TypeWebAuthn, webAuthnScheme{})
+}
+
+// Register adds a scheme, a name registered twice is a programming error.
+func Register(name string, s Scheme) {
+	lock.Lock()
+	defer lock.Unlock()
+	if _, ok := schemes[name]; ok {
+		panic(fmt.Sprintf("signature scheme %s registered twice", name))
+	}
+	schemes[name] = s
+}
+
+// Lookup returns the scheme of a sig_type, an empty sig_type is DefaultType.
+func Lookup(name string) (Scheme, bool) {
+	if name == "" {
+		name = DefaultType
+	}
+	lock.RLock()
+	defer lock.RUnlock()
+	s, ok := schemes[name]
+	return s, ok
+}
+
+// Names returns the registered sig_types.
+func Names() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TypeOf returns the sig_type of json params, empty when there is none.
+func TypeOf(params string) string {
+	return jsoniter.Get([]byte(params), typeParam).ToString()
+}
+
+// Verify checks sign with the scheme of sigType.
+func Verify(sigType, addr, sign string, params []byte) error {
+	if sigType == "" {
+		sigType = DefaultType
+	}
+	s, ok := Lookup(sigType)
+	if !ok {
+		return fmt.Errorf("unknown sig_type %s", sigType)
+	}
+	if !s.ValidAddress(addr) {
+		return fmt.Errorf("address %s is not a %s address", addr, sigType)
+	}
+	return s.Verify(addr, sign, params)
+}
+
+// ValidAddress tells whether addr is an address of any scheme. Schemes are
+// called without the lock held, they may look up the others.
+func ValidAddress(addr string) bool {
+	lock.RLock()
+	all := make([]Scheme, 0, len(schemes))
+	for _, s := range schemes {
+		all = append(all, s)
+	}
+	lock.RUnlock()
+	for _, s := range all {
+		if s.ValidAddress(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/config"
+)
+
+const (
+	testRPID   = "vault.example.com"
+	testOrigin = "https://vault.example.com"
+)
+
+var testParams = []byte(`{"addr":"x","timestamp":1700000000}`)
+
+// loadConfig makes a minimal config with the extra yaml the current one.
+func loadConfig(t *testing.T, extra string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(`running_mode: official
+node:
+  token: test
+log_dir: %s
+http_server:
+  port: "8080"
+server:
+  port: "8081"
+`, t.TempDir()) + extra
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.ParseConfig(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSecp256k1(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	if got, err := (secp256k1Scheme{}).Address(crypto.CompressPubkey(&key.PublicKey)); err != nil || got != addr {
+		t.Fatalf("address %s %v, want %s", got, err, addr)
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(testParams), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	sign := "0x" + hex.EncodeToString(sig)
+
+	if err := Verify("", addr, sign, testParams); err != nil {
+		t.Fatalf("default sig_type: %v", err)
+	}
+	if err := Verify(TypeSecp256k1, addr, sign, []byte(`{"addr":"y"}`)); err == nil {
+		t.Fatal("accepted a signature of other params")
+	}
+	if err := Verify(TypeSecp256k1, "ed25519:00", sign, testParams); err == nil {
+		t.Fatal("accepted an address of another scheme")
+	}
+}
+
+func TestEIP712(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	sig, err := crypto.Sign(eip712Hash(testParams), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	if err := Verify(TypeEIP712, addr, hex.EncodeToString(sig), testParams); err != nil {
+		t.Fatal(err)
+	}
+	// a personal sign is not typed data
+	personal, err := crypto.Sign(crypto.Keccak256(testParams), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(TypeEIP712, addr, hex.EncodeToString(personal), testParams); err == nil {
+		t.Fatal("accepted a personal sign")
+	}
+	if err := Verify(TypeEIP712, addr, "0x1234", testParams); err == nil {
+		t.Fatal("accepted a short signature")
+	}
+}
+
+func TestEd25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := (ed25519Scheme{}).Address(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidAddress(addr) || (secp256k1Scheme{}).ValidAddress(addr) {
+		t.Fatalf("%s is not only an ed25519 address", addr)
+	}
+	sign := hex.EncodeToString(ed25519.Sign(priv, testParams))
+	if err := Verify(TypeEd25519, addr, sign, testParams); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(TypeEd25519, addr, sign, []byte("other")); err == nil {
+		t.Fatal("accepted a signature of other params")
+	}
+	if err := Verify(TypeEd25519, addr, sign[:10], testParams); err == nil {
+		t.Fatal("accepted a short signature")
+	}
+}
+
+// webAuthnSign makes the assertion of params by key, as a browser would.
+func webAuthnSign(t *testing.T, key *ecdsa.PrivateKey, params []byte, rpID, origin, typ string, flags byte) string {
+	t.Helper()
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authData := append(rpIDHash[:], flags, 0, 0, 0, 1)
+	challenge := sha256.Sum256(params)
+	clientDataJSON, err := jsoniter.Marshal(webAuthnClientData{
+		Type:      typ,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge[:]),
+		Origin:    origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, signed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign, err := jsoniter.MarshalToString(webAuthnAssertion{
+		AuthenticatorData: base64.RawURLEncoding.EncodeToString(authData),
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientDataJSON),
+		Signature:         base64.RawURLEncoding.EncodeToString(sig),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sign
+}
+
+func TestWebAuthn(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := (webAuthnScheme{}).Address(elliptic.Marshal(elliptic.P256(), key.X, key.Y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := webAuthnSign(t, key, testParams, testRPID, testOrigin, webAuthnTypeGet, flagUserPresent)
+
+	loadConfig(t, "")
+	if err := Verify(TypeWebAuthn, addr, valid, testParams); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Fatalf("accepted without rp_id: %v", err)
+	}
+
+	loadConfig(t, "webauthn:\n  rp_id: "+testRPID+"\n")
+	if err := Verify(TypeWebAuthn, addr, valid, testParams); err != nil {
+		t.Fatalf("default origin: %v", err)
+	}
+	tests := []struct {
+		name string
+		sign string
+		err  string
+	}{
+		{"other rp id", webAuthnSign(t, key, testParams, "evil.example.com", testOrigin, webAuthnTypeGet, flagUserPresent), "relying party"},
+		{"other origin", webAuthnSign(t, key, testParams, testRPID, "https://evil.example.com", webAuthnTypeGet, flagUserPresent), "origin"},
+		{"user not present", webAuthnSign(t, key, testParams, testRPID, testOrigin, webAuthnTypeGet, 0), "user not present"},
+		{"registration", webAuthnSign(t, key, testParams, testRPID, testOrigin, "webauthn.create", flagUserPresent), "type"},
+		{"other params", webAuthnSign(t, key, []byte("other"), testRPID, testOrigin, webAuthnTypeGet, flagUserPresent), "challenge"},
+		{"not json", "x", "assertion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(TypeWebAuthn, addr, tt.sign, testParams); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	// the origins list replaces the default one
+	loadConfig(t, "webauthn:\n  rp_id: "+testRPID+"\n  origins: [https://app.example.com]\n")
+	if err := Verify(TypeWebAuthn, addr, valid, testParams); err == nil {
+		t.Fatal("accepted an origin left out of the list")
+	}
+	app := webAuthnSign(t, key, testParams, testRPID, "https://app.example.com", webAuthnTypeGet, flagUserPresent)
+	if err := Verify(TypeWebAuthn, addr, app, testParams); err != nil {
+		t.Fatalf("listed origin: %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{TypeSecp256k1, TypeEIP712, TypeEd25519, TypeWebAuthn} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("%s is not registered", name)
+		}
+	}
+	if err := Verify("nosuch", "0x0", "0x0", testParams); err == nil {
+		t.Fatal("accepted an unknown sig_type")
+	}
+	if got := TypeOf(`{"sig_type":"ed25519"}`); got != TypeEd25519 {
+		t.Fatalf("TypeOf %q", got)
+	}
+}
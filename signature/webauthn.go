@@ -0,0 +1,170 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/config"
+)
+
+const (
+	p256Prefix = "p256:"
+
+	webAuthnTypeGet = "webauthn.get"
+	// flagUserPresent is set in the authenticator data when the user touched the key.
+	flagUserPresent = 0x01
+)
+
+// webAuthnScheme is for passkeys. The address is p256:<hex compressed public
+// key>, the challenge of the assertion is the sha256 of the params and the
+// signature is the json of the assertion fields, base64url encoded. The
+// assertion must be made for webauthn.rp_id from one of webauthn.origins.
+type webAuthnScheme struct{}
+
+type webAuthnAssertion struct {
+	AuthenticatorData string `json:"authenticator_data"`
+	ClientDataJSON    string `json:"client_data_json"`
+	Signature         string `json:"signature"`
+}
+
+type webAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (webAuthnScheme) ValidAddress(addr string) bool {
+	_, err := p256PublicKey(addr)
+	return err == nil
+}
+
+func (webAuthnScheme) Address(publicKey []byte) (string, error) {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), publicKey)
+	if x == nil {
+		x, y = elliptic.Unmarshal(elliptic.P256(), publicKey)
+	}
+	if x == nil {
+		return "", errors.New("invalid p256 public key")
+	}
+	return p256Prefix + hex.EncodeToString(elliptic.MarshalCompressed(elliptic.P256(), x, y)), nil
+}
+
+func (webAuthnScheme) Verify(addr, sign string, params []byte) error {
+	rpID, origins := webAuthnRelyingParty()
+	if rpID == "" {
+		return errors.New("webauthn is not configured")
+	}
+	pub, err := p256PublicKey(addr)
+	if err != nil {
+		return err
+	}
+	var assertion webAuthnAssertion
+	if err := jsoniter.UnmarshalFromString(sign, &assertion); err != nil {
+		return fmt.Errorf("invalid webauthn assertion: %s", err.Error())
+	}
+	authData, err := decodeBase64URL(assertion.AuthenticatorData)
+	if err != nil {
+		return err
+	}
+	clientDataJSON, err := decodeBase64URL(assertion.ClientDataJSON)
+	if err != nil {
+		return err
+	}
+	sig, err := decodeBase64URL(assertion.Signature)
+	if err != nil {
+		return err
+	}
+	if len(authData) < 37 {
+		return fmt.Errorf("invalid authenticator data length: %d", len(authData))
+	}
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if subtle.ConstantTimeCompare(authData[:32], rpIDHash[:]) != 1 {
+		return errors.New("authenticator data is not for the relying party")
+	}
+	if authData[32]&flagUserPresent == 0 {
+		return errors.New("user not present")
+	}
+
+	var clientData webAuthnClientData
+	if err := jsoniter.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return fmt.Errorf("invalid client data: %s", err.Error())
+	}
+	if clientData.Type != webAuthnTypeGet {
+		return fmt.Errorf("invalid client data type: %s", clientData.Type)
+	}
+	if !containsString(origins, clientData.Origin) {
+		return fmt.Errorf("origin %s is not allowed", clientData.Origin)
+	}
+	challenge := sha256.Sum256(params)
+	if strings.TrimRight(clientData.Challenge, "=") != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+		return errors.New("challenge is not the params hash")
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := sha256.Sum256(append(authData, clientDataHash[:]...))
+	if !ecdsa.VerifyASN1(pub, signed[:], sig) {
+		return errors.New("webauthn signature mismatch")
+	}
+	return nil
+}
+
+// webAuthnRelyingParty returns the configured rp id and its allowed origins.
+func webAuthnRelyingParty() (string, []string) {
+	conf := config.GetConfig()
+	if conf == nil || conf.WebAuthn.RPID == "" {
+		return "", nil
+	}
+	if len(conf.WebAuthn.Origins) == 0 {
+		return conf.WebAuthn.RPID, []string{"https://" + conf.WebAuthn.RPID}
+	}
+	return conf.WebAuthn.RPID, conf.WebAuthn.Origins
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func p256PublicKey(addr string) (*ecdsa.PublicKey, error) {
+	if !strings.HasPrefix(addr, p256Prefix) {
+		return nil, errors.New("not a p256 address")
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(addr, p256Prefix))
+	if err != nil {
+		return nil, errors.New("invalid p256 address")
+	}
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), b)
+	if x == nil {
+		return nil, errors.New("invalid p256 address")
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, errors.New("invalid base64url value")
+	}
+	return b, nil
+}
@@ -16,13 +16,15 @@ import (
 	"errors"
 	"time"
 
-	"github.com/web3password/jewel/tools"
 	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/signature"
 	"google.golang.org/grpc/metadata"
 )
 
 const (
 	W3PTimeout              = 12
+	W3PTimeoutAttachment    = 60  // attachments download after the record
+	W3PTimeoutUpload        = 120 // uploads sign before sending up to 60MB
 	W3PMaxNonceLength       = 100
 	W3PMaxGeneralLenth      = 1024
 	W3PMax2048Lenth         = 2048
@@ -31,6 +33,9 @@ const (
 	W3PMaxBatchRecordNumber = 500
 	W3PMaxBodyLength        = 2 * 1024 * 1024
 	W3PMaxAttachmentLength  = 60 * 1024 * 1024
+
+	// sessionSigType is session.Type, which imports util through model
+	sessionSigType = "session"
 )
 
 // CheckTimestamp check timestamp
@@ -52,13 +57,13 @@ func CheckTimestamp(timestamp int64, optionalArgs ...int64) bool {
 	return true
 }
 
-// CheckSignature check signature
-func CheckSignature(addr, signature, params string) bool {
-	if err := tools.BizVerifySignature(signature, []byte(params), addr); err != nil {
+// CheckSignature checks the signature of params with the scheme of their sig_type
+func CheckSignature(addr, sign, params string) bool {
+	sigType := signature.TypeOf(params)
+	if err := signature.Verify(sigType, addr, sign, []byte(params)); err != nil {
 		log.Logger.Warn("checkSign fail",
-			log.String("addr", addr),
-			log.String("signature", signature),
-			log.String("params", params),
+			log.Hashed("addr", addr),
+			log.String("sig_type", sigType),
 			log.String("errmsg", err.Error()))
 		return false
 	}
@@ -109,6 +114,9 @@ func ParseAuthParams(auth string) (AuthParams, error) {
 		return authParams, errors.New("unmarshal personal-auth params failed")
 	}
 
+	if signature.TypeOf(personalAuth.Params) == sessionSigType {
+		return authParams, errors.New("personal-auth signed with a session token")
+	}
 	if !CheckSignature(authParams.PrimaryAddress, personalAuth.Signature, personalAuth.Params) {
 		return authParams, errors.New("invalid signautre")
 	}
@@ -0,0 +1,52 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+package util_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/web3password/satis/log"
+	"github.com/web3password/satis/util"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCheckSignatureFailureLogsNoSecrets(t *testing.T) {
+	buf := &bytes.Buffer{}
+	previous := log.Logger
+	log.Logger = zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zapcore.DebugLevel))
+	defer func() { log.Logger = previous }()
+
+	addr := "0x1111111111111111111111111111111111111111"
+	sign := "0xdeadbeefsignaturevalue"
+	params := `{"addr":"` + addr + `","token":"tok3n-value","signature":"nested-sig","data":"secret-data"}`
+	if util.CheckSignature(addr, sign, params) {
+		t.Fatal("bogus signature accepted")
+	}
+	out := buf.String()
+	if !strings.Contains(out, "checkSign fail") {
+		t.Fatalf("failure not logged: %s", out)
+	}
+	for _, s := range []string{addr, sign, "tok3n-value", "nested-sig", "secret-data"} {
+		if strings.Contains(out, s) {
+			t.Errorf("%q logged: %s", s, out)
+		}
+	}
+}
+
+func TestParseAuthParamsRefusesSessionTokens(t *testing.T) {
+	auth := `{"signature":"w3ps.x.y","params":"{\"addr\":\"0x1111111111111111111111111111111111111111\",\"sig_type\":\"session\",\"token\":\"getCredentialList\"}"}`
+	if _, err := util.ParseAuthParams(auth); err == nil || !strings.Contains(err.Error(), "session") {
+		t.Fatalf("personal-auth signed with a session token: %v", err)
+	}
+}
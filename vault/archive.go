@@ -0,0 +1,282 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+
+// Package vault reads and writes the backup archive of a vault: a tar of the
+// encrypted blobs as the nodes store them, credentials/<id> and
+// records/<folder_id>/<id>, ending with manifest.json, which lists every
+// entry with its sha256 and the attachments by reference. Satis never holds
+// the keys, the blobs are copied as they are.
+package vault
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/web3password/satis/util"
+)
+
+// Version is the archive format written, archives of a later version are refused.
+const Version = 1
+
+// ManifestName is the last file of an archive.
+const ManifestName = "manifest.json"
+
+const (
+	KindCredential = "credential"
+	KindRecord     = "record"
+	KindAttachment = "attachment" // a reference, the file stays in the storage
+)
+
+var ErrInvalid = errors.New("invalid vault archive")
+
+// Entry is an item of the vault. Attachments have no blob in the archive,
+// their Size and SHA256 are the ones of the stored file.
+type Entry struct {
+	Kind        string `json:"kind"`
+	Id          string `json:"id"`
+	FolderId    string `json:"folder_id,omitempty"`
+	OpTimestamp int64  `json:"op_timestamp,omitempty"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// Manifest describes an archive. An archive with Resume is a part of the
+// vault, the export stopped there before its signature expired.
+type Manifest struct {
+	Version   int      `json:"version"`
+	Addr      string   `json:"addr"`
+	OrgId     string   `json:"org_id,omitempty"`
+	CreatedAt int64    `json:"created_at"`
+	Entries   []*Entry `json:"entries"`
+	Resume    *Resume  `json:"resume,omitempty"`
+}
+
+// Resume is where the next part of an export starts, sent back as the
+// resume_kind and resume_cursor params of a new export.
+type Resume struct {
+	Kind   string `json:"kind"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// Count is the number of entries of kind.
+func (m *Manifest) Count(kind string) int {
+	n := 0
+	for _, e := range m.Entries {
+		if e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Name is the file of the entry in the archive, empty for an attachment.
+func (e *Entry) Name() string {
+	switch e.Kind {
+	case KindCredential:
+		return path.Join("credentials", e.Id)
+	case KindRecord:
+		return path.Join("records", e.FolderId, e.Id)
+	}
+	return ""
+}
+
+func (e *Entry) check() error {
+	if !validName(e.Id) {
+		return fmt.Errorf("%w: %s id %q", ErrInvalid, e.Kind, e.Id)
+	}
+	switch e.Kind {
+	case KindCredential, KindAttachment:
+	case KindRecord:
+		if !validName(e.FolderId) {
+			return fmt.Errorf("%w: record %s folder_id %q", ErrInvalid, e.Id, e.FolderId)
+		}
+	default:
+		return fmt.Errorf("%w: kind %q", ErrInvalid, e.Kind)
+	}
+	return nil
+}
+
+// validName keeps the ids usable as a path element.
+func validName(s string) bool {
+	return s != "" && s != "." && s != ".." && len(s) <= util.W3PMaxNonceLength && !strings.ContainsAny(s, `/\`)
+}
+
+// Writer streams an archive, nothing is written before the first Add.
+type Writer struct {
+	tw       *tar.Writer
+	manifest Manifest
+}
+
+func NewWriter(w io.Writer, addr, orgId string) *Writer {
+	return &Writer{
+		tw:       tar.NewWriter(w),
+		manifest: Manifest{Version: Version, Addr: addr, OrgId: orgId, CreatedAt: time.Now().Unix()},
+	}
+}
+
+// Add writes the blob of e and lists it in the manifest, the blob of an
+// attachment is ignored.
+func (w *Writer) Add(e *Entry, blob []byte) error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	if e.Kind != KindAttachment {
+		sum := sha256.Sum256(blob)
+		e.Size, e.SHA256 = int64(len(blob)), hex.EncodeToString(sum[:])
+		if err := w.writeFile(e.Name(), blob); err != nil {
+			return err
+		}
+	}
+	w.manifest.Entries = append(w.manifest.Entries, e)
+	return nil
+}
+
+// Stop marks the archive as a part of the vault, the next part starting at
+// the page cursor of kind. Close still has to be called.
+func (w *Writer) Stop(kind, cursor string) {
+	w.manifest.Resume = &Resume{Kind: kind, Cursor: cursor}
+}
+
+// Close writes the manifest, an archive without it is incomplete.
+func (w *Writer) Close() error {
+	if w.manifest.Entries == nil {
+		w.manifest.Entries = make([]*Entry, 0)
+	}
+	b, err := jsoniter.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile(ManifestName, b); err != nil {
+		return err
+	}
+	return w.tw.Close()
+}
+
+func (w *Writer) writeFile(name string, b []byte) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(b)),
+		ModTime: time.Unix(w.manifest.CreatedAt, 0),
+	}); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(b)
+	return err
+}
+
+// Archive is a read archive whose blobs match the manifest.
+type Archive struct {
+	Manifest Manifest
+	blobs    map[string][]byte
+}
+
+// Blob is the blob of a credential or record entry.
+func (a *Archive) Blob(e *Entry) []byte {
+	return a.blobs[e.Name()]
+}
+
+// Read reads an archive and checks every blob against the manifest.
+func Read(b []byte) (*Archive, error) {
+	a := &Archive{blobs: make(map[string][]byte)}
+	var manifest []byte
+	tr := tar.NewReader(bytes.NewReader(b))
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+		}
+		if h.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%w: %s is not a regular file", ErrInvalid, h.Name)
+		}
+		blob, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+		}
+		if h.Name == ManifestName {
+			manifest = blob
+			continue
+		}
+		a.blobs[h.Name] = blob
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: no %s, the archive is incomplete", ErrInvalid, ManifestName)
+	}
+	if err := jsoniter.Unmarshal(manifest, &a.Manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalid, ManifestName, err.Error())
+	}
+	if a.Manifest.Version < 1 || a.Manifest.Version > Version {
+		return nil, fmt.Errorf("%w: version %d, at most %d is supported", ErrInvalid, a.Manifest.Version, Version)
+	}
+
+	listed := make(map[string]bool, len(a.Manifest.Entries))
+	for _, e := range a.Manifest.Entries {
+		if err := e.check(); err != nil {
+			return nil, err
+		}
+		if e.Kind == KindAttachment {
+			continue
+		}
+		name := e.Name()
+		if listed[name] {
+			return nil, fmt.Errorf("%w: %s listed twice", ErrInvalid, name)
+		}
+		listed[name] = true
+		blob, ok := a.blobs[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s missing", ErrInvalid, name)
+		}
+		sum := sha256.Sum256(blob)
+		if int64(len(blob)) != e.Size || hex.EncodeToString(sum[:]) != e.SHA256 {
+			return nil, fmt.Errorf("%w: %s does not match its sha256", ErrInvalid, name)
+		}
+	}
+	for name := range a.blobs {
+		if !listed[name] {
+			return nil, fmt.Errorf("%w: %s not in the manifest", ErrInvalid, name)
+		}
+	}
+	return a, nil
+}
+
+// ReadManifest reads the manifest of an archive without keeping or checking
+// its blobs, to find the resume of a part.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: no %s, the archive is incomplete", ErrInvalid, ManifestName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+		}
+		if h.Name != ManifestName {
+			continue
+		}
+		var m Manifest
+		if err := jsoniter.NewDecoder(tr).Decode(&m); err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalid, ManifestName, err.Error())
+		}
+		return &m, nil
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2024 Web3Password PTE. LTD.(Singapore UEN: 202333030C) - All Rights Reserved
+
+Web3Password PTE. LTD.(Singapore UEN: 202333030C) holds the copyright of this file.
+
+Unauthorized copying or redistribution of this file in binary forms via any medium is strictly prohibited.
+
+For more information, please refer to https://www.web3password.com/web3password_license.txt
+*/
+package vault
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/web3password/satis/model"
+)
+
+const (
+	StateRunning = "running"
+	StateDone    = "done"
+	StateFailed  = "failed"
+)
+
+// keepFinished is how long the progress of a finished import stays readable.
+const keepFinished = time.Hour
+
+// Imports keeps the progress of the imports of this process, in memory. It is
+// not shared between instances and is lost on restart: behind a load balancer
+// the import status requests of an addr must reach the instance that accepted
+// its import, e.g. with addr affinity.
+type Imports struct {
+	mu   sync.Mutex
+	jobs map[string]*model.VaultImportProgress
+}
+
+func NewImports() *Imports {
+	return &Imports{jobs: make(map[string]*model.VaultImportProgress)}
+}
+
+// Start registers a running import of total items and drops the long finished ones.
+func (i *Imports) Start(addr string, total int64) model.VaultImportProgress {
+	now := time.Now().Unix()
+	p := &model.VaultImportProgress{JobId: uuid.NewString(), Addr: addr, State: StateRunning, Total: total, StartedAt: now, UpdatedAt: now}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for id, job := range i.jobs {
+		if job.State != StateRunning && now-job.UpdatedAt > int64(keepFinished/time.Second) {
+			delete(i.jobs, id)
+		}
+	}
+	i.jobs[p.JobId] = p
+	return *p
+}
+
+// Advance counts done and failed items of a running import.
+func (i *Imports) Advance(jobId string, done, failed int64) {
+	i.update(jobId, func(p *model.VaultImportProgress) {
+		p.Done += done
+		p.Failed += failed
+	})
+}
+
+// Finish ends an import, failed when errMsg is set.
+func (i *Imports) Finish(jobId, errMsg string) {
+	i.update(jobId, func(p *model.VaultImportProgress) {
+		p.State = StateDone
+		if errMsg != "" {
+			p.State, p.Error = StateFailed, errMsg
+		}
+	})
+}
+
+func (i *Imports) update(jobId string, f func(p *model.VaultImportProgress)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if p, ok := i.jobs[jobId]; ok {
+		f(p)
+		p.UpdatedAt = time.Now().Unix()
+	}
+}
+
+// Get is the progress of an import of addr.
+func (i *Imports) Get(addr, jobId string) (model.VaultImportProgress, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	p, ok := i.jobs[jobId]
+	if !ok || !strings.EqualFold(p.Addr, addr) {
+		return model.VaultImportProgress{}, false
+	}
+	return *p, true
+}